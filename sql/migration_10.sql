@@ -0,0 +1,14 @@
+-- 数据库迁移脚本：为 orders 表添加 selected_at 字段
+-- 执行日期：2026-10-18
+-- 说明：支持开放订单（chain_type=OPEN），由付款人在收银台选择链，选择后才分配钱包并开始计算过期时间
+
+-- 添加 selected_at 字段
+ALTER TABLE `orders` 
+ADD COLUMN `selected_at` TIMESTAMP NULL DEFAULT NULL COMMENT '开放订单付款人选择链的时间（从该时间开始计算过期）' 
+AFTER `callback_confirm`;
+
+-- 验证字段是否添加成功
+-- SELECT id, trade_id, chain_type, token, selected_at FROM orders LIMIT 5;
+
+-- 如果需要回滚，执行以下语句：
+-- ALTER TABLE `orders` DROP COLUMN `selected_at`;
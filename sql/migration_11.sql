@@ -0,0 +1,14 @@
+-- 数据库迁移脚本：为 orders 表添加 theme 字段
+-- 执行日期：2026-10-18
+-- 说明：收银台模板改为内置并支持覆盖目录，订单可指定主题使用 checkout_template_path/{theme}/index.html
+
+-- 添加 theme 字段
+ALTER TABLE `orders` 
+ADD COLUMN `theme` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '收银台主题（模板覆盖目录下的子目录，为空使用默认模板）' 
+AFTER `selected_at`;
+
+-- 验证字段是否添加成功
+-- SELECT id, trade_id, theme FROM orders LIMIT 5;
+
+-- 如果需要回滚，执行以下语句：
+-- ALTER TABLE `orders` DROP COLUMN `theme`;
@@ -0,0 +1,43 @@
+-- 数据库迁移脚本：新增 payment_links 表，orders 表添加 payment_link_id 字段
+-- 执行日期：2026-10-18
+-- 说明：支付链接（/pay/link/:slug）可重复使用，付款人每次提交生成一笔独立订单，订单通过 payment_link_id 关联来源链接
+
+-- 创建支付链接表
+CREATE TABLE IF NOT EXISTS `payment_links` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `slug` VARCHAR(32) NOT NULL COMMENT '链接标识（/pay/link/:slug）',
+  `title` VARCHAR(100) NOT NULL COMMENT '标题',
+  `description` VARCHAR(500) NOT NULL DEFAULT '' COMMENT '描述',
+  `amount` DECIMAL(20,8) NOT NULL DEFAULT 0 COMMENT '固定金额（0=付款人填写）',
+  `currency` VARCHAR(10) NOT NULL DEFAULT 'CNY' COMMENT '金额币种（CNY, USD, EUR 或 USDT）',
+  `chain_types` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '允许的链类型，逗号分隔（为空=所有可用的链）',
+  `max_uses` INT NOT NULL DEFAULT 0 COMMENT '最多支付次数（0=不限）',
+  `use_count` INT NOT NULL DEFAULT 0 COMMENT '已支付次数',
+  `expires_at` TIMESTAMP NULL DEFAULT NULL COMMENT '过期时间（为空=永不过期）',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=启用, 2=禁用',
+  `notify_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '生成订单的异步回调地址（为空不回调）',
+  `redirect_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '生成订单的同步回调地址',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `payment_links_slug_uindex` (`slug`),
+  KEY `idx_payment_links_status` (`status`),
+  KEY `idx_payment_links_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='支付链接表';
+
+-- 添加 payment_link_id 字段
+ALTER TABLE `orders` 
+ADD COLUMN `payment_link_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '来源支付链接ID（0=商户下单）' 
+AFTER `theme`;
+
+-- 添加索引
+ALTER TABLE `orders` ADD INDEX `idx_orders_payment_link_id` (`payment_link_id`);
+
+-- 验证表和字段是否添加成功
+-- SHOW CREATE TABLE payment_links;
+-- SELECT id, trade_id, payment_link_id FROM orders LIMIT 5;
+
+-- 如果需要回滚，执行以下语句：
+-- ALTER TABLE `orders` DROP INDEX `idx_orders_payment_link_id`;
+-- ALTER TABLE `orders` DROP COLUMN `payment_link_id`;
+-- DROP TABLE IF EXISTS `payment_links`;
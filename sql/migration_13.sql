@@ -0,0 +1,14 @@
+-- 数据库迁移脚本：为 orders 表添加 amount_mode 字段
+-- 执行日期：2026-10-18
+-- 说明：支持开放金额订单（打赏、充值等下单时金额未知的场景），付款人向订单独占的派生地址转入任意金额，首笔到账交易按实际金额结算订单
+
+-- 添加 amount_mode 字段
+ALTER TABLE `orders` 
+ADD COLUMN `amount_mode` VARCHAR(10) NOT NULL DEFAULT 'FIXED' COMMENT '金额模式（FIXED=固定金额, OPEN=开放金额，按首笔到账金额结算）' 
+AFTER `payment_link_id`;
+
+-- 验证字段是否添加成功
+-- SELECT id, trade_id, amount, actual_amount, amount_mode FROM orders LIMIT 5;
+
+-- 如果需要回滚，执行以下语句：
+-- ALTER TABLE `orders` DROP COLUMN `amount_mode`;
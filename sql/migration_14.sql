@@ -0,0 +1,54 @@
+-- 数据库迁移脚本：新增 customer_addresses、customer_deposits 表
+-- 执行日期：2026-10-18
+-- 说明：为商户的客户分配永久充值地址（派生或地址池），地址不论有无订单都持续监听，每笔稳定币转入记录为充值并回调
+
+-- 创建客户充值地址表
+CREATE TABLE IF NOT EXISTS `customer_addresses` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `customer_id` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '商户的客户ID（为空=地址池中未分配）',
+  `chain_type` VARCHAR(20) NOT NULL COMMENT '链类型（TRC20, ERC20, BEP20, SOLANA, POLYGON, ARBITRUM）',
+  `token` VARCHAR(50) NOT NULL COMMENT '充值地址',
+  `source` VARCHAR(10) NOT NULL DEFAULT 'pool' COMMENT '地址来源（derive=派生, pool=地址池）',
+  `derivation_index` INT UNSIGNED DEFAULT NULL COMMENT '派生地址索引（xpub/0/index，与订单派生地址共用）',
+  `notify_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '充值回调地址（为空=使用 deposit_notify_url）',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=启用, 2=禁用',
+  `assigned_at` TIMESTAMP NULL DEFAULT NULL COMMENT '分配时间',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `customer_addresses_token_chain_uindex` (`token`, `chain_type`),
+  UNIQUE KEY `idx_customer_addresses_derivation_index` (`chain_type`, `derivation_index`),
+  KEY `idx_customer_addresses_customer` (`customer_id`, `chain_type`),
+  KEY `idx_customer_addresses_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='客户充值地址表';
+
+-- 创建客户充值记录表
+CREATE TABLE IF NOT EXISTS `customer_deposits` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `customer_address_id` BIGINT UNSIGNED NOT NULL COMMENT '充值地址ID',
+  `customer_id` VARCHAR(64) NOT NULL COMMENT '商户的客户ID',
+  `chain_type` VARCHAR(20) NOT NULL COMMENT '链类型',
+  `token` VARCHAR(50) NOT NULL COMMENT '充值地址',
+  `from_address` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '转出地址',
+  `asset` VARCHAR(10) NOT NULL DEFAULT 'USDT' COMMENT '代币符号（USDT, USDC）',
+  `amount` DECIMAL(20,8) NOT NULL COMMENT '到账金额',
+  `block_transaction_id` VARCHAR(128) NOT NULL COMMENT '交易哈希',
+  `block_timestamp` BIGINT NOT NULL DEFAULT 0 COMMENT '区块时间戳（毫秒）',
+  `notify_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '充值回调地址（为空不回调）',
+  `callback_num` INT NOT NULL DEFAULT 0 COMMENT '回调次数',
+  `callback_confirm` TINYINT NOT NULL DEFAULT 2 COMMENT '回调是否已确认（1=是, 2=否）',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `customer_deposits_tx_uindex` (`chain_type`, `block_transaction_id`, `customer_address_id`),
+  KEY `idx_customer_deposits_customer_id` (`customer_id`),
+  KEY `idx_customer_deposits_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='客户充值记录表';
+
+-- 验证表是否创建成功
+-- SHOW CREATE TABLE customer_addresses;
+-- SHOW CREATE TABLE customer_deposits;
+
+-- 如果需要回滚，执行以下语句：
+-- DROP TABLE IF EXISTS `customer_deposits`;
+-- DROP TABLE IF EXISTS `customer_addresses`;
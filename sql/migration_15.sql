@@ -0,0 +1,52 @@
+-- 数据库迁移脚本：新增 subscriptions 表，orders 表添加 subscription_id 字段
+-- 执行日期：2026-10-18
+-- 说明：订阅按周期自动生成账单订单并发送给客户，账单过期未支付时按间隔重新生成账单催缴，超过次数后取消订阅；账单订单通过 subscription_id 关联订阅
+
+-- 创建订阅表
+CREATE TABLE IF NOT EXISTS `subscriptions` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `subscription_no` VARCHAR(32) NOT NULL COMMENT '订阅编号',
+  `customer_id` VARCHAR(64) NOT NULL COMMENT '商户的客户ID',
+  `customer_email` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '客户邮箱（通过邮件 webhook 发送账单）',
+  `telegram_chat_id` BIGINT NOT NULL DEFAULT 0 COMMENT '客户 Telegram chat ID（0=不通过机器人发送账单）',
+  `title` VARCHAR(100) NOT NULL COMMENT '账单标题',
+  `amount` DECIMAL(20,8) NOT NULL COMMENT '每期金额',
+  `currency` VARCHAR(10) NOT NULL DEFAULT 'CNY' COMMENT '金额币种（CNY, USD, EUR 或 USDT）',
+  `chain_type` VARCHAR(20) NOT NULL DEFAULT 'OPEN' COMMENT '支付链类型（OPEN=客户在收银台选择）',
+  `interval_unit` VARCHAR(10) NOT NULL COMMENT '周期单位（DAY, WEEK, MONTH, YEAR）',
+  `interval_count` INT NOT NULL DEFAULT 1 COMMENT '周期数',
+  `start_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '首期账单日',
+  `due_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '当前账期的账单日',
+  `next_run_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '下次生成账单的时间（逾期时为催缴时间）',
+  `current_trade_id` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '当前账期待支付的订单号',
+  `retry_count` INT NOT NULL DEFAULT 0 COMMENT '当前账期已失败的账单数',
+  `paid_periods` INT NOT NULL DEFAULT 0 COMMENT '已支付期数',
+  `notify_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '订阅事件回调地址（为空不回调）',
+  `redirect_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '账单订单的同步回调地址',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=正常, 2=逾期, 3=已取消',
+  `cancelled_at` TIMESTAMP NULL DEFAULT NULL COMMENT '取消时间',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `subscriptions_subscription_no_uindex` (`subscription_no`),
+  KEY `idx_subscriptions_customer_id` (`customer_id`),
+  KEY `idx_subscriptions_next_run` (`status`, `next_run_at`),
+  KEY `idx_subscriptions_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订阅表';
+
+-- 添加 subscription_id 字段
+ALTER TABLE `orders` 
+ADD COLUMN `subscription_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '来源订阅ID（0=非订阅账单）' 
+AFTER `amount_mode`;
+
+-- 添加索引
+ALTER TABLE `orders` ADD INDEX `idx_orders_subscription_id` (`subscription_id`);
+
+-- 验证表和字段是否添加成功
+-- SHOW CREATE TABLE subscriptions;
+-- SELECT id, trade_id, order_id, subscription_id FROM orders LIMIT 5;
+
+-- 如果需要回滚，执行以下语句：
+-- ALTER TABLE `orders` DROP INDEX `idx_orders_subscription_id`;
+-- ALTER TABLE `orders` DROP COLUMN `subscription_id`;
+-- DROP TABLE IF EXISTS `subscriptions`;
@@ -0,0 +1,39 @@
+-- 数据库迁移脚本：新增 refunds 表
+-- 执行日期：2026-10-18
+-- 说明：记录订单退款，退款交易由商户在外部签名发出，提交交易哈希后在链上核验转账地址、币种和金额，核验通过后回调 refund.completed
+
+-- 创建退款表
+CREATE TABLE IF NOT EXISTS `refunds` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `refund_no` VARCHAR(32) NOT NULL COMMENT '退款编号',
+  `trade_id` VARCHAR(32) NOT NULL COMMENT 'epusdt订单号',
+  `order_id` VARCHAR(32) NOT NULL COMMENT '客户交易id',
+  `chain_type` VARCHAR(20) NOT NULL COMMENT '链类型（与订单支付链一致）',
+  `asset` VARCHAR(10) NOT NULL DEFAULT 'USDT' COMMENT '退款币种（与订单支付币种一致）',
+  `amount` DECIMAL(20,8) NOT NULL COMMENT '退款金额（按支付币种）',
+  `to_address` VARCHAR(64) NOT NULL COMMENT '退款地址',
+  `reason` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '退款原因',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=待退款, 2=核验中, 3=已完成, 4=核验失败, 5=已取消',
+  `tx_hash` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '退款交易哈希',
+  `from_address` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '退款转出地址（核验通过后记录）',
+  `fail_reason` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '核验失败原因',
+  `submitted_at` TIMESTAMP NULL DEFAULT NULL COMMENT '提交交易哈希的时间',
+  `completed_at` TIMESTAMP NULL DEFAULT NULL COMMENT '核验通过的时间',
+  `notify_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '退款回调地址（为空不回调）',
+  `callback_num` INT NOT NULL DEFAULT 0 COMMENT '回调次数',
+  `callback_confirm` TINYINT NOT NULL DEFAULT 2 COMMENT '回调是否已确认（1=是, 2=否）',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `refunds_refund_no_uindex` (`refund_no`),
+  KEY `idx_refunds_trade_id` (`trade_id`),
+  KEY `idx_refunds_tx_hash` (`chain_type`, `tx_hash`),
+  KEY `idx_refunds_status` (`status`),
+  KEY `idx_refunds_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='退款表';
+
+-- 验证表是否创建成功
+-- SHOW CREATE TABLE refunds;
+
+-- 如果需要回滚，执行以下语句：
+-- DROP TABLE IF EXISTS `refunds`;
@@ -0,0 +1,21 @@
+-- 数据库迁移脚本：新增 derivation_counters 表
+-- 执行日期：2026-10-18
+-- 说明：派生地址索引改为从每条链的计数器原子分配，订单派生地址与客户充值地址共用，避免并发下分配到相同索引、派生出相同地址
+
+-- 创建派生地址索引计数器表
+CREATE TABLE IF NOT EXISTS `derivation_counters` (
+  `chain_type` VARCHAR(20) NOT NULL PRIMARY KEY COMMENT '链类型',
+  `next_index` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '下一个未分配的派生索引',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='派生地址索引计数器表';
+
+-- 按已使用的最大索引初始化计数器（未执行时首次派生会自动初始化）
+INSERT IGNORE INTO `derivation_counters` (`chain_type`, `next_index`)
+SELECT `chain_type`, MAX(`derivation_index`) + 1 FROM (
+  SELECT `chain_type`, `derivation_index` FROM `wallet_address` WHERE `derivation_index` IS NOT NULL
+  UNION ALL
+  SELECT `chain_type`, `derivation_index` FROM `customer_addresses` WHERE `derivation_index` IS NOT NULL
+) t GROUP BY `chain_type`;
+
+-- 如果需要回滚，执行以下语句：
+-- DROP TABLE IF EXISTS `derivation_counters`;
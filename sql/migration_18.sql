@@ -0,0 +1,11 @@
+-- 数据库迁移脚本：refunds 表添加 active_tx_hash 生成列及唯一索引
+-- 执行日期：2026-10-18
+-- 说明：未取消的退款不能使用相同的交易哈希，由唯一索引保证并发提交时同一笔链上转账只计入一笔退款；退款取消后该列为 NULL，交易哈希可重新使用
+
+-- 添加生成列及唯一索引
+ALTER TABLE `refunds`
+ADD COLUMN `active_tx_hash` VARCHAR(128) GENERATED ALWAYS AS (IF(`status` <> 5 AND `tx_hash` <> '', `tx_hash`, NULL)) STORED COMMENT '未取消退款的交易哈希（唯一，防止同一交易用于多笔退款）' AFTER `tx_hash`,
+ADD UNIQUE KEY `idx_refunds_active_tx_hash` (`chain_type`, `active_tx_hash`);
+
+-- 如果需要回滚，执行以下语句：
+-- ALTER TABLE `refunds` DROP INDEX `idx_refunds_active_tx_hash`, DROP COLUMN `active_tx_hash`;
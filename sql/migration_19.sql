@@ -0,0 +1,9 @@
+-- 数据库迁移脚本：禁用已结束订单的派生地址
+-- 执行日期：2026-10-18
+-- 说明：订单支付成功或过期后禁用其派生地址，监听任务只轮询启用的钱包地址；此脚本处理升级前遗留的派生地址
+
+-- 禁用订单已不在待支付状态的派生地址
+UPDATE `wallet_address` w
+JOIN `orders` o ON o.`trade_id` = w.`trade_id`
+SET w.`status` = 2
+WHERE w.`trade_id` <> '' AND w.`status` = 1 AND o.`status` <> 1;
@@ -0,0 +1,14 @@
+-- 数据库迁移脚本：为 orders 表添加 asset 字段
+-- 执行日期：2026-10-18
+-- 说明：支持使用链原生币（TRX、ETH、BNB、SOL、POL）支付
+
+-- 添加 asset 字段
+ALTER TABLE `orders` 
+ADD COLUMN `asset` VARCHAR(10) NOT NULL DEFAULT 'USDT' COMMENT '支付币种（USDT=稳定币, TRX, ETH, BNB, SOL, POL）' 
+AFTER `chain_type`;
+
+-- 验证字段是否添加成功
+-- SELECT id, trade_id, chain_type, asset FROM orders LIMIT 5;
+
+-- 如果需要回滚，执行以下语句：
+-- ALTER TABLE `orders` DROP COLUMN `asset`;
@@ -0,0 +1,21 @@
+-- 数据库迁移脚本：新增 scan_cursor 表
+-- 执行日期：2026-10-18
+-- 说明：记录各链/各地址的扫描游标，监听任务只查询新数据，不再每次回扫24小时
+
+-- 区块链扫描游标表（增量扫描）
+CREATE TABLE IF NOT EXISTS `scan_cursor` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `chain_type` VARCHAR(20) NOT NULL COMMENT '链类型',
+  `address` VARCHAR(50) NOT NULL DEFAULT '' COMMENT '钱包地址，为空表示整条链的游标',
+  `scope` VARCHAR(20) NOT NULL DEFAULT 'token' COMMENT '扫描范围（token=稳定币, native=原生币）',
+  `cursor_value` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '链自定义游标（区块号、slot 等）',
+  `last_timestamp` BIGINT NOT NULL DEFAULT 0 COMMENT '已扫描到的时间（毫秒）',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `idx_scan_cursor` (`chain_type`, `address`, `scope`),
+  KEY `idx_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='区块链扫描游标表';
+
+-- 如果需要回滚，执行以下语句：
+-- DROP TABLE IF EXISTS `scan_cursor`;
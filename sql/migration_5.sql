@@ -0,0 +1,26 @@
+-- 数据库迁移脚本：为 wallet_address 表添加派生地址字段
+-- 执行日期：2026-10-18
+-- 说明：支持按 xpub 为每笔订单派生独立的只读收款地址，按地址匹配订单
+
+-- 添加 trade_id 字段
+ALTER TABLE `wallet_address` 
+ADD COLUMN `trade_id` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '派生地址关联的订单号，为空表示收款池钱包' 
+AFTER `status`;
+
+-- 添加 derivation_index 字段
+ALTER TABLE `wallet_address` 
+ADD COLUMN `derivation_index` INT UNSIGNED DEFAULT NULL COMMENT '派生地址索引（xpub/0/index）' 
+AFTER `trade_id`;
+
+-- 添加索引，同一链的派生索引唯一
+ALTER TABLE `wallet_address` ADD KEY `idx_wallet_trade_id` (`trade_id`);
+ALTER TABLE `wallet_address` ADD UNIQUE KEY `idx_chain_derivation_index` (`chain_type`, `derivation_index`);
+
+-- 验证字段是否添加成功
+-- SELECT id, token, chain_type, trade_id, derivation_index FROM wallet_address LIMIT 5;
+
+-- 如果需要回滚，执行以下语句：
+-- ALTER TABLE `wallet_address` DROP INDEX `idx_chain_derivation_index`;
+-- ALTER TABLE `wallet_address` DROP INDEX `idx_wallet_trade_id`;
+-- ALTER TABLE `wallet_address` DROP COLUMN `derivation_index`;
+-- ALTER TABLE `wallet_address` DROP COLUMN `trade_id`;
@@ -0,0 +1,36 @@
+-- 数据库迁移脚本：新增 amount_locks 金额锁表
+-- 执行日期：2026-10-18
+-- 说明：金额锁从 cache 表迁移到独立表，(token, amount, chain_type) 唯一索引保证多实例下不会重复分配金额
+
+CREATE TABLE IF NOT EXISTS `amount_locks` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `token` VARCHAR(50) NOT NULL COMMENT '钱包地址',
+  `chain_type` VARCHAR(30) NOT NULL COMMENT '链标识（原生币为 链类型:币种）',
+  `amount` VARCHAR(32) NOT NULL COMMENT '锁定金额（保留4位小数）',
+  `trade_id` VARCHAR(32) NOT NULL COMMENT '交易号',
+  `expires_at` TIMESTAMP NOT NULL COMMENT '过期时间',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY `idx_amount_lock` (`token`, `amount`, `chain_type`),
+  KEY `idx_amount_lock_chain` (`chain_type`, `expires_at`),
+  KEY `idx_amount_lock_expires` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='支付金额锁表';
+
+-- 迁移未过期的金额锁，缓存键格式：wallet:{token}_{amount}_{chain}
+INSERT IGNORE INTO `amount_locks` (`token`, `chain_type`, `amount`, `trade_id`, `expires_at`)
+SELECT
+  SUBSTRING_INDEX(SUBSTRING(`cache_key`, 8), '_', 1),
+  SUBSTRING_INDEX(`cache_key`, '_', -1),
+  SUBSTRING_INDEX(SUBSTRING_INDEX(`cache_key`, '_', -2), '_', 1),
+  `cache_value`,
+  `expires_at`
+FROM `cache`
+WHERE `cache_key` LIKE 'wallet:%' AND `expires_at` > NOW();
+
+-- 删除 cache 表中的旧金额锁
+DELETE FROM `cache` WHERE `cache_key` LIKE 'wallet:%';
+
+-- 验证迁移结果
+-- SELECT token, chain_type, amount, trade_id, expires_at FROM amount_locks LIMIT 5;
+
+-- 如果需要回滚，执行以下语句（回滚前需重新部署旧版本，未过期的金额锁会丢失）：
+-- DROP TABLE `amount_locks`;
@@ -0,0 +1,38 @@
+-- 数据库迁移脚本：amount_locks 改为按钱包ID加锁
+-- 执行日期：2026-10-18
+-- 说明：金额锁关联 wallet_address.id，待支付地址检查与回调匹配均可走索引，按交易号释放金额锁
+
+-- 添加 wallet_id 字段
+ALTER TABLE `amount_locks` 
+ADD COLUMN `wallet_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '钱包ID（wallet_address.id）' 
+AFTER `id`;
+
+-- 按地址和链类型回填钱包ID（原生币链标识为 链类型:币种）
+UPDATE `amount_locks` l
+JOIN `wallet_address` w ON w.`token` = l.`token` 
+  AND w.`chain_type` = SUBSTRING_INDEX(l.`chain_type`, ':', 1) 
+  AND w.`deleted_at` IS NULL
+SET l.`wallet_id` = w.`id`;
+
+-- 删除找不到钱包的金额锁
+DELETE FROM `amount_locks` WHERE `wallet_id` = 0;
+
+-- 替换索引并删除 token 字段
+ALTER TABLE `amount_locks` DROP INDEX `idx_amount_lock`;
+ALTER TABLE `amount_locks` DROP COLUMN `token`;
+ALTER TABLE `amount_locks` ALTER COLUMN `wallet_id` DROP DEFAULT;
+ALTER TABLE `amount_locks` ADD UNIQUE KEY `idx_amount_lock` (`wallet_id`, `amount`, `chain_type`);
+ALTER TABLE `amount_locks` ADD KEY `idx_amount_lock_pending` (`wallet_id`, `chain_type`, `expires_at`);
+ALTER TABLE `amount_locks` ADD KEY `idx_amount_lock_trade_id` (`trade_id`);
+
+-- 验证字段是否添加成功
+-- SELECT id, wallet_id, chain_type, amount, trade_id, expires_at FROM amount_locks LIMIT 5;
+
+-- 如果需要回滚，执行以下语句（未过期的金额锁会丢失）：
+-- DELETE FROM `amount_locks`;
+-- ALTER TABLE `amount_locks` DROP INDEX `idx_amount_lock_trade_id`;
+-- ALTER TABLE `amount_locks` DROP INDEX `idx_amount_lock_pending`;
+-- ALTER TABLE `amount_locks` DROP INDEX `idx_amount_lock`;
+-- ALTER TABLE `amount_locks` ADD COLUMN `token` VARCHAR(50) NOT NULL COMMENT '钱包地址' AFTER `id`;
+-- ALTER TABLE `amount_locks` DROP COLUMN `wallet_id`;
+-- ALTER TABLE `amount_locks` ADD UNIQUE KEY `idx_amount_lock` (`token`, `amount`, `chain_type`);
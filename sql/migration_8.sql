@@ -0,0 +1,20 @@
+-- 数据库迁移脚本：为 orders 表添加法币币种和汇率快照字段
+-- 执行日期：2026-10-18
+-- 说明：订单支持 CNY、USD、EUR 等多种法币计价，记录下单时使用的汇率供回调核对
+
+-- 添加 currency 字段
+ALTER TABLE `orders` 
+ADD COLUMN `currency` VARCHAR(10) NOT NULL DEFAULT 'CNY' COMMENT '订单金额的法币币种（CNY, USD, EUR）' 
+AFTER `amount`;
+
+-- 添加 rate 字段（历史订单未记录汇率，保持为0）
+ALTER TABLE `orders` 
+ADD COLUMN `rate` DECIMAL(20,8) NOT NULL DEFAULT 0 COMMENT '下单时的汇率快照（1 USDT 折合的法币数量）' 
+AFTER `currency`;
+
+-- 验证字段是否添加成功
+-- SELECT id, trade_id, amount, currency, rate FROM orders LIMIT 5;
+
+-- 如果需要回滚，执行以下语句：
+-- ALTER TABLE `orders` DROP COLUMN `rate`;
+-- ALTER TABLE `orders` DROP COLUMN `currency`;
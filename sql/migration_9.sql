@@ -0,0 +1,19 @@
+-- 数据库迁移脚本：新增 rate_history 汇率历史表
+-- 执行日期：2026-10-18
+-- 说明：记录每次获取的法币汇率，服务启动时恢复最近一次汇率，订单的 rate 字段记录下单时使用的汇率
+
+CREATE TABLE IF NOT EXISTS `rate_history` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `currency` VARCHAR(10) NOT NULL COMMENT '法币币种（CNY, USD, EUR）',
+  `rate` DECIMAL(20,8) NOT NULL COMMENT '1 USDT 折合的法币数量',
+  `source` VARCHAR(100) NOT NULL DEFAULT '' COMMENT '汇率来源（数据源名称或 median:数据源列表）',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '获取时间',
+  KEY `idx_rate_history_currency` (`currency`, `id`),
+  KEY `idx_rate_history_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='汇率历史表';
+
+-- 验证表是否创建成功
+-- SELECT id, currency, rate, source, created_at FROM rate_history ORDER BY id DESC LIMIT 5;
+
+-- 如果需要回滚，执行以下语句：
+-- DROP TABLE `rate_history`;
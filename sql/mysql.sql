@@ -7,15 +7,23 @@ CREATE TABLE IF NOT EXISTS `orders` (
   `trade_id` VARCHAR(32) NOT NULL COMMENT 'epusdt订单号',
   `order_id` VARCHAR(32) NOT NULL COMMENT '客户交易id',
   `block_transaction_id` VARCHAR(128) DEFAULT NULL COMMENT '区块唯一编号',
-  `actual_amount` DECIMAL(20,8) NOT NULL COMMENT '订单实际需要支付的金额（USDT）',
-  `amount` DECIMAL(20,8) NOT NULL COMMENT '订单金额（人民币）',
+  `actual_amount` DECIMAL(20,8) NOT NULL COMMENT '订单实际需要支付的金额（按支付币种）',
+  `amount` DECIMAL(20,8) NOT NULL COMMENT '订单金额（法币）',
+  `currency` VARCHAR(10) NOT NULL DEFAULT 'CNY' COMMENT '订单金额的法币币种（CNY, USD, EUR）',
+  `rate` DECIMAL(20,8) NOT NULL DEFAULT 0 COMMENT '下单时的汇率快照（1 USDT 折合的法币数量）',
   `token` VARCHAR(50) NOT NULL COMMENT '所属钱包地址',
   `chain_type` VARCHAR(20) NOT NULL DEFAULT 'TRC20' COMMENT '链类型（TRC20, ERC20, BEP20, SOLANA, POLYGON）',
+  `asset` VARCHAR(10) NOT NULL DEFAULT 'USDT' COMMENT '支付币种（USDT=稳定币, TRX, ETH, BNB, SOL, POL）',
   `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=等待支付, 2=支付成功, 3=已过期',
   `notify_url` VARCHAR(128) NOT NULL COMMENT '异步回调地址',
   `redirect_url` VARCHAR(128) DEFAULT NULL COMMENT '同步回调地址',
   `callback_num` INT NOT NULL DEFAULT 0 COMMENT '回调次数',
   `callback_confirm` TINYINT NOT NULL DEFAULT 2 COMMENT '回调是否已确认（1=是, 2=否）',
+  `selected_at` TIMESTAMP NULL DEFAULT NULL COMMENT '开放订单付款人选择链的时间（从该时间开始计算过期）',
+  `theme` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '收银台主题（模板覆盖目录下的子目录，为空使用默认模板）',
+  `payment_link_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '来源支付链接ID（0=商户下单）',
+  `amount_mode` VARCHAR(10) NOT NULL DEFAULT 'FIXED' COMMENT '金额模式（FIXED=固定金额, OPEN=开放金额，按首笔到账金额结算）',
+  `subscription_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '来源订阅ID（0=非订阅账单）',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   `deleted_at` TIMESTAMP NULL DEFAULT NULL,
@@ -25,6 +33,8 @@ CREATE TABLE IF NOT EXISTS `orders` (
   KEY `idx_chain_type` (`chain_type`),
   KEY `idx_orders_status` (`status`),
   KEY `idx_orders_created_at` (`created_at`),
+  KEY `idx_orders_payment_link_id` (`payment_link_id`),
+  KEY `idx_orders_subscription_id` (`subscription_id`),
   KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订单表';
 
@@ -37,12 +47,16 @@ CREATE TABLE IF NOT EXISTS `wallet_address` (
   `balance` DECIMAL(20,8) DEFAULT 0.00000000 COMMENT 'USDT余额',
   `balance_updated_at` TIMESTAMP NULL DEFAULT NULL COMMENT '余额更新时间',
   `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=启用, 2=禁用',
+  `trade_id` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '派生地址关联的订单号，为空表示收款池钱包',
+  `derivation_index` INT UNSIGNED DEFAULT NULL COMMENT '派生地址索引（xpub/0/index）',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   `deleted_at` TIMESTAMP NULL DEFAULT NULL,
   KEY `wallet_address_token_index` (`token`),
   KEY `idx_token_chain_type` (`token`, `chain_type`),
   KEY `idx_wallet_status` (`status`),
+  KEY `idx_wallet_trade_id` (`trade_id`),
+  UNIQUE KEY `idx_chain_derivation_index` (`chain_type`, `derivation_index`),
   KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='钱包地址表';
 
@@ -58,6 +72,22 @@ CREATE TABLE IF NOT EXISTS `cache` (
   KEY `idx_cache_expires` (`expires_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='缓存表';
 
+-- 支付金额锁表（同一钱包、链、金额同一时间只对应一个待支付订单）
+CREATE TABLE IF NOT EXISTS `amount_locks` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `wallet_id` BIGINT UNSIGNED NOT NULL COMMENT '钱包ID（wallet_address.id）',
+  `chain_type` VARCHAR(30) NOT NULL COMMENT '链标识（原生币为 链类型:币种）',
+  `amount` VARCHAR(32) NOT NULL COMMENT '锁定金额（保留4位小数）',
+  `trade_id` VARCHAR(32) NOT NULL COMMENT '交易号',
+  `expires_at` TIMESTAMP NOT NULL COMMENT '过期时间',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY `idx_amount_lock` (`wallet_id`, `amount`, `chain_type`),
+  KEY `idx_amount_lock_pending` (`wallet_id`, `chain_type`, `expires_at`),
+  KEY `idx_amount_lock_chain` (`chain_type`, `expires_at`),
+  KEY `idx_amount_lock_trade_id` (`trade_id`),
+  KEY `idx_amount_lock_expires` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='支付金额锁表';
+
 -- 队列表（替代 Redis 队列）
 CREATE TABLE IF NOT EXISTS `queue_jobs` (
   `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
@@ -76,3 +106,165 @@ CREATE TABLE IF NOT EXISTS `queue_jobs` (
   KEY `idx_queue_task_type` (`task_type`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='队列任务表';
 
+-- 区块链扫描游标表（增量扫描）
+CREATE TABLE IF NOT EXISTS `scan_cursor` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `chain_type` VARCHAR(20) NOT NULL COMMENT '链类型',
+  `address` VARCHAR(50) NOT NULL DEFAULT '' COMMENT '钱包地址，为空表示整条链的游标',
+  `scope` VARCHAR(20) NOT NULL DEFAULT 'token' COMMENT '扫描范围（token=稳定币, native=原生币）',
+  `cursor_value` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '链自定义游标（区块号、slot 等）',
+  `last_timestamp` BIGINT NOT NULL DEFAULT 0 COMMENT '已扫描到的时间（毫秒）',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `idx_scan_cursor` (`chain_type`, `address`, `scope`),
+  KEY `idx_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='区块链扫描游标表';
+
+-- 汇率历史表（记录每次获取的法币汇率，启动时恢复最近一次汇率）
+CREATE TABLE IF NOT EXISTS `rate_history` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `currency` VARCHAR(10) NOT NULL COMMENT '法币币种（CNY, USD, EUR）',
+  `rate` DECIMAL(20,8) NOT NULL COMMENT '1 USDT 折合的法币数量',
+  `source` VARCHAR(100) NOT NULL DEFAULT '' COMMENT '汇率来源（数据源名称或 median:数据源列表）',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '获取时间',
+  KEY `idx_rate_history_currency` (`currency`, `id`),
+  KEY `idx_rate_history_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='汇率历史表';
+
+-- 支付链接表（可重复使用的支付页面，付款人每次提交生成一笔独立订单）
+CREATE TABLE IF NOT EXISTS `payment_links` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `slug` VARCHAR(32) NOT NULL COMMENT '链接标识（/pay/link/:slug）',
+  `title` VARCHAR(100) NOT NULL COMMENT '标题',
+  `description` VARCHAR(500) NOT NULL DEFAULT '' COMMENT '描述',
+  `amount` DECIMAL(20,8) NOT NULL DEFAULT 0 COMMENT '固定金额（0=付款人填写）',
+  `currency` VARCHAR(10) NOT NULL DEFAULT 'CNY' COMMENT '金额币种（CNY, USD, EUR 或 USDT）',
+  `chain_types` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '允许的链类型，逗号分隔（为空=所有可用的链）',
+  `max_uses` INT NOT NULL DEFAULT 0 COMMENT '最多支付次数（0=不限）',
+  `use_count` INT NOT NULL DEFAULT 0 COMMENT '已支付次数',
+  `expires_at` TIMESTAMP NULL DEFAULT NULL COMMENT '过期时间（为空=永不过期）',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=启用, 2=禁用',
+  `notify_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '生成订单的异步回调地址（为空不回调）',
+  `redirect_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '生成订单的同步回调地址',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `payment_links_slug_uindex` (`slug`),
+  KEY `idx_payment_links_status` (`status`),
+  KEY `idx_payment_links_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='支付链接表';
+
+-- 客户充值地址表（商户客户的永久充值地址，customer_id 为空表示地址池中尚未分配）
+CREATE TABLE IF NOT EXISTS `customer_addresses` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `customer_id` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '商户的客户ID（为空=地址池中未分配）',
+  `chain_type` VARCHAR(20) NOT NULL COMMENT '链类型（TRC20, ERC20, BEP20, SOLANA, POLYGON, ARBITRUM）',
+  `token` VARCHAR(50) NOT NULL COMMENT '充值地址',
+  `source` VARCHAR(10) NOT NULL DEFAULT 'pool' COMMENT '地址来源（derive=派生, pool=地址池）',
+  `derivation_index` INT UNSIGNED DEFAULT NULL COMMENT '派生地址索引（xpub/0/index，与订单派生地址共用）',
+  `notify_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '充值回调地址（为空=使用 deposit_notify_url）',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=启用, 2=禁用',
+  `assigned_at` TIMESTAMP NULL DEFAULT NULL COMMENT '分配时间',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `customer_addresses_token_chain_uindex` (`token`, `chain_type`),
+  UNIQUE KEY `idx_customer_addresses_derivation_index` (`chain_type`, `derivation_index`),
+  KEY `idx_customer_addresses_customer` (`customer_id`, `chain_type`),
+  KEY `idx_customer_addresses_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='客户充值地址表';
+
+-- 客户充值记录表（客户充值地址每收到一笔稳定币转账记录一条）
+CREATE TABLE IF NOT EXISTS `customer_deposits` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `customer_address_id` BIGINT UNSIGNED NOT NULL COMMENT '充值地址ID',
+  `customer_id` VARCHAR(64) NOT NULL COMMENT '商户的客户ID',
+  `chain_type` VARCHAR(20) NOT NULL COMMENT '链类型',
+  `token` VARCHAR(50) NOT NULL COMMENT '充值地址',
+  `from_address` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '转出地址',
+  `asset` VARCHAR(10) NOT NULL DEFAULT 'USDT' COMMENT '代币符号（USDT, USDC）',
+  `amount` DECIMAL(20,8) NOT NULL COMMENT '到账金额',
+  `block_transaction_id` VARCHAR(128) NOT NULL COMMENT '交易哈希',
+  `block_timestamp` BIGINT NOT NULL DEFAULT 0 COMMENT '区块时间戳（毫秒）',
+  `notify_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '充值回调地址（为空不回调）',
+  `callback_num` INT NOT NULL DEFAULT 0 COMMENT '回调次数',
+  `callback_confirm` TINYINT NOT NULL DEFAULT 2 COMMENT '回调是否已确认（1=是, 2=否）',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `customer_deposits_tx_uindex` (`chain_type`, `block_transaction_id`, `customer_address_id`),
+  KEY `idx_customer_deposits_customer_id` (`customer_id`),
+  KEY `idx_customer_deposits_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='客户充值记录表';
+
+-- 订阅表（按周期自动生成账单订单，逾期按间隔催缴）
+CREATE TABLE IF NOT EXISTS `subscriptions` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `subscription_no` VARCHAR(32) NOT NULL COMMENT '订阅编号',
+  `customer_id` VARCHAR(64) NOT NULL COMMENT '商户的客户ID',
+  `customer_email` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '客户邮箱（通过邮件 webhook 发送账单）',
+  `telegram_chat_id` BIGINT NOT NULL DEFAULT 0 COMMENT '客户 Telegram chat ID（0=不通过机器人发送账单）',
+  `title` VARCHAR(100) NOT NULL COMMENT '账单标题',
+  `amount` DECIMAL(20,8) NOT NULL COMMENT '每期金额',
+  `currency` VARCHAR(10) NOT NULL DEFAULT 'CNY' COMMENT '金额币种（CNY, USD, EUR 或 USDT）',
+  `chain_type` VARCHAR(20) NOT NULL DEFAULT 'OPEN' COMMENT '支付链类型（OPEN=客户在收银台选择）',
+  `interval_unit` VARCHAR(10) NOT NULL COMMENT '周期单位（DAY, WEEK, MONTH, YEAR）',
+  `interval_count` INT NOT NULL DEFAULT 1 COMMENT '周期数',
+  `start_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '首期账单日',
+  `due_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '当前账期的账单日',
+  `next_run_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '下次生成账单的时间（逾期时为催缴时间）',
+  `current_trade_id` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '当前账期待支付的订单号',
+  `retry_count` INT NOT NULL DEFAULT 0 COMMENT '当前账期已失败的账单数',
+  `paid_periods` INT NOT NULL DEFAULT 0 COMMENT '已支付期数',
+  `notify_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '订阅事件回调地址（为空不回调）',
+  `redirect_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '账单订单的同步回调地址',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=正常, 2=逾期, 3=已取消',
+  `cancelled_at` TIMESTAMP NULL DEFAULT NULL COMMENT '取消时间',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `subscriptions_subscription_no_uindex` (`subscription_no`),
+  KEY `idx_subscriptions_customer_id` (`customer_id`),
+  KEY `idx_subscriptions_next_run` (`status`, `next_run_at`),
+  KEY `idx_subscriptions_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='订阅表';
+
+-- 退款表（退款交易由商户在外部签名发出，提交交易哈希后在链上核验）
+CREATE TABLE IF NOT EXISTS `refunds` (
+  `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+  `refund_no` VARCHAR(32) NOT NULL COMMENT '退款编号',
+  `trade_id` VARCHAR(32) NOT NULL COMMENT 'epusdt订单号',
+  `order_id` VARCHAR(32) NOT NULL COMMENT '客户交易id',
+  `chain_type` VARCHAR(20) NOT NULL COMMENT '链类型（与订单支付链一致）',
+  `asset` VARCHAR(10) NOT NULL DEFAULT 'USDT' COMMENT '退款币种（与订单支付币种一致）',
+  `amount` DECIMAL(20,8) NOT NULL COMMENT '退款金额（按支付币种）',
+  `to_address` VARCHAR(64) NOT NULL COMMENT '退款地址',
+  `reason` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '退款原因',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=待退款, 2=核验中, 3=已完成, 4=核验失败, 5=已取消',
+  `tx_hash` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '退款交易哈希',
+  `active_tx_hash` VARCHAR(128) GENERATED ALWAYS AS (IF(`status` <> 5 AND `tx_hash` <> '', `tx_hash`, NULL)) STORED COMMENT '未取消退款的交易哈希（唯一，防止同一交易用于多笔退款）',
+  `from_address` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '退款转出地址（核验通过后记录）',
+  `fail_reason` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '核验失败原因',
+  `submitted_at` TIMESTAMP NULL DEFAULT NULL COMMENT '提交交易哈希的时间',
+  `completed_at` TIMESTAMP NULL DEFAULT NULL COMMENT '核验通过的时间',
+  `notify_url` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '退款回调地址（为空不回调）',
+  `callback_num` INT NOT NULL DEFAULT 0 COMMENT '回调次数',
+  `callback_confirm` TINYINT NOT NULL DEFAULT 2 COMMENT '回调是否已确认（1=是, 2=否）',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` TIMESTAMP NULL DEFAULT NULL,
+  UNIQUE KEY `refunds_refund_no_uindex` (`refund_no`),
+  KEY `idx_refunds_trade_id` (`trade_id`),
+  KEY `idx_refunds_tx_hash` (`chain_type`, `tx_hash`),
+  UNIQUE KEY `idx_refunds_active_tx_hash` (`chain_type`, `active_tx_hash`),
+  KEY `idx_refunds_status` (`status`),
+  KEY `idx_refunds_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='退款表';
+
+-- 派生地址索引计数器表（每条链一行，订单派生地址与客户充值地址共用，原子分配避免并发派生出相同地址）
+CREATE TABLE IF NOT EXISTS `derivation_counters` (
+  `chain_type` VARCHAR(20) NOT NULL PRIMARY KEY COMMENT '链类型',
+  `next_index` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '下一个未分配的派生索引',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='派生地址索引计数器表';
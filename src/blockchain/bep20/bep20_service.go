@@ -19,8 +19,13 @@ import (
 )
 
 const (
+	EtherscanApiV2Uri        = "https://api.etherscan.io/v2/api"            // Etherscan API V2，用于查询BNB转账
+	BSCChainID               = "56"                                         // BSC Mainnet
 	USDTContractAddressBEP20 = "0x55d398326f99059fF775485246999027B3197955" // USDT on BSC
 	USDCContractAddressBEP20 = "0x8AC76a51cc950d9822D68b83fE1Ad97B32Cd580d" // USDC on BSC
+	EtherscanPageSize        = 100                                          // Etherscan 单页条数
+	BEP20LogsBlockRange      = 5000                                         // eth_getLogs 单次查询的区块区间
+	BSCBlockTime             = 3                                            // BSC 平均出块时间（秒）
 )
 
 type BEP20Service struct {
@@ -61,6 +66,23 @@ type BEP20Transfer struct {
 	Confirmations     string `json:"confirmations"`
 }
 
+type BscScanNativeResponse struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Result  []BEP20NativeTx `json:"result"`
+}
+
+type BEP20NativeTx struct {
+	BlockNumber   string `json:"blockNumber"`
+	TimeStamp     string `json:"timeStamp"`
+	Hash          string `json:"hash"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Value         string `json:"value"`
+	IsError       string `json:"isError"`
+	Confirmations string `json:"confirmations"`
+}
+
 func NewBEP20Service() *BEP20Service {
 	bep20ServiceOnce.Do(func() {
 		bep20ServiceInstance = &BEP20Service{
@@ -79,6 +101,18 @@ func (s *BEP20Service) GetUSDTContractAddress() string {
 	return USDTContractAddressBEP20
 }
 
+func (s *BEP20Service) GetNativeSymbol() string {
+	return mdb.AssetBNB
+}
+
+// GetPaymentURI 生成 EIP-681 支付链接
+func (s *BEP20Service) GetPaymentURI(address string, asset string, amount float64) string {
+	if asset == mdb.AssetUSDT {
+		return blockchain.BuildEIP681TokenURI(BSCChainID, USDTContractAddressBEP20, 18, address, amount)
+	}
+	return blockchain.BuildEIP681NativeURI(BSCChainID, address, amount)
+}
+
 func (s *BEP20Service) ValidateAddress(address string) bool {
 	// BEP20地址格式与ERC20相同，以0x开头，42个字符
 	match, _ := regexp.MatchString(`^0x[a-fA-F0-9]{40}$`, address)
@@ -87,21 +121,79 @@ func (s *BEP20Service) ValidateAddress(address string) bool {
 
 func (s *BEP20Service) GetTransactions(address string, startTime int64, endTime int64) ([]blockchain.Transaction, error) {
 	// 同时查询 USDT 和 USDC 交易
-	usdtTxs, err := s.getTransactionsByContract(address, startTime, endTime, USDTContractAddressBEP20)
-	if err != nil {
-		// USDT 查询失败，记录错误但继续查询 USDC
-		usdtTxs = []blockchain.Transaction{}
+	// 任一代币查询失败都返回错误，避免调用方推进游标后遗漏该时间段的交易
+	return blockchain.JoinTransactions(
+		func() ([]blockchain.Transaction, error) {
+			return s.getTransactionsByContract(address, startTime, endTime, USDTContractAddressBEP20)
+		},
+		func() ([]blockchain.Transaction, error) {
+			return s.getTransactionsByContract(address, startTime, endTime, USDCContractAddressBEP20)
+		},
+	)
+}
+
+// ScanTransfers 区块扫描模式：扫描新区块中 USDT/USDC 合约的全部转账
+func (s *BEP20Service) ScanTransfers(cursor string, startTime int64, endTime int64, match func(to string) bool) ([]blockchain.Transaction, string, error) {
+	return blockchain.ScanEvmTransfers(config.GetBep20RpcUrl(), s.getTokenContracts(), BSCBlockTime*1000, cursor, startTime, endTime, match)
+}
+
+// GetTransfersByHash 通过 RPC 查询交易中转入 to 地址的 USDT/USDC 和原生币转账
+func (s *BEP20Service) GetTransfersByHash(hash string, to string) ([]blockchain.Transaction, error) {
+	return blockchain.GetEvmTransfersByHash(config.GetBep20RpcUrl(), s.getTokenContracts(), hash, to)
+}
+
+// getTokenContracts 获取 USDT/USDC 合约配置
+func (s *BEP20Service) getTokenContracts() []blockchain.EvmTokenContract {
+	// BEP20 USDT/USDC 都是 18 位小数
+	return []blockchain.EvmTokenContract{
+		{Address: USDTContractAddressBEP20, Decimals: 18},
+		{Address: USDCContractAddressBEP20, Decimals: 18},
+	}
+}
+
+// GetTransactionsByCursor 从已扫描的区块号之后增量查询交易，游标为区块号
+func (s *BEP20Service) GetTransactionsByCursor(address string, cursor string, startTime int64, endTime int64) ([]blockchain.Transaction, string, error) {
+	rpcUrl := config.GetBep20RpcUrl()
+	if rpcUrl == "" {
+		return nil, cursor, fmt.Errorf("未配置 BEP20 RPC URL")
 	}
 
-	usdcTxs, err := s.getTransactionsByContract(address, startTime, endTime, USDCContractAddressBEP20)
+	// 速率限制，等待令牌
+	s.mu.Lock()
+	<-s.rateLimiter.C
+	s.mu.Unlock()
+
+	latestBlock, err := s.getLatestBlockNumber(rpcUrl)
 	if err != nil {
-		// USDC 查询失败，记录错误但继续
-		usdcTxs = []blockchain.Transaction{}
+		return nil, cursor, err
+	}
+
+	// 补扫窗口：按出块时间估算 startTime 对应的区块，游标更新时从游标之后开始
+	fromBlock := latestBlock - (endTime-startTime)/1000/BSCBlockTime
+	if lastBlock, err := strconv.ParseInt(cursor, 10, 64); err == nil && lastBlock+1 > fromBlock {
+		fromBlock = lastBlock + 1
+	}
+	if fromBlock > latestBlock {
+		return []blockchain.Transaction{}, cursor, nil
+	}
+
+	// 单次最多扫描 maxPages 个区间，剩余区块留到下个周期继续
+	toBlock := latestBlock
+	if maxBlock := fromBlock + int64(config.GetBlockchainMaxPages())*BEP20LogsBlockRange - 1; toBlock > maxBlock {
+		toBlock = maxBlock
 	}
 
-	// 合并交易列表
-	allTxs := append(usdtTxs, usdcTxs...)
-	return allTxs, nil
+	transactions := make([]blockchain.Transaction, 0)
+	for _, contractAddress := range []string{USDTContractAddressBEP20, USDCContractAddressBEP20} {
+		txs, err := s.getTransactionsInBlockRange(rpcUrl, address, contractAddress, fromBlock, toBlock, startTime, endTime)
+		if err != nil {
+			// 任一合约查询失败都不推进游标，下个周期重试
+			return nil, cursor, err
+		}
+		transactions = append(transactions, txs...)
+	}
+
+	return transactions, strconv.FormatInt(toBlock, 10), nil
 }
 
 // getTransactionsByContract 查询指定合约地址的交易（使用 OnFinality RPC）
@@ -116,13 +208,26 @@ func (s *BEP20Service) getTransactionsByContract(address string, startTime int64
 	<-s.rateLimiter.C
 	s.mu.Unlock()
 
-	client := http_client.GetHttpClient()
-
 	// BSC 平均 3 秒一个块，24 小时约 28800 个块
 	blockCount := int64(28800)
 
-	// 获取最新区块号
-	latestBlockResp, err := client.R().
+	latestBlock, err := s.getLatestBlockNumber(rpcUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只扫描最近的 maxPages 个区间，避免单次请求过多
+	startBlock := latestBlock - blockCount
+	if minBlock := latestBlock - int64(config.GetBlockchainMaxPages())*BEP20LogsBlockRange + 1; startBlock < minBlock {
+		startBlock = minBlock
+	}
+
+	return s.getTransactionsInBlockRange(rpcUrl, address, contractAddress, startBlock, latestBlock, startTime, endTime)
+}
+
+// getLatestBlockNumber 获取最新区块号
+func (s *BEP20Service) getLatestBlockNumber(rpcUrl string) (int64, error) {
+	latestBlockResp, err := http_client.GetHttpClient().R().
 		SetHeader("Content-Type", "application/json").
 		SetBody(map[string]interface{}{
 			"jsonrpc": "2.0",
@@ -133,85 +238,58 @@ func (s *BEP20Service) getTransactionsByContract(address string, startTime int64
 		Post(rpcUrl)
 
 	if err != nil {
-		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+		return 0, fmt.Errorf("获取最新区块失败: %w", err)
 	}
 
 	var blockNumResp struct {
 		Result string `json:"result"`
 	}
 	if err := json.Cjson.Unmarshal(latestBlockResp.Body(), &blockNumResp); err != nil {
-		return nil, fmt.Errorf("解析区块号失败: %w", err)
+		return 0, fmt.Errorf("解析区块号失败: %w", err)
 	}
 
 	// 将十六进制区块号转换为十进制
-	latestBlock, _ := strconv.ParseInt(strings.TrimPrefix(blockNumResp.Result, "0x"), 16, 64)
-	startBlock := latestBlock - blockCount
-
-	// Transfer 事件签名
-	transferEventSignature := "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
-
-	// 构造接收地址 topic（补齐到 32 字节）
-	toAddress := "0x" + strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(address), "0x")
-
-	// 使用 eth_getLogs 查询事件
-	resp, err := client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(map[string]interface{}{
-			"id":      1,
-			"jsonrpc": "2.0",
-			"method":  "eth_getLogs",
-			"params": []interface{}{
-				map[string]interface{}{
-					"fromBlock": fmt.Sprintf("0x%x", startBlock),
-					"toBlock":   fmt.Sprintf("0x%x", latestBlock),
-					"address":   contractAddress,
-					"topics": []interface{}{
-						transferEventSignature,
-						nil,
-						toAddress,
-					},
-				},
-			},
-		}).
-		Post(rpcUrl)
-
+	latestBlock, err := strconv.ParseInt(strings.TrimPrefix(blockNumResp.Result, "0x"), 16, 64)
 	if err != nil {
-		return nil, fmt.Errorf("eth_getLogs 请求失败: %w", err)
+		return 0, fmt.Errorf("解析区块号失败: %w", err)
 	}
+	return latestBlock, nil
+}
 
-	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("RPC 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
-	}
+// getTransactionsInBlockRange 查询区块区间内指定合约转入地址的交易
+func (s *BEP20Service) getTransactionsInBlockRange(rpcUrl, address, contractAddress string, startBlock, endBlock int64, startTime int64, endTime int64) ([]blockchain.Transaction, error) {
+	client := http_client.GetHttpClient()
 
-	// 解析 JSON-RPC 响应
-	var rpcResp struct {
-		Result []struct {
-			BlockNumber     string   `json:"blockNumber"`
-			TransactionHash string   `json:"transactionHash"`
-			Topics          []string `json:"topics"`
-			Data            string   `json:"data"`
-		} `json:"result"`
-		Error *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error"`
-	}
+	// 构造接收地址 topic（补齐到 32 字节）
+	toAddress := "0x" + strings.Repeat("0", 24) + strings.TrimPrefix(strings.ToLower(address), "0x")
 
-	if err := json.Cjson.Unmarshal(resp.Body(), &rpcResp); err != nil {
-		return nil, fmt.Errorf("解析 RPC 响应失败: %w", err)
-	}
+	// 按区间分段查询，避免单次 eth_getLogs 区间过大被节点拒绝或截断
+	logs := make([]bep20TransferLog, 0)
+	for fromBlock := startBlock; fromBlock <= endBlock; fromBlock += BEP20LogsBlockRange {
+		toBlock := fromBlock + BEP20LogsBlockRange - 1
+		if toBlock > endBlock {
+			toBlock = endBlock
+		}
+		if fromBlock > startBlock {
+			s.mu.Lock()
+			<-s.rateLimiter.C
+			s.mu.Unlock()
+		}
 
-	if rpcResp.Error != nil {
-		return nil, fmt.Errorf("RPC 错误: %s", rpcResp.Error.Message)
+		chunk, err := s.getTransferLogs(rpcUrl, contractAddress, toAddress, fromBlock, toBlock)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, chunk...)
 	}
 
 	// 如果没有结果，返回空数组
-	if len(rpcResp.Result) == 0 {
+	if len(logs) == 0 {
 		return []blockchain.Transaction{}, nil
 	}
 
 	transactions := make([]blockchain.Transaction, 0)
-	for _, log := range rpcResp.Result {
+	for _, log := range logs {
 		// 解析区块号
 		blockNum, _ := strconv.ParseInt(strings.TrimPrefix(log.BlockNumber, "0x"), 16, 64)
 
@@ -226,8 +304,9 @@ func (s *BEP20Service) getTransactionsByContract(address string, startTime int64
 			}).
 			Post(rpcUrl)
 
+		// 获取区块时间失败时整体返回错误，避免游标越过未处理的交易
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("获取区块信息失败: %w", err)
 		}
 
 		var blockInfo struct {
@@ -236,7 +315,7 @@ func (s *BEP20Service) getTransactionsByContract(address string, startTime int64
 			} `json:"result"`
 		}
 		if err := json.Cjson.Unmarshal(blockResp.Body(), &blockInfo); err != nil {
-			continue
+			return nil, fmt.Errorf("解析区块信息失败: %w", err)
 		}
 
 		// 解析时间戳
@@ -292,6 +371,181 @@ func (s *BEP20Service) getTransactionsByContract(address string, startTime int64
 	return transactions, nil
 }
 
+type bep20TransferLog struct {
+	BlockNumber     string   `json:"blockNumber"`
+	TransactionHash string   `json:"transactionHash"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+}
+
+// getTransferLogs 使用 eth_getLogs 查询指定区块区间内转入地址的 Transfer 事件
+func (s *BEP20Service) getTransferLogs(rpcUrl, contractAddress, toAddress string, fromBlock, toBlock int64) ([]bep20TransferLog, error) {
+	// Transfer 事件签名
+	transferEventSignature := "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+	resp, err := http_client.GetHttpClient().R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]interface{}{
+			"id":      1,
+			"jsonrpc": "2.0",
+			"method":  "eth_getLogs",
+			"params": []interface{}{
+				map[string]interface{}{
+					"fromBlock": fmt.Sprintf("0x%x", fromBlock),
+					"toBlock":   fmt.Sprintf("0x%x", toBlock),
+					"address":   contractAddress,
+					"topics": []interface{}{
+						transferEventSignature,
+						nil,
+						toAddress,
+					},
+				},
+			},
+		}).
+		Post(rpcUrl)
+
+	if err != nil {
+		return nil, fmt.Errorf("eth_getLogs 请求失败: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("RPC 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	// 解析 JSON-RPC 响应
+	var rpcResp struct {
+		Result []bep20TransferLog `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if err := json.Cjson.Unmarshal(resp.Body(), &rpcResp); err != nil {
+		return nil, fmt.Errorf("解析 RPC 响应失败: %w", err)
+	}
+
+	if rpcResp.Error != nil {
+		return nil, fmt.Errorf("RPC 错误: %s", rpcResp.Error.Message)
+	}
+
+	return rpcResp.Result, nil
+}
+
+// GetNativeTransactions 获取地址的BNB转入记录（普通交易 + 内部交易）
+func (s *BEP20Service) GetNativeTransactions(address string, startTime int64, endTime int64) ([]blockchain.Transaction, error) {
+	// 内部交易（合约转出的BNB）查询失败时整体返回错误，避免游标越过未获取的交易
+	return blockchain.JoinTransactions(
+		func() ([]blockchain.Transaction, error) {
+			return s.getNativeTransactionsByAction(address, startTime, endTime, "txlist")
+		},
+		func() ([]blockchain.Transaction, error) {
+			return s.getNativeTransactionsByAction(address, startTime, endTime, "txlistinternal")
+		},
+	)
+}
+
+// getNativeTransactionsByAction 按 action 查询原生币交易，txlist 为普通交易，txlistinternal 为内部交易
+func (s *BEP20Service) getNativeTransactionsByAction(address string, startTime int64, endTime int64, action string) ([]blockchain.Transaction, error) {
+	apiKey := config.GetBscScanApiKey()
+	if apiKey == "" {
+		return nil, fmt.Errorf("未配置 BscScan API 密钥（bscscan_api_key 或 etherscan_api_key）")
+	}
+
+	transactions := make([]blockchain.Transaction, 0)
+	maxPages := config.GetBlockchainMaxPages()
+	for page := 1; page <= maxPages; page++ {
+		// 速率限制，等待令牌
+		s.mu.Lock()
+		<-s.rateLimiter.C
+		s.mu.Unlock()
+
+		client := http_client.GetHttpClient()
+
+		resp, err := client.R().SetQueryParams(map[string]string{
+			"chainid":    BSCChainID,
+			"module":     "account",
+			"action":     action,
+			"address":    address,
+			"page":       strconv.Itoa(page),
+			"offset":     strconv.Itoa(EtherscanPageSize),
+			"startblock": "0",
+			"endblock":   "99999999",
+			"sort":       "desc",
+			"apikey":     apiKey,
+		}).Get(EtherscanApiV2Uri)
+
+		if err != nil {
+			return nil, fmt.Errorf("Etherscan API 请求失败: %w", err)
+		}
+
+		if resp.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("Etherscan API 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
+		}
+
+		var nativeResp BscScanNativeResponse
+		err = json.Cjson.Unmarshal(resp.Body(), &nativeResp)
+		if err != nil {
+			return nil, fmt.Errorf("解析 Etherscan API 响应失败: %w, 响应内容: %s", err, string(resp.Body()))
+		}
+
+		// 没有交易时停止翻页；速率限制、API 密钥错误等返回错误，避免游标越过未获取的交易
+		if nativeResp.Status != "1" {
+			if nativeResp.Message == blockchain.EtherscanNoTransactionsMessage {
+				return transactions, nil
+			}
+			return nil, fmt.Errorf("Etherscan API 返回错误: %s, 响应: %s", nativeResp.Message, string(resp.Body()))
+		}
+
+		for _, transfer := range nativeResp.Result {
+			// 只处理成功的转入交易
+			if !strings.EqualFold(transfer.To, address) || transfer.IsError != "0" {
+				continue
+			}
+
+			timestamp, err := strconv.ParseInt(transfer.TimeStamp, 10, 64)
+			if err != nil {
+				continue
+			}
+			timestampMs := timestamp * 1000
+			if timestampMs < startTime || timestampMs > endTime {
+				continue
+			}
+
+			// BNB 是18位小数
+			decimalQuant, err := decimal.NewFromString(transfer.Value)
+			if err != nil || decimalQuant.IsZero() {
+				continue
+			}
+			amount, _ := decimalQuant.Div(decimal.New(1, 18)).Round(blockchain.NativeAmountDecimals).Float64()
+
+			confirmations, _ := strconv.Atoi(transfer.Confirmations)
+
+			transactions = append(transactions, blockchain.Transaction{
+				Hash:           transfer.Hash,
+				From:           transfer.From,
+				To:             transfer.To,
+				Amount:         amount,
+				BlockTimestamp: timestampMs,
+				Confirmations:  confirmations,
+				Status:         "SUCCESS",
+			})
+		}
+
+		// 结果按时间倒序，不足一页或已早于开始时间则停止翻页
+		if len(nativeResp.Result) < EtherscanPageSize {
+			return transactions, nil
+		}
+		lastTimestamp, _ := strconv.ParseInt(nativeResp.Result[len(nativeResp.Result)-1].TimeStamp, 10, 64)
+		if lastTimestamp*1000 < startTime {
+			return transactions, nil
+		}
+	}
+
+	// 达到翻页上限时仍有更早的交易未获取，返回已获取的交易，调用方不推进游标
+	return transactions, blockchain.ErrMaxPagesReached
+}
+
 // GetTokenBalance 获取地址的代币余额（USDT + USDC）
 func (s *BEP20Service) GetTokenBalance(address string) (*blockchain.TokenBalance, error) {
 	apiKey := config.GetEtherscanApiKey()
@@ -0,0 +1,4 @@
+package blockchain
+
+// EtherscanNoTransactionsMessage Etherscan 系 API 没有交易时 status 为0，message 为该值，不属于请求失败
+const EtherscanNoTransactionsMessage = "No transactions found"
@@ -0,0 +1,219 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/util/http_client"
+	"github.com/assimon/luuu/util/json"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// TransferEventSignature ERC20 Transfer 事件签名
+	TransferEventSignature = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	// EvmScanBlockRange 区块扫描时 eth_getLogs 单次查询的区块数，代币转账频繁，区间不宜过大
+	EvmScanBlockRange = 20
+)
+
+// EvmTokenContract 区块扫描的代币合约
+type EvmTokenContract struct {
+	Address  string // 合约地址
+	Decimals int32  // 代币精度
+}
+
+type evmTransferLog struct {
+	Address         string   `json:"address"`
+	BlockNumber     string   `json:"blockNumber"`
+	TransactionHash string   `json:"transactionHash"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+	Removed         bool     `json:"removed"`
+}
+
+// ScanEvmTransfers 通过 RPC 扫描游标之后区块中代币合约的 Transfer 事件
+// blockTimeMs 为链的平均出块时间（毫秒），用于估算补扫窗口对应的区块数
+func ScanEvmTransfers(rpcUrl string, contracts []EvmTokenContract, blockTimeMs int64, cursor string, startTime int64, endTime int64, match func(to string) bool) ([]Transaction, string, error) {
+	latest, err := getEvmBlockNumber(rpcUrl)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	// 单次最多扫描 maxPages 个区间，剩余区块留到下个周期继续
+	maxBlocks := int64(config.GetBlockchainMaxPages()) * EvmScanBlockRange
+
+	var fromBlock int64
+	if lastBlock, err := strconv.ParseInt(cursor, 10, 64); err == nil {
+		// 从游标之后继续扫描，游标落后于补扫窗口（按出块时间估算 startTime 对应的区块）时从窗口起点开始
+		fromBlock = lastBlock + 1
+		if windowStart := latest - (endTime-startTime)/blockTimeMs; fromBlock < windowStart {
+			fromBlock = windowStart
+		}
+	} else {
+		// 首次扫描没有游标，从最新区块附近开始，之后按游标增量推进
+		fromBlock = latest - maxBlocks + 1
+	}
+	if fromBlock > latest {
+		return []Transaction{}, cursor, nil
+	}
+
+	toBlock := latest
+	if maxBlock := fromBlock + maxBlocks - 1; toBlock > maxBlock {
+		toBlock = maxBlock
+	}
+
+	// RPC 返回的合约地址为小写，按小写地址索引配置的合约
+	contractMap := make(map[string]EvmTokenContract, len(contracts))
+	addresses := make([]string, 0, len(contracts))
+	for _, contract := range contracts {
+		contractMap[strings.ToLower(contract.Address)] = contract
+		addresses = append(addresses, contract.Address)
+	}
+
+	transactions := make([]Transaction, 0)
+	blockTimestamps := make(map[string]int64)
+	for chunkFrom := fromBlock; chunkFrom <= toBlock; chunkFrom += EvmScanBlockRange {
+		chunkTo := chunkFrom + EvmScanBlockRange - 1
+		if chunkTo > toBlock {
+			chunkTo = toBlock
+		}
+
+		result, err := evmRpcCall(rpcUrl, "eth_getLogs", []interface{}{
+			map[string]interface{}{
+				"fromBlock": fmt.Sprintf("0x%x", chunkFrom),
+				"toBlock":   fmt.Sprintf("0x%x", chunkTo),
+				"address":   addresses,
+				"topics":    []interface{}{TransferEventSignature},
+			},
+		})
+		if err != nil {
+			return nil, cursor, fmt.Errorf("eth_getLogs 请求失败: %w", err)
+		}
+		var logs []evmTransferLog
+		if err := json.Cjson.Unmarshal(result, &logs); err != nil {
+			return nil, cursor, fmt.Errorf("解析 eth_getLogs 响应失败: %w", err)
+		}
+
+		for _, log := range logs {
+			if log.Removed || len(log.Topics) < 3 || len(log.Topics[1]) < 40 || len(log.Topics[2]) < 40 {
+				continue
+			}
+
+			// 先在内存中匹配接收地址，只对命中的转账查询区块时间
+			to := "0x" + strings.ToLower(log.Topics[2][len(log.Topics[2])-40:])
+			if !match(to) {
+				continue
+			}
+
+			valueBigInt := new(big.Int)
+			if _, ok := valueBigInt.SetString(strings.TrimPrefix(log.Data, "0x"), 16); !ok {
+				continue
+			}
+			contract, ok := contractMap[strings.ToLower(log.Address)]
+			if !ok {
+				continue
+			}
+			// 金额统一保留4位小数，避免精度不匹配问题
+			amount, _ := decimal.NewFromBigInt(valueBigInt, 0).Div(decimal.New(1, contract.Decimals)).Round(4).Float64()
+
+			timestamp, ok := blockTimestamps[log.BlockNumber]
+			if !ok {
+				timestamp, err = getEvmBlockTimestamp(rpcUrl, log.BlockNumber)
+				if err != nil {
+					// 获取区块时间失败时整体返回错误，避免游标越过未处理的交易
+					return nil, cursor, err
+				}
+				blockTimestamps[log.BlockNumber] = timestamp
+			}
+
+			transactions = append(transactions, Transaction{
+				Hash:            log.TransactionHash,
+				From:            "0x" + strings.ToLower(log.Topics[1][len(log.Topics[1])-40:]),
+				To:              to,
+				Amount:          amount,
+				BlockTimestamp:  timestamp,
+				Confirmations:   0,
+				Status:          "SUCCESS",
+				ContractAddress: contract.Address,
+			})
+		}
+	}
+
+	return transactions, strconv.FormatInt(toBlock, 10), nil
+}
+
+// getEvmBlockNumber 获取最新区块号
+func getEvmBlockNumber(rpcUrl string) (int64, error) {
+	result, err := evmRpcCall(rpcUrl, "eth_blockNumber", []interface{}{})
+	if err != nil {
+		return 0, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	var latestHex string
+	if err := json.Cjson.Unmarshal(result, &latestHex); err != nil {
+		return 0, fmt.Errorf("解析区块号失败: %w", err)
+	}
+	latest, err := strconv.ParseInt(strings.TrimPrefix(latestHex, "0x"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析区块号失败: %w", err)
+	}
+	return latest, nil
+}
+
+// getEvmBlockTimestamp 获取区块时间戳，毫秒
+func getEvmBlockTimestamp(rpcUrl string, blockNumber string) (int64, error) {
+	result, err := evmRpcCall(rpcUrl, "eth_getBlockByNumber", []interface{}{blockNumber, false})
+	if err != nil {
+		return 0, fmt.Errorf("获取区块信息失败: %w", err)
+	}
+	var blockInfo struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := json.Cjson.Unmarshal(result, &blockInfo); err != nil {
+		return 0, fmt.Errorf("解析区块信息失败: %w", err)
+	}
+	timestamp, err := strconv.ParseInt(strings.TrimPrefix(blockInfo.Timestamp, "0x"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析区块时间失败: %w", err)
+	}
+	return timestamp * 1000, nil
+}
+
+// evmRpcCall 发起 JSON-RPC 请求，返回 result 原始内容
+func evmRpcCall(rpcUrl string, method string, params []interface{}) ([]byte, error) {
+	resp, err := http_client.GetHttpClient().R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  method,
+			"params":  params,
+			"id":      1,
+		}).
+		Post(rpcUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("RPC 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var rpcResp struct {
+		Result jsoniter.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Cjson.Unmarshal(resp.Body(), &rpcResp); err != nil {
+		return nil, fmt.Errorf("解析 RPC 响应失败: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return nil, fmt.Errorf("RPC 错误: %s", rpcResp.Error.Message)
+	}
+	return rpcResp.Result, nil
+}
@@ -0,0 +1,126 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/assimon/luuu/util/json"
+	"github.com/shopspring/decimal"
+)
+
+// GetEvmTransfersByHash 通过 RPC 查询交易中转入 to 地址的代币和原生币转账
+// 代币转账从交易回执的 Transfer 事件解析，只统计 contracts 中配置的合约
+func GetEvmTransfersByHash(rpcUrl string, contracts []EvmTokenContract, hash string, to string) ([]Transaction, error) {
+	result, err := evmRpcCall(rpcUrl, "eth_getTransactionReceipt", []interface{}{hash})
+	if err != nil {
+		return nil, fmt.Errorf("获取交易回执失败: %w", err)
+	}
+	// 交易不存在或尚未打包时 result 为 null
+	if len(result) == 0 || string(result) == "null" {
+		return []Transaction{}, nil
+	}
+	var receipt *struct {
+		BlockNumber string           `json:"blockNumber"`
+		Status      string           `json:"status"`
+		Logs        []evmTransferLog `json:"logs"`
+	}
+	if err := json.Cjson.Unmarshal(result, &receipt); err != nil {
+		return nil, fmt.Errorf("解析交易回执失败: %w", err)
+	}
+	if receipt == nil || receipt.BlockNumber == "" {
+		return []Transaction{}, nil
+	}
+	if receipt.Status != "0x1" {
+		return nil, ErrTransactionFailed
+	}
+
+	blockNumber, err := strconv.ParseInt(strings.TrimPrefix(receipt.BlockNumber, "0x"), 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析区块号失败: %w", err)
+	}
+	latest, err := getEvmBlockNumber(rpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := getEvmBlockTimestamp(rpcUrl, receipt.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	confirmations := int(latest - blockNumber + 1)
+
+	contractMap := make(map[string]EvmTokenContract, len(contracts))
+	for _, contract := range contracts {
+		contractMap[strings.ToLower(contract.Address)] = contract
+	}
+
+	target := strings.ToLower(to)
+	transactions := make([]Transaction, 0)
+	for _, log := range receipt.Logs {
+		if log.Removed || len(log.Topics) < 3 || len(log.Topics[1]) < 40 || len(log.Topics[2]) < 40 {
+			continue
+		}
+		if !strings.EqualFold(log.Topics[0], TransferEventSignature) {
+			continue
+		}
+		contract, ok := contractMap[strings.ToLower(log.Address)]
+		if !ok {
+			continue
+		}
+		logTo := "0x" + strings.ToLower(log.Topics[2][len(log.Topics[2])-40:])
+		if logTo != target {
+			continue
+		}
+		valueBigInt := new(big.Int)
+		if _, ok := valueBigInt.SetString(strings.TrimPrefix(log.Data, "0x"), 16); !ok {
+			continue
+		}
+		// 金额统一保留4位小数，避免精度不匹配问题
+		amount, _ := decimal.NewFromBigInt(valueBigInt, 0).Div(decimal.New(1, contract.Decimals)).Round(4).Float64()
+
+		transactions = append(transactions, Transaction{
+			Hash:            hash,
+			From:            "0x" + strings.ToLower(log.Topics[1][len(log.Topics[1])-40:]),
+			To:              logTo,
+			Amount:          amount,
+			BlockTimestamp:  timestamp,
+			Confirmations:   confirmations,
+			Status:          "SUCCESS",
+			ContractAddress: contract.Address,
+		})
+	}
+
+	// 原生币转账金额在交易本身的 value 中
+	result, err = evmRpcCall(rpcUrl, "eth_getTransactionByHash", []interface{}{hash})
+	if err != nil {
+		return nil, fmt.Errorf("获取交易信息失败: %w", err)
+	}
+	var tx *struct {
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Value string `json:"value"`
+	}
+	if len(result) > 0 && string(result) != "null" {
+		if err := json.Cjson.Unmarshal(result, &tx); err != nil {
+			return nil, fmt.Errorf("解析交易信息失败: %w", err)
+		}
+	}
+	if tx != nil && strings.ToLower(tx.To) == target {
+		valueBigInt := new(big.Int)
+		if _, ok := valueBigInt.SetString(strings.TrimPrefix(tx.Value, "0x"), 16); ok && valueBigInt.Sign() > 0 {
+			amount, _ := decimal.NewFromBigInt(valueBigInt, 0).Div(decimal.New(1, EvmNativeDecimals)).Round(NativeAmountDecimals).Float64()
+			transactions = append(transactions, Transaction{
+				Hash:           hash,
+				From:           strings.ToLower(tx.From),
+				To:             target,
+				Amount:         amount,
+				BlockTimestamp: timestamp,
+				Confirmations:  confirmations,
+				Status:         "SUCCESS",
+			})
+		}
+	}
+
+	return transactions, nil
+}
@@ -1,6 +1,37 @@
 package blockchain
 
-import "sync"
+import (
+	"errors"
+	"sync"
+)
+
+// ErrTransactionFailed 交易已上链但执行失败
+var ErrTransactionFailed = errors.New("交易执行失败")
+
+// ErrMaxPagesReached 查询达到 blockchain_max_pages 翻页上限，仍有更早的交易未获取
+// 与已获取的交易一起返回，调用方应处理这些交易但不推进扫描游标，避免遗漏更早的交易
+var ErrMaxPagesReached = errors.New("达到翻页上限，仍有更早的交易未获取")
+
+// JoinTransactions 依次执行查询并合并交易，任一查询失败时返回该错误
+// 查询达到翻页上限时继续执行其余查询，合并全部已获取的交易并返回 ErrMaxPagesReached
+func JoinTransactions(queries ...func() ([]Transaction, error)) ([]Transaction, error) {
+	transactions := make([]Transaction, 0)
+	var maxPagesErr error
+	for _, query := range queries {
+		txs, err := query()
+		if err != nil {
+			if !errors.Is(err, ErrMaxPagesReached) {
+				return nil, err
+			}
+			maxPagesErr = err
+		}
+		transactions = append(transactions, txs...)
+	}
+	return transactions, maxPagesErr
+}
+
+// NativeAmountDecimals 原生币转账金额保留的小数位数，与订单金额字段的8位一致；稳定币保留4位
+const NativeAmountDecimals = 8
 
 // Transaction 通用交易结构
 type Transaction struct {
@@ -11,7 +42,7 @@ type Transaction struct {
 	BlockTimestamp  int64   // 区块时间戳，毫秒
 	Confirmations   int     // 确认数
 	Status          string  // 交易状态
-	ContractAddress string  // 合约地址，代币
+	ContractAddress string  // 合约地址，代币；原生币转账为空
 }
 
 // TokenBalance 代币余额
@@ -25,7 +56,7 @@ type ChainService interface {
 	// GetChainType 获取链类型
 	GetChainType() string
 
-	// GetTransactions 获取地址的交易记录
+	// GetTransactions 获取地址的交易记录，达到翻页上限时同时返回已获取的交易和 ErrMaxPagesReached
 	GetTransactions(address string, startTime int64, endTime int64) ([]Transaction, error)
 
 	// GetUSDTContractAddress 获取USDT合约地址
@@ -36,6 +67,38 @@ type ChainService interface {
 
 	// GetTokenBalance 获取地址的代币余额（USDT + USDC）
 	GetTokenBalance(address string) (*TokenBalance, error)
+
+	// GetNativeSymbol 获取链原生币符号，如 TRX、ETH
+	GetNativeSymbol() string
+
+	// GetNativeTransactions 获取地址的原生币转入记录，达到翻页上限时同时返回已获取的交易和 ErrMaxPagesReached
+	GetNativeTransactions(address string, startTime int64, endTime int64) ([]Transaction, error)
+
+	// GetTransfersByHash 查询交易中转入 to 地址的稳定币和原生币转账，用于核验向外转出的交易（如退款）
+	// 交易不存在或尚未打包时返回空列表，交易执行失败时返回 ErrTransactionFailed
+	GetTransfersByHash(hash string, to string) ([]Transaction, error)
+}
+
+// CursorScanner 支持游标增量扫描的链服务（可选接口）
+// 游标由各链自行定义，如 BEP20 为已扫描的区块号、Solana 为已扫描的 slot
+type CursorScanner interface {
+	// GetTransactionsByCursor 从游标之后查询地址的交易记录，返回新的游标
+	// cursor 为空表示首次扫描，此时按 startTime 确定补扫窗口
+	GetTransactionsByCursor(address string, cursor string, startTime int64, endTime int64) ([]Transaction, string, error)
+}
+
+// BlockScanner 支持按区块扫描代币 Transfer 事件的链服务（可选接口，EVM 链）
+type BlockScanner interface {
+	// ScanTransfers 扫描游标之后新区块中 USDT/USDC 合约的全部转账，只返回 match 命中的接收地址
+	// cursor 为已扫描的区块号，为空表示首次扫描，此时从最新区块附近开始；游标落后时按 startTime 确定补扫窗口；返回新的游标
+	ScanTransfers(cursor string, startTime int64, endTime int64, match func(to string) bool) ([]Transaction, string, error)
+}
+
+// PaymentURIProvider 支持生成钱包支付链接的链服务（可选接口）
+type PaymentURIProvider interface {
+	// GetPaymentURI 生成链标准的支付链接，asset 为 USDT(稳定币)或链原生币符号，amount 为应付金额
+	// 稳定币订单 USDT/USDC 均可支付，链接统一使用 USDT 合约
+	GetPaymentURI(address string, asset string, amount float64) string
 }
 
 // Factory 链服务工厂
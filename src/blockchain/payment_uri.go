@@ -0,0 +1,48 @@
+package blockchain
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/shopspring/decimal"
+)
+
+// EvmNativeDecimals EVM 链原生币（ETH、BNB、POL）精度
+const EvmNativeDecimals = 18
+
+// BuildEIP681TokenURI 生成 EIP-681 代币转账链接：ethereum:<合约>@<chainId>/transfer?address=<收款地址>&uint256=<最小单位金额>
+func BuildEIP681TokenURI(chainId string, contract string, decimals int32, to string, amount float64) string {
+	return fmt.Sprintf("ethereum:%s@%s/transfer?address=%s&uint256=%s",
+		contract, chainId, to, toBaseUnits(amount, decimals))
+}
+
+// BuildEIP681NativeURI 生成 EIP-681 原生币转账链接：ethereum:<收款地址>@<chainId>?value=<wei>
+func BuildEIP681NativeURI(chainId string, to string, amount float64) string {
+	return fmt.Sprintf("ethereum:%s@%s?value=%s", to, chainId, toBaseUnits(amount, EvmNativeDecimals))
+}
+
+// BuildSolanaPayURI 生成 Solana Pay 转账链接，mint 为空表示转账 SOL
+func BuildSolanaPayURI(to string, mint string, amount float64) string {
+	query := url.Values{}
+	query.Set("amount", decimal.NewFromFloat(amount).String())
+	if mint != "" {
+		query.Set("spl-token", mint)
+	}
+	return fmt.Sprintf("solana:%s?%s", to, query.Encode())
+}
+
+// BuildTronURI 生成 TRON 转账链接：tron:<收款地址>?amount=<金额>&token=<合约>，contract 为空表示转账 TRX
+// TRON 没有统一的链接标准，该格式被 TronLink 等主流钱包识别，其他钱包可能只识别地址
+func BuildTronURI(to string, contract string, amount float64) string {
+	query := url.Values{}
+	query.Set("amount", decimal.NewFromFloat(amount).String())
+	if contract != "" {
+		query.Set("token", contract)
+	}
+	return fmt.Sprintf("tron:%s?%s", to, query.Encode())
+}
+
+// toBaseUnits 按精度转换为链上最小单位的整数金额
+func toBaseUnits(amount float64, decimals int32) string {
+	return decimal.NewFromFloat(amount).Shift(decimals).Truncate(0).String()
+}
@@ -22,6 +22,8 @@ const (
 	PolygonChainID             = "137"                                        // Polygon Mainnet
 	USDTContractAddressPolygon = "0xc2132D05D31c914a87C6611C10748AEb04B58e8F" // USDT on Polygon
 	USDCContractAddressPolygon = "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174" // USDC on Polygon
+	EtherscanPageSize          = 100                                          // Etherscan 单页条数
+	PolygonBlockTimeMs         = 2000                                         // Polygon 平均出块时间（毫秒）
 )
 
 type PolygonService struct {
@@ -62,6 +64,23 @@ type PolygonTransfer struct {
 	Confirmations     string `json:"confirmations"`
 }
 
+type PolygonScanNativeResponse struct {
+	Status  string            `json:"status"`
+	Message string            `json:"message"`
+	Result  []PolygonNativeTx `json:"result"`
+}
+
+type PolygonNativeTx struct {
+	BlockNumber   string `json:"blockNumber"`
+	TimeStamp     string `json:"timeStamp"`
+	Hash          string `json:"hash"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Value         string `json:"value"`
+	IsError       string `json:"isError"`
+	Confirmations string `json:"confirmations"`
+}
+
 func NewPolygonService() *PolygonService {
 	polygonServiceOnce.Do(func() {
 		polygonServiceInstance = &PolygonService{
@@ -80,6 +99,18 @@ func (s *PolygonService) GetUSDTContractAddress() string {
 	return USDTContractAddressPolygon
 }
 
+func (s *PolygonService) GetNativeSymbol() string {
+	return mdb.AssetPOL
+}
+
+// GetPaymentURI 生成 EIP-681 支付链接
+func (s *PolygonService) GetPaymentURI(address string, asset string, amount float64) string {
+	if asset == mdb.AssetUSDT {
+		return blockchain.BuildEIP681TokenURI(PolygonChainID, USDTContractAddressPolygon, 6, address, amount)
+	}
+	return blockchain.BuildEIP681NativeURI(PolygonChainID, address, amount)
+}
+
 func (s *PolygonService) ValidateAddress(address string) bool {
 	// Polygon地址格式与ERC20相同，以0x开头，42个字符
 	match, _ := regexp.MatchString(`^0x[a-fA-F0-9]{40}$`, address)
@@ -88,21 +119,34 @@ func (s *PolygonService) ValidateAddress(address string) bool {
 
 func (s *PolygonService) GetTransactions(address string, startTime int64, endTime int64) ([]blockchain.Transaction, error) {
 	// 同时查询 USDT 和 USDC 交易
-	usdtTxs, err := s.getTransactionsByContract(address, startTime, endTime, USDTContractAddressPolygon)
-	if err != nil {
-		// USDT 查询失败，记录错误但继续查询 USDC
-		usdtTxs = []blockchain.Transaction{}
-	}
+	// 任一代币查询失败都返回错误，避免调用方推进游标后遗漏该时间段的交易
+	return blockchain.JoinTransactions(
+		func() ([]blockchain.Transaction, error) {
+			return s.getTransactionsByContract(address, startTime, endTime, USDTContractAddressPolygon)
+		},
+		func() ([]blockchain.Transaction, error) {
+			return s.getTransactionsByContract(address, startTime, endTime, USDCContractAddressPolygon)
+		},
+	)
+}
 
-	usdcTxs, err := s.getTransactionsByContract(address, startTime, endTime, USDCContractAddressPolygon)
-	if err != nil {
-		// USDC 查询失败，记录错误但继续
-		usdcTxs = []blockchain.Transaction{}
-	}
+// ScanTransfers 区块扫描模式：扫描新区块中 USDT/USDC 合约的全部转账
+func (s *PolygonService) ScanTransfers(cursor string, startTime int64, endTime int64, match func(to string) bool) ([]blockchain.Transaction, string, error) {
+	return blockchain.ScanEvmTransfers(config.GetPolygonRpcUrl(), s.getTokenContracts(), PolygonBlockTimeMs, cursor, startTime, endTime, match)
+}
 
-	// 合并交易列表
-	allTxs := append(usdtTxs, usdcTxs...)
-	return allTxs, nil
+// GetTransfersByHash 通过 RPC 查询交易中转入 to 地址的 USDT/USDC 和原生币转账
+func (s *PolygonService) GetTransfersByHash(hash string, to string) ([]blockchain.Transaction, error) {
+	return blockchain.GetEvmTransfersByHash(config.GetPolygonRpcUrl(), s.getTokenContracts(), hash, to)
+}
+
+// getTokenContracts 获取 USDT/USDC 合约配置
+func (s *PolygonService) getTokenContracts() []blockchain.EvmTokenContract {
+	// Polygon USDT/USDC 都是 6 位小数
+	return []blockchain.EvmTokenContract{
+		{Address: USDTContractAddressPolygon, Decimals: 6},
+		{Address: USDCContractAddressPolygon, Decimals: 6},
+	}
 }
 
 // getTransactionsByContract 查询指定合约地址的交易
@@ -112,111 +156,237 @@ func (s *PolygonService) getTransactionsByContract(address string, startTime int
 		return nil, fmt.Errorf("未配置 Etherscan API 密钥")
 	}
 
-	// 速率限制，等待令牌
-	s.mu.Lock()
-	<-s.rateLimiter.C
-	s.mu.Unlock()
+	transactions := make([]blockchain.Transaction, 0)
+	maxPages := config.GetBlockchainMaxPages()
+	for page := 1; page <= maxPages; page++ {
+		// 速率限制，等待令牌
+		s.mu.Lock()
+		<-s.rateLimiter.C
+		s.mu.Unlock()
+
+		client := http_client.GetHttpClient()
+
+		// 转换时间戳（毫秒转秒）
+		startBlock := "0"
+		endBlock := "99999999"
+
+		resp, err := client.R().SetQueryParams(map[string]string{
+			"chainid":         PolygonChainID,
+			"module":          "account",
+			"action":          "tokentx",
+			"contractaddress": contractAddress,
+			"address":         address,
+			"page":            strconv.Itoa(page),
+			"offset":          strconv.Itoa(EtherscanPageSize),
+			"startblock":      startBlock,
+			"endblock":        endBlock,
+			"sort":            "desc",
+			"apikey":          apiKey,
+		}).Get(EtherscanApiV2Uri)
 
-	client := http_client.GetHttpClient()
+		if err != nil {
+			return nil, fmt.Errorf("Etherscan API V2 请求失败: %w", err)
+		}
 
-	// 转换时间戳（毫秒转秒）
-	startBlock := "0"
-	endBlock := "99999999"
+		if resp.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("Etherscan API V2 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
+		}
 
-	resp, err := client.R().SetQueryParams(map[string]string{
-		"chainid":         PolygonChainID,
-		"module":          "account",
-		"action":          "tokentx",
-		"contractaddress": contractAddress,
-		"address":         address,
-		"page":            "1",
-		"offset":          "100",
-		"startblock":      startBlock,
-		"endblock":        endBlock,
-		"sort":            "desc",
-		"apikey":          apiKey,
-	}).Get(EtherscanApiV2Uri)
+		var polygonScanResp PolygonScanResponse
+		err = json.Cjson.Unmarshal(resp.Body(), &polygonScanResp)
+		if err != nil {
+			return nil, fmt.Errorf("解析 Etherscan API V2 响应失败: %w, 响应内容: %s", err, string(resp.Body()))
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("Etherscan API V2 请求失败: %w", err)
-	}
+		// 没有交易时停止翻页；速率限制、API 密钥错误等返回错误，避免游标越过未获取的交易
+		if polygonScanResp.Status != "1" {
+			if polygonScanResp.Message == blockchain.EtherscanNoTransactionsMessage {
+				return transactions, nil
+			}
+			return nil, fmt.Errorf("Etherscan API 返回错误: %s, 响应: %s", polygonScanResp.Message, string(resp.Body()))
+		}
 
-	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("Etherscan API V2 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
-	}
+		for _, transfer := range polygonScanResp.Result {
+			// 只处理接收到的交易，0x开头的地址需要忽略大小写比对
+			if !strings.EqualFold(transfer.To, address) {
+				continue
+			}
+
+			// 解析时间戳
+			timestamp, err := strconv.ParseInt(transfer.TimeStamp, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			// 转换为毫秒
+			timestampMs := timestamp * 1000
+			// 检查时间范围
+			if timestampMs < startTime || timestampMs > endTime {
+				continue
+			}
+
+			// 转换金额，Polygon USDT/USDC是6位小数
+			decimalQuant, err := decimal.NewFromString(transfer.Value)
+			if err != nil {
+				continue
+			}
+
+			// 获取小数位
+			tokenDecimal, err := strconv.Atoi(transfer.TokenDecimal)
+			if err != nil {
+				tokenDecimal = 6 // 默认为6位
+			}
+
+			divisor := decimal.NewFromFloat(1)
+			for i := 0; i < tokenDecimal; i++ {
+				divisor = divisor.Mul(decimal.NewFromInt(10))
+			}
+
+			// 金额统一保留4位小数，避免精度不匹配问题，比如12.31和12.3100
+			amount, _ := decimalQuant.Div(divisor).Round(4).Float64()
+
+			// 解析确认数
+			confirmations, _ := strconv.Atoi(transfer.Confirmations)
+
+			tx := blockchain.Transaction{
+				Hash:            transfer.Hash,
+				From:            transfer.From,
+				To:              transfer.To,
+				Amount:          amount,
+				BlockTimestamp:  timestampMs,
+				Confirmations:   confirmations,
+				Status:          "SUCCESS",
+				ContractAddress: contractAddress, // 使用实际的合约地址
+			}
+			transactions = append(transactions, tx)
+		}
 
-	var polygonScanResp PolygonScanResponse
-	err = json.Cjson.Unmarshal(resp.Body(), &polygonScanResp)
-	if err != nil {
-		return nil, fmt.Errorf("解析 Etherscan API V2 响应失败: %w, 响应内容: %s", err, string(resp.Body()))
+		// 结果按时间倒序，不足一页或已早于开始时间则停止翻页
+		if len(polygonScanResp.Result) < EtherscanPageSize {
+			return transactions, nil
+		}
+		lastTimestamp, _ := strconv.ParseInt(polygonScanResp.Result[len(polygonScanResp.Result)-1].TimeStamp, 10, 64)
+		if lastTimestamp*1000 < startTime {
+			return transactions, nil
+		}
 	}
 
-	// 如果API返回错误，返回空数组而不是错误，降级处理
-	if polygonScanResp.Status != "1" {
-		// 如果是速率限制错误，稍微延迟一下
-		if polygonScanResp.Message == "NOTOK" {
-			time.Sleep(time.Second * 2)
-		}
-		return []blockchain.Transaction{}, nil
+	// 达到翻页上限时仍有更早的交易未获取，返回已获取的交易，调用方不推进游标
+	return transactions, blockchain.ErrMaxPagesReached
+}
+
+// GetNativeTransactions 获取地址的POL转入记录（普通交易 + 内部交易）
+func (s *PolygonService) GetNativeTransactions(address string, startTime int64, endTime int64) ([]blockchain.Transaction, error) {
+	// 内部交易（合约转出的POL）查询失败时整体返回错误，避免游标越过未获取的交易
+	return blockchain.JoinTransactions(
+		func() ([]blockchain.Transaction, error) {
+			return s.getNativeTransactionsByAction(address, startTime, endTime, "txlist")
+		},
+		func() ([]blockchain.Transaction, error) {
+			return s.getNativeTransactionsByAction(address, startTime, endTime, "txlistinternal")
+		},
+	)
+}
+
+// getNativeTransactionsByAction 按 action 查询原生币交易，txlist 为普通交易，txlistinternal 为内部交易
+func (s *PolygonService) getNativeTransactionsByAction(address string, startTime int64, endTime int64, action string) ([]blockchain.Transaction, error) {
+	apiKey := config.GetEtherscanApiKey()
+	if apiKey == "" {
+		return nil, fmt.Errorf("未配置 Etherscan API 密钥")
 	}
 
 	transactions := make([]blockchain.Transaction, 0)
-	for _, transfer := range polygonScanResp.Result {
-		// 只处理接收到的交易，0x开头的地址需要忽略大小写比对
-		if !strings.EqualFold(transfer.To, address) {
-			continue
-		}
+	maxPages := config.GetBlockchainMaxPages()
+	for page := 1; page <= maxPages; page++ {
+		// 速率限制，等待令牌
+		s.mu.Lock()
+		<-s.rateLimiter.C
+		s.mu.Unlock()
+
+		client := http_client.GetHttpClient()
+
+		resp, err := client.R().SetQueryParams(map[string]string{
+			"chainid":    PolygonChainID,
+			"module":     "account",
+			"action":     action,
+			"address":    address,
+			"page":       strconv.Itoa(page),
+			"offset":     strconv.Itoa(EtherscanPageSize),
+			"startblock": "0",
+			"endblock":   "99999999",
+			"sort":       "desc",
+			"apikey":     apiKey,
+		}).Get(EtherscanApiV2Uri)
 
-		// 解析时间戳
-		timestamp, err := strconv.ParseInt(transfer.TimeStamp, 10, 64)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("Etherscan API 请求失败: %w", err)
 		}
 
-		// 转换为毫秒
-		timestampMs := timestamp * 1000
-		// 检查时间范围
-		if timestampMs < startTime || timestampMs > endTime {
-			continue
+		if resp.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("Etherscan API 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
 		}
 
-		// 转换金额，Polygon USDT/USDC是6位小数
-		decimalQuant, err := decimal.NewFromString(transfer.Value)
+		var nativeResp PolygonScanNativeResponse
+		err = json.Cjson.Unmarshal(resp.Body(), &nativeResp)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("解析 Etherscan API 响应失败: %w, 响应内容: %s", err, string(resp.Body()))
 		}
 
-		// 获取小数位
-		tokenDecimal, err := strconv.Atoi(transfer.TokenDecimal)
-		if err != nil {
-			tokenDecimal = 6 // 默认为6位
+		// 没有交易时停止翻页；速率限制、API 密钥错误等返回错误，避免游标越过未获取的交易
+		if nativeResp.Status != "1" {
+			if nativeResp.Message == blockchain.EtherscanNoTransactionsMessage {
+				return transactions, nil
+			}
+			return nil, fmt.Errorf("Etherscan API 返回错误: %s, 响应: %s", nativeResp.Message, string(resp.Body()))
 		}
 
-		divisor := decimal.NewFromFloat(1)
-		for i := 0; i < tokenDecimal; i++ {
-			divisor = divisor.Mul(decimal.NewFromInt(10))
+		for _, transfer := range nativeResp.Result {
+			// 只处理成功的转入交易
+			if !strings.EqualFold(transfer.To, address) || transfer.IsError != "0" {
+				continue
+			}
+
+			timestamp, err := strconv.ParseInt(transfer.TimeStamp, 10, 64)
+			if err != nil {
+				continue
+			}
+			timestampMs := timestamp * 1000
+			if timestampMs < startTime || timestampMs > endTime {
+				continue
+			}
+
+			// POL 是18位小数
+			decimalQuant, err := decimal.NewFromString(transfer.Value)
+			if err != nil || decimalQuant.IsZero() {
+				continue
+			}
+			amount, _ := decimalQuant.Div(decimal.New(1, 18)).Round(blockchain.NativeAmountDecimals).Float64()
+
+			confirmations, _ := strconv.Atoi(transfer.Confirmations)
+
+			transactions = append(transactions, blockchain.Transaction{
+				Hash:           transfer.Hash,
+				From:           transfer.From,
+				To:             transfer.To,
+				Amount:         amount,
+				BlockTimestamp: timestampMs,
+				Confirmations:  confirmations,
+				Status:         "SUCCESS",
+			})
 		}
 
-		// 金额统一保留4位小数，避免精度不匹配问题，比如12.31和12.3100
-		amount, _ := decimalQuant.Div(divisor).Round(4).Float64()
-
-		// 解析确认数
-		confirmations, _ := strconv.Atoi(transfer.Confirmations)
-
-		tx := blockchain.Transaction{
-			Hash:            transfer.Hash,
-			From:            transfer.From,
-			To:              transfer.To,
-			Amount:          amount,
-			BlockTimestamp:  timestampMs,
-			Confirmations:   confirmations,
-			Status:          "SUCCESS",
-			ContractAddress: contractAddress, // 使用实际的合约地址
+		// 结果按时间倒序，不足一页或已早于开始时间则停止翻页
+		if len(nativeResp.Result) < EtherscanPageSize {
+			return transactions, nil
+		}
+		lastTimestamp, _ := strconv.ParseInt(nativeResp.Result[len(nativeResp.Result)-1].TimeStamp, 10, 64)
+		if lastTimestamp*1000 < startTime {
+			return transactions, nil
 		}
-		transactions = append(transactions, tx)
 	}
 
-	return transactions, nil
+	// 达到翻页上限时仍有更早的交易未获取，返回已获取的交易，调用方不推进游标
+	return transactions, blockchain.ErrMaxPagesReached
 }
 
 // GetTokenBalance 获取地址的代币余额（USDT + USDC）
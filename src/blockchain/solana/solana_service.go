@@ -2,8 +2,10 @@ package solana
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 
 	"github.com/assimon/luuu/blockchain"
 	"github.com/assimon/luuu/config"
@@ -11,6 +13,8 @@ import (
 	"github.com/assimon/luuu/util/log"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/shopspring/decimal"
 )
 
 const (
@@ -18,8 +22,19 @@ const (
 	USDTMintAddressSolana = "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB"
 	// USDC on Solana (SPL Token)
 	USDCMintAddressSolana = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	// getSignaturesForAddress 单页条数
+	SignaturePageSize = 100
 )
 
+// 节点无法返回交易详情的 RPC 错误码，重试也不会成功：
+// -32007/-32009 slot 已跳过或不在长期存储中，-32011 交易历史不可用，-32015 不支持的交易版本
+var unavailableTransactionErrorCodes = map[int]bool{
+	-32007: true,
+	-32009: true,
+	-32011: true,
+	-32015: true,
+}
+
 type SolanaService struct {
 	rpcClient *rpc.Client
 }
@@ -43,6 +58,18 @@ func (s *SolanaService) GetUSDTContractAddress() string {
 	return USDTMintAddressSolana
 }
 
+func (s *SolanaService) GetNativeSymbol() string {
+	return mdb.AssetSOL
+}
+
+// GetPaymentURI 生成 Solana Pay 支付链接
+func (s *SolanaService) GetPaymentURI(address string, asset string, amount float64) string {
+	if asset == mdb.AssetUSDT {
+		return blockchain.BuildSolanaPayURI(address, USDTMintAddressSolana, amount)
+	}
+	return blockchain.BuildSolanaPayURI(address, "", amount)
+}
+
 func (s *SolanaService) ValidateAddress(address string) bool {
 	// Solana地址是Base58编码，通常32-44个字符
 	match, _ := regexp.MatchString(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`, address)
@@ -51,31 +78,63 @@ func (s *SolanaService) ValidateAddress(address string) bool {
 
 func (s *SolanaService) GetTransactions(address string, startTime int64, endTime int64) ([]blockchain.Transaction, error) {
 	// 同时查询 USDT 和 USDC 交易
-	usdtTxs, err := s.getTransactionsByMint(address, startTime, endTime, USDTMintAddressSolana)
-	if err != nil {
-		// USDT 查询失败，记录错误但继续查询 USDC
-		usdtTxs = []blockchain.Transaction{}
+	// 任一代币查询失败都返回错误，避免调用方推进游标后遗漏该时间段的交易
+	return blockchain.JoinTransactions(
+		func() ([]blockchain.Transaction, error) {
+			return s.getTransactionsByMint(address, startTime, endTime, USDTMintAddressSolana)
+		},
+		func() ([]blockchain.Transaction, error) {
+			return s.getTransactionsByMint(address, startTime, endTime, USDCMintAddressSolana)
+		},
+	)
+}
+
+// GetTransactionsByCursor 从已扫描的 slot 之后增量查询交易，游标为 slot
+func (s *SolanaService) GetTransactionsByCursor(address string, cursor string, startTime int64, endTime int64) ([]blockchain.Transaction, string, error) {
+	minSlot, _ := strconv.ParseUint(cursor, 10, 64)
+
+	transactions := make([]blockchain.Transaction, 0)
+	maxSlot := minSlot
+	maxPagesReached := false
+	for _, mintAddress := range []string{USDTMintAddressSolana, USDCMintAddressSolana} {
+		txs, lastSlot, err := s.getTransactionsByMintSince(address, startTime, endTime, mintAddress, minSlot)
+		if err != nil {
+			// 达到翻页上限时处理已获取的交易但不推进游标，其他错误下个周期重试
+			if !errors.Is(err, blockchain.ErrMaxPagesReached) {
+				return nil, cursor, err
+			}
+			maxPagesReached = true
+		}
+		transactions = append(transactions, txs...)
+		if lastSlot > maxSlot {
+			maxSlot = lastSlot
+		}
 	}
 
-	usdcTxs, err := s.getTransactionsByMint(address, startTime, endTime, USDCMintAddressSolana)
-	if err != nil {
-		// USDC 查询失败，记录错误但继续
-		usdcTxs = []blockchain.Transaction{}
+	if maxPagesReached {
+		return transactions, cursor, blockchain.ErrMaxPagesReached
 	}
 
-	// 合并交易列表
-	allTxs := append(usdtTxs, usdcTxs...)
-	return allTxs, nil
+	if maxSlot == 0 {
+		return transactions, cursor, nil
+	}
+	return transactions, strconv.FormatUint(maxSlot, 10), nil
 }
 
 // getTransactionsByMint 查询指定 mint 地址的交易
 func (s *SolanaService) getTransactionsByMint(address string, startTime int64, endTime int64, mintAddress string) ([]blockchain.Transaction, error) {
+	transactions, _, err := s.getTransactionsByMintSince(address, startTime, endTime, mintAddress, 0)
+	return transactions, err
+}
+
+// getTransactionsByMintSince 查询指定 mint 地址在 minSlot 之后的交易，同时返回扫描到的最大 slot
+func (s *SolanaService) getTransactionsByMintSince(address string, startTime int64, endTime int64, mintAddress string, minSlot uint64) ([]blockchain.Transaction, uint64, error) {
 	ctx := context.Background()
 
 	// 解析地址
 	pubKey, err := solana.PublicKeyFromBase58(address)
 	if err != nil {
-		return nil, fmt.Errorf("无效的 Solana 地址: %w", err)
+		return nil, 0, fmt.Errorf("无效的 Solana 地址: %w", err)
 	}
 
 	mint := solana.MustPublicKeyFromBase58(mintAddress)
@@ -86,20 +145,26 @@ func (s *SolanaService) getTransactionsByMint(address string, startTime int64, e
 		mint,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("获取关联Token地址失败: %w", err)
+		return nil, 0, fmt.Errorf("获取关联Token地址失败: %w", err)
 	}
 	log.Sugar.Debugf("[SOLANA] %s 的关联Token地址: %s (Mint: %s)", address, ata, mintAddress)
 
-	// 获取签名列表，最近的交易
-	sigs, err := s.rpcClient.GetSignaturesForAddress(ctx, ata)
-	if err != nil {
-		return nil, fmt.Errorf("获取签名失败: %w", err)
+	// 获取签名列表，从最近的交易向前翻页直到开始时间或已扫描的 slot
+	sigs, pagesErr := s.getSignaturesSince(ctx, ata, startTime, minSlot)
+	if pagesErr != nil && !errors.Is(pagesErr, blockchain.ErrMaxPagesReached) {
+		return nil, 0, fmt.Errorf("获取签名失败: %w", pagesErr)
 	}
 
+	maxVersion := uint64(0)
 	transactions := make([]blockchain.Transaction, 0)
+	maxSlot := uint64(0)
 
 	// 遍历签名
 	for _, sig := range sigs {
+		if sig.Slot > maxSlot {
+			maxSlot = sig.Slot
+		}
+
 		// 检查时间范围
 		if sig.BlockTime == nil {
 			continue
@@ -115,13 +180,19 @@ func (s *SolanaService) getTransactionsByMint(address string, startTime int64, e
 			continue // 跳过失败的交易
 		}
 
-		// 获取交易详情
+		// 获取交易详情，支持 v0 版本交易
 		tx, err := s.rpcClient.GetTransaction(ctx, sig.Signature, &rpc.GetTransactionOpts{
 			Encoding:                       solana.EncodingBase64,
-			MaxSupportedTransactionVersion: nil, // 使用默认版本
+			MaxSupportedTransactionVersion: &maxVersion,
 		})
 		if err != nil {
-			continue
+			// 节点永久无法返回的交易跳过，避免单笔交易导致地址一直无法扫描
+			if isTransactionUnavailable(err) {
+				log.Sugar.Warnf("[SOLANA] 交易 %s 详情不可用，跳过: %v", sig.Signature, err)
+				continue
+			}
+			// 其他错误（网络、限流等）整体返回错误，避免游标越过未处理的交易
+			return nil, 0, fmt.Errorf("获取交易详情失败: %w", err)
 		}
 
 		if tx == nil || tx.Meta == nil {
@@ -135,7 +206,57 @@ func (s *SolanaService) getTransactionsByMint(address string, startTime int64, e
 		}
 	}
 
-	return transactions, nil
+	return transactions, maxSlot, pagesErr
+}
+
+// isTransactionUnavailable 判断获取交易详情的错误是否为节点永久无法返回该交易
+func isTransactionUnavailable(err error) bool {
+	if errors.Is(err, rpc.ErrNotFound) {
+		return true
+	}
+	var rpcErr *jsonrpc.RPCError
+	return errors.As(err, &rpcErr) && unavailableTransactionErrorCodes[rpcErr.Code]
+}
+
+// getSignaturesSince 按 before 游标向前翻页获取签名，直到早于开始时间、不晚于 minSlot 或达到翻页上限
+// 达到翻页上限时同时返回已获取的签名和 ErrMaxPagesReached
+func (s *SolanaService) getSignaturesSince(ctx context.Context, account solana.PublicKey, startTime int64, minSlot uint64) ([]*rpc.TransactionSignature, error) {
+	limit := SignaturePageSize
+	maxPages := config.GetBlockchainMaxPages()
+
+	sigs := make([]*rpc.TransactionSignature, 0)
+	var before solana.Signature
+	for page := 0; page < maxPages; page++ {
+		pageSigs, err := s.rpcClient.GetSignaturesForAddressWithOpts(ctx, account, &rpc.GetSignaturesForAddressOpts{
+			Limit:  &limit,
+			Before: before,
+		})
+		if err != nil {
+			// 任一页失败都返回错误，避免调用方推进游标后遗漏更早的交易
+			return nil, err
+		}
+		reachedCursor := false
+		for _, sig := range pageSigs {
+			// 游标之前的签名已在上次扫描中处理
+			if minSlot > 0 && sig.Slot <= minSlot {
+				reachedCursor = true
+				break
+			}
+			sigs = append(sigs, sig)
+		}
+
+		if reachedCursor || len(pageSigs) < limit {
+			return sigs, nil
+		}
+		last := pageSigs[len(pageSigs)-1]
+		if last.BlockTime != nil && int64(*last.BlockTime)*1000 < startTime {
+			return sigs, nil
+		}
+		before = last.Signature
+	}
+
+	// 达到翻页上限时仍有更早的签名未获取，返回已获取的签名，调用方不推进游标
+	return sigs, blockchain.ErrMaxPagesReached
 }
 
 // parseTokenTransfer 解析SPL Token转账，简化版本
@@ -193,6 +314,208 @@ func (s *SolanaService) parseTokenTransfer(tx *rpc.GetTransactionResult, targetA
 	return nil
 }
 
+// GetNativeTransactions 获取地址的SOL转入记录（系统转账）
+func (s *SolanaService) GetNativeTransactions(address string, startTime int64, endTime int64) ([]blockchain.Transaction, error) {
+	ctx := context.Background()
+
+	pubKey, err := solana.PublicKeyFromBase58(address)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 Solana 地址: %w", err)
+	}
+
+	// SOL 直接转入钱包地址本身，无需 ATA
+	sigs, pagesErr := s.getSignaturesSince(ctx, pubKey, startTime, 0)
+	if pagesErr != nil && !errors.Is(pagesErr, blockchain.ErrMaxPagesReached) {
+		return nil, fmt.Errorf("获取签名失败: %w", pagesErr)
+	}
+
+	maxVersion := uint64(0)
+	transactions := make([]blockchain.Transaction, 0)
+	for _, sig := range sigs {
+		if sig.BlockTime == nil || sig.Err != nil {
+			continue
+		}
+
+		blockTimeMs := int64(*sig.BlockTime) * 1000
+		if blockTimeMs < startTime || blockTimeMs > endTime {
+			continue
+		}
+
+		tx, err := s.rpcClient.GetTransaction(ctx, sig.Signature, &rpc.GetTransactionOpts{
+			Encoding:                       solana.EncodingBase64,
+			MaxSupportedTransactionVersion: &maxVersion,
+		})
+		if err != nil || tx == nil || tx.Meta == nil || tx.Transaction == nil {
+			continue
+		}
+
+		transaction := s.parseNativeTransfer(tx, pubKey, sig.Signature.String(), blockTimeMs)
+		if transaction != nil {
+			transactions = append(transactions, *transaction)
+		}
+	}
+
+	return transactions, pagesErr
+}
+
+// parseNativeTransfer 通过 lamports 余额变化解析 SOL 转入
+func (s *SolanaService) parseNativeTransfer(tx *rpc.GetTransactionResult, targetAddr solana.PublicKey, txHash string, blockTime int64) *blockchain.Transaction {
+	parsedTx, err := tx.Transaction.GetTransaction()
+	if err != nil {
+		return nil
+	}
+
+	// 只需查找静态账户，系统转账的接收方不会出现在地址查找表中
+	for i, key := range parsedTx.Message.AccountKeys {
+		if !key.Equals(targetAddr) {
+			continue
+		}
+		if i >= len(tx.Meta.PreBalances) || i >= len(tx.Meta.PostBalances) {
+			return nil
+		}
+
+		pre := tx.Meta.PreBalances[i]
+		post := tx.Meta.PostBalances[i]
+		if post <= pre {
+			return nil
+		}
+
+		// SOL 是9位小数（lamports）
+		amount, _ := decimal.NewFromInt(int64(post - pre)).Div(decimal.New(1, 9)).Round(blockchain.NativeAmountDecimals).Float64()
+
+		from := ""
+		if len(parsedTx.Message.AccountKeys) > 0 {
+			// 第一个账户为手续费支付者，通常即为转出方
+			from = parsedTx.Message.AccountKeys[0].String()
+		}
+
+		return &blockchain.Transaction{
+			Hash:           txHash,
+			From:           from,
+			To:             targetAddr.String(),
+			Amount:         amount,
+			BlockTimestamp: blockTime,
+			Confirmations:  1,
+			Status:         "SUCCESS",
+		}
+	}
+
+	return nil
+}
+
+// GetTransfersByHash 按交易签名查询转入 to 地址的 USDT/USDC 和 SOL 转账，只查询已最终确认的交易
+func (s *SolanaService) GetTransfersByHash(hash string, to string) ([]blockchain.Transaction, error) {
+	ctx := context.Background()
+
+	signature, err := solana.SignatureFromBase58(hash)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 Solana 交易签名: %w", err)
+	}
+	pubKey, err := solana.PublicKeyFromBase58(to)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 Solana 地址: %w", err)
+	}
+
+	maxVersion := uint64(0)
+	tx, err := s.rpcClient.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
+		Encoding:                       solana.EncodingBase64,
+		Commitment:                     rpc.CommitmentFinalized,
+		MaxSupportedTransactionVersion: &maxVersion,
+	})
+	if err != nil {
+		if errors.Is(err, rpc.ErrNotFound) {
+			return []blockchain.Transaction{}, nil
+		}
+		return nil, fmt.Errorf("获取交易失败: %w", err)
+	}
+	if tx.Meta == nil || tx.Transaction == nil {
+		return []blockchain.Transaction{}, nil
+	}
+	if tx.Meta.Err != nil {
+		return nil, blockchain.ErrTransactionFailed
+	}
+
+	blockTimeMs := int64(0)
+	if tx.BlockTime != nil {
+		blockTimeMs = int64(*tx.BlockTime) * 1000
+	}
+
+	transactions := make([]blockchain.Transaction, 0)
+	for _, mintAddress := range []string{USDTMintAddressSolana, USDCMintAddressSolana} {
+		if transaction := s.parseOwnerTokenTransfer(tx, pubKey, hash, blockTimeMs, mintAddress); transaction != nil {
+			transactions = append(transactions, *transaction)
+		}
+	}
+	if transaction := s.parseNativeTransfer(tx, pubKey, hash, blockTimeMs); transaction != nil {
+		transactions = append(transactions, *transaction)
+	}
+	return transactions, nil
+}
+
+// parseOwnerTokenTransfer 按代币账户所有者解析SPL Token转入，转出方为同一 mint 余额减少的所有者
+func (s *SolanaService) parseOwnerTokenTransfer(tx *rpc.GetTransactionResult, owner solana.PublicKey, txHash string, blockTime int64, mintAddress string) *blockchain.Transaction {
+	// 按账户索引计算该 mint 的余额变化（最小单位）
+	changes := make(map[uint16]decimal.Decimal)
+	owners := make(map[uint16]*solana.PublicKey)
+	decimals := int32(0)
+	for _, balance := range tx.Meta.PostTokenBalances {
+		if balance.Mint.String() != mintAddress || balance.UiTokenAmount == nil {
+			continue
+		}
+		amount, err := decimal.NewFromString(balance.UiTokenAmount.Amount)
+		if err != nil {
+			continue
+		}
+		changes[balance.AccountIndex] = changes[balance.AccountIndex].Add(amount)
+		owners[balance.AccountIndex] = balance.Owner
+		decimals = int32(balance.UiTokenAmount.Decimals)
+	}
+	for _, balance := range tx.Meta.PreTokenBalances {
+		if balance.Mint.String() != mintAddress || balance.UiTokenAmount == nil {
+			continue
+		}
+		amount, err := decimal.NewFromString(balance.UiTokenAmount.Amount)
+		if err != nil {
+			continue
+		}
+		changes[balance.AccountIndex] = changes[balance.AccountIndex].Sub(amount)
+		if owners[balance.AccountIndex] == nil {
+			owners[balance.AccountIndex] = balance.Owner
+		}
+	}
+
+	received := decimal.Zero
+	from := ""
+	for index, change := range changes {
+		accountOwner := owners[index]
+		if accountOwner == nil {
+			continue
+		}
+		if accountOwner.Equals(owner) {
+			if change.IsPositive() {
+				received = received.Add(change)
+			}
+		} else if change.IsNegative() {
+			from = accountOwner.String()
+		}
+	}
+	if !received.IsPositive() {
+		return nil
+	}
+
+	amount, _ := received.Div(decimal.New(1, decimals)).Round(4).Float64()
+	return &blockchain.Transaction{
+		Hash:            txHash,
+		From:            from,
+		To:              owner.String(),
+		Amount:          amount,
+		BlockTimestamp:  blockTime,
+		Confirmations:   1,
+		Status:          "SUCCESS",
+		ContractAddress: mintAddress,
+	}
+}
+
 // GetTokenBalance 获取地址的代币余额（USDT + USDC）
 func (s *SolanaService) GetTokenBalance(address string) (*blockchain.TokenBalance, error) {
 	ctx := context.Background()
@@ -6,6 +6,7 @@ import (
 	"regexp"
 
 	"github.com/assimon/luuu/blockchain"
+	"github.com/assimon/luuu/config"
 	"github.com/assimon/luuu/model/mdb"
 	"github.com/assimon/luuu/util/http_client"
 	"github.com/assimon/luuu/util/json"
@@ -15,7 +16,10 @@ import (
 
 const (
 	TRC20ApiUri              = "https://apilist.tronscanapi.com/api/transfer/trc20"
+	TRXApiUri                = "https://apilist.tronscanapi.com/api/transfer/trx"
+	TransactionInfoApiUri    = "https://apilist.tronscanapi.com/api/transaction-info"
 	USDTContractAddressTRC20 = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	TronScanPageSize         = 50 // TronScan 单页条数
 )
 
 type TRC20Service struct{}
@@ -47,6 +51,43 @@ type TRC20Transfer struct {
 	Direction      int    `json:"direction"`
 }
 
+type TrxTransferResp struct {
+	Total int           `json:"total"`
+	Data  []TRXTransfer `json:"data"`
+}
+
+type TRXTransfer struct {
+	Amount         string `json:"amount"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+	Block          int    `json:"block"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Hash           string `json:"hash"`
+	Confirmed      bool   `json:"confirmed"`
+	ContractRet    string `json:"contract_ret"`
+}
+
+// TronScanTransactionInfo TronScan 交易详情，交易不存在时各字段为空
+type TronScanTransactionInfo struct {
+	Hash         string `json:"hash"`
+	Timestamp    int64  `json:"timestamp"`
+	Confirmed    bool   `json:"confirmed"`
+	ContractRet  string `json:"contractRet"`
+	ContractType int    `json:"contractType"` // 1 为 TRX 转账
+	ContractData struct {
+		Amount       decimal.Decimal `json:"amount"`
+		OwnerAddress string          `json:"owner_address"`
+		ToAddress    string          `json:"to_address"`
+	} `json:"contractData"`
+	Trc20TransferInfo []struct {
+		ContractAddress string `json:"contract_address"`
+		FromAddress     string `json:"from_address"`
+		ToAddress       string `json:"to_address"`
+		AmountStr       string `json:"amount_str"`
+		Decimals        int32  `json:"decimals"`
+	} `json:"trc20TransferInfo"`
+}
+
 func NewTRC20Service() *TRC20Service {
 	return &TRC20Service{}
 }
@@ -59,6 +100,18 @@ func (s *TRC20Service) GetUSDTContractAddress() string {
 	return USDTContractAddressTRC20
 }
 
+func (s *TRC20Service) GetNativeSymbol() string {
+	return mdb.AssetTRX
+}
+
+// GetPaymentURI 生成 TRON 支付链接
+func (s *TRC20Service) GetPaymentURI(address string, asset string, amount float64) string {
+	if asset == mdb.AssetUSDT {
+		return blockchain.BuildTronURI(address, USDTContractAddressTRC20, amount)
+	}
+	return blockchain.BuildTronURI(address, "", amount)
+}
+
 func (s *TRC20Service) ValidateAddress(address string) bool {
 	// TRC20地址以T开头，34个字符
 	match, _ := regexp.MatchString(`^T[a-zA-Z0-9]{33}$`, address)
@@ -66,65 +119,227 @@ func (s *TRC20Service) ValidateAddress(address string) bool {
 }
 
 func (s *TRC20Service) GetTransactions(address string, startTime int64, endTime int64) ([]blockchain.Transaction, error) {
+	if config.GetTrc20ApiProvider() == ProviderTronGrid {
+		return s.getTransactionsFromTronGrid(address, startTime, endTime)
+	}
+
 	client := http_client.GetHttpClient()
+	maxPages := config.GetBlockchainMaxPages()
+
+	transactions := make([]blockchain.Transaction, 0)
+	for page := 0; page < maxPages; page++ {
+		resp, err := client.R().SetQueryParams(map[string]string{
+			"sort":            "-timestamp",
+			"limit":           stdutil.ToString(TronScanPageSize),
+			"start":           stdutil.ToString(page * TronScanPageSize),
+			"direction":       "2", // 2表示接收
+			"db_version":      "1",
+			"trc20Id":         USDTContractAddressTRC20,
+			"address":         address,
+			"start_timestamp": stdutil.ToString(startTime),
+			"end_timestamp":   stdutil.ToString(endTime),
+		}).Get(TRC20ApiUri)
+
+		if err != nil {
+			return nil, fmt.Errorf("TRC20 API 请求失败: %w", err)
+		}
 
-	resp, err := client.R().SetQueryParams(map[string]string{
-		"sort":            "-timestamp",
-		"limit":           "50",
-		"start":           "0",
-		"direction":       "2", // 2表示接收
-		"db_version":      "1",
-		"trc20Id":         USDTContractAddressTRC20,
-		"address":         address,
-		"start_timestamp": stdutil.ToString(startTime),
-		"end_timestamp":   stdutil.ToString(endTime),
-	}).Get(TRC20ApiUri)
+		if resp.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("TRC20 API 返回状态码: %d", resp.StatusCode())
+		}
 
+		var trc20Resp UsdtTrc20Resp
+		err = json.Cjson.Unmarshal(resp.Body(), &trc20Resp)
+		if err != nil {
+			return nil, fmt.Errorf("解析 TRC20 响应失败: %w", err)
+		}
+
+		if trc20Resp.PageSize <= 0 {
+			return transactions, nil
+		}
+
+		for _, transfer := range trc20Resp.Data {
+			// 只处理成功的交易
+			if transfer.To != address || transfer.ContractRet != "SUCCESS" {
+				continue
+			}
+
+			// 转换金额，TRC20 USDT是6位小数
+			decimalQuant, err := decimal.NewFromString(transfer.Amount)
+			if err != nil {
+				continue
+			}
+			decimalDivisor := decimal.NewFromFloat(1000000)
+			// 金额统一保留4位小数，避免精度不匹配问题，比如12.31和12.3100
+			amount, _ := decimalQuant.Div(decimalDivisor).Round(4).Float64()
+
+			tx := blockchain.Transaction{
+				Hash:            transfer.Hash,
+				From:            transfer.From,
+				To:              transfer.To,
+				Amount:          amount,
+				BlockTimestamp:  transfer.BlockTimestamp,
+				Confirmations:   transfer.Confirmed,
+				Status:          transfer.ContractRet,
+				ContractAddress: USDTContractAddressTRC20,
+			}
+			transactions = append(transactions, tx)
+		}
+
+		// 按时间倒序返回，不足一页或已早于开始时间则没有更多数据
+		last := len(trc20Resp.Data) - 1
+		if len(trc20Resp.Data) < TronScanPageSize || trc20Resp.Data[last].BlockTimestamp < startTime {
+			return transactions, nil
+		}
+	}
+
+	// 达到翻页上限时仍有更早的交易未获取，返回已获取的交易，调用方不推进游标
+	return transactions, blockchain.ErrMaxPagesReached
+}
+
+// GetNativeTransactions 获取地址的TRX转入记录
+func (s *TRC20Service) GetNativeTransactions(address string, startTime int64, endTime int64) ([]blockchain.Transaction, error) {
+	client := http_client.GetHttpClient()
+	maxPages := config.GetBlockchainMaxPages()
+
+	transactions := make([]blockchain.Transaction, 0)
+	for page := 0; page < maxPages; page++ {
+		resp, err := client.R().SetQueryParams(map[string]string{
+			"address":         address,
+			"start":           stdutil.ToString(page * TronScanPageSize),
+			"limit":           stdutil.ToString(TronScanPageSize),
+			"direction":       "2", // 2表示接收
+			"reverse":         "true",
+			"db_version":      "1",
+			"start_timestamp": stdutil.ToString(startTime),
+			"end_timestamp":   stdutil.ToString(endTime),
+		}).Get(TRXApiUri)
+
+		if err != nil {
+			return nil, fmt.Errorf("TRX API 请求失败: %w", err)
+		}
+
+		if resp.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("TRX API 返回状态码: %d", resp.StatusCode())
+		}
+
+		var trxResp TrxTransferResp
+		err = json.Cjson.Unmarshal(resp.Body(), &trxResp)
+		if err != nil {
+			return nil, fmt.Errorf("解析 TRX 响应失败: %w", err)
+		}
+
+		for _, transfer := range trxResp.Data {
+			// 只处理成功的转入交易
+			if transfer.To != address || transfer.ContractRet != "SUCCESS" {
+				continue
+			}
+
+			// TRX 是6位小数（sun）
+			decimalQuant, err := decimal.NewFromString(transfer.Amount)
+			if err != nil {
+				continue
+			}
+			amount, _ := decimalQuant.Div(decimal.NewFromInt(1000000)).Round(blockchain.NativeAmountDecimals).Float64()
+
+			confirmations := 0
+			if transfer.Confirmed {
+				confirmations = 1
+			}
+
+			transactions = append(transactions, blockchain.Transaction{
+				Hash:           transfer.Hash,
+				From:           transfer.From,
+				To:             transfer.To,
+				Amount:         amount,
+				BlockTimestamp: transfer.BlockTimestamp,
+				Confirmations:  confirmations,
+				Status:         transfer.ContractRet,
+			})
+		}
+
+		// 不足一页或已早于开始时间则没有更多数据
+		last := len(trxResp.Data) - 1
+		if len(trxResp.Data) < TronScanPageSize || trxResp.Data[last].BlockTimestamp < startTime {
+			return transactions, nil
+		}
+	}
+
+	// 达到翻页上限时仍有更早的交易未获取，返回已获取的交易，调用方不推进游标
+	return transactions, blockchain.ErrMaxPagesReached
+}
+
+// GetTransfersByHash 查询交易中转入 to 地址的 USDT 和 TRX 转账
+func (s *TRC20Service) GetTransfersByHash(hash string, to string) ([]blockchain.Transaction, error) {
+	if config.GetTrc20ApiProvider() == ProviderTronGrid {
+		return s.getTransfersByHashFromTronGrid(hash, to)
+	}
+
+	resp, err := http_client.GetHttpClient().R().SetQueryParam("hash", hash).Get(TransactionInfoApiUri)
 	if err != nil {
-		return nil, fmt.Errorf("TRC20 API 请求失败: %w", err)
+		return nil, fmt.Errorf("TronScan API 请求失败: %w", err)
 	}
 
 	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("TRC20 API 返回状态码: %d", resp.StatusCode())
+		return nil, fmt.Errorf("TronScan API 返回状态码: %d", resp.StatusCode())
 	}
 
-	var trc20Resp UsdtTrc20Resp
-	err = json.Cjson.Unmarshal(resp.Body(), &trc20Resp)
+	var info TronScanTransactionInfo
+	err = json.Cjson.Unmarshal(resp.Body(), &info)
 	if err != nil {
-		return nil, fmt.Errorf("解析 TRC20 响应失败: %w", err)
+		return nil, fmt.Errorf("解析 TronScan 响应失败: %w", err)
+	}
+
+	transactions := make([]blockchain.Transaction, 0)
+	if info.Hash == "" {
+		return transactions, nil
+	}
+	if info.ContractRet != "" && info.ContractRet != "SUCCESS" {
+		return nil, blockchain.ErrTransactionFailed
 	}
 
-	if trc20Resp.PageSize <= 0 {
-		return []blockchain.Transaction{}, nil
+	confirmations := 0
+	if info.Confirmed {
+		confirmations = 1
 	}
 
-	transactions := make([]blockchain.Transaction, 0)
-	for _, transfer := range trc20Resp.Data {
-		// 只处理成功的交易
-		if transfer.To != address || transfer.ContractRet != "SUCCESS" {
+	for _, transfer := range info.Trc20TransferInfo {
+		if transfer.ContractAddress != USDTContractAddressTRC20 || transfer.ToAddress != to {
 			continue
 		}
-
-		// 转换金额，TRC20 USDT是6位小数
-		decimalQuant, err := decimal.NewFromString(transfer.Amount)
+		decimalQuant, err := decimal.NewFromString(transfer.AmountStr)
 		if err != nil {
 			continue
 		}
-		decimalDivisor := decimal.NewFromFloat(1000000)
-		// 金额统一保留4位小数，避免精度不匹配问题，比如12.31和12.3100
-		amount, _ := decimalQuant.Div(decimalDivisor).Round(4).Float64()
-
-		tx := blockchain.Transaction{
-			Hash:            transfer.Hash,
-			From:            transfer.From,
-			To:              transfer.To,
+		tokenDecimals := transfer.Decimals
+		if tokenDecimals <= 0 {
+			tokenDecimals = 6 // TRC20 USDT 是6位小数
+		}
+		amount, _ := decimalQuant.Div(decimal.New(1, tokenDecimals)).Round(4).Float64()
+		transactions = append(transactions, blockchain.Transaction{
+			Hash:            info.Hash,
+			From:            transfer.FromAddress,
+			To:              transfer.ToAddress,
 			Amount:          amount,
-			BlockTimestamp:  transfer.BlockTimestamp,
-			Confirmations:   transfer.Confirmed,
-			Status:          transfer.ContractRet,
+			BlockTimestamp:  info.Timestamp,
+			Confirmations:   confirmations,
+			Status:          "SUCCESS",
 			ContractAddress: USDTContractAddressTRC20,
-		}
-		transactions = append(transactions, tx)
+		})
+	}
+
+	// TRX 是6位小数（sun）
+	if info.ContractType == 1 && info.ContractData.ToAddress == to && info.ContractData.Amount.IsPositive() {
+		amount, _ := info.ContractData.Amount.Div(decimal.NewFromInt(1000000)).Round(blockchain.NativeAmountDecimals).Float64()
+		transactions = append(transactions, blockchain.Transaction{
+			Hash:           info.Hash,
+			From:           info.ContractData.OwnerAddress,
+			To:             info.ContractData.ToAddress,
+			Amount:         amount,
+			BlockTimestamp: info.Timestamp,
+			Confirmations:  confirmations,
+			Status:         "SUCCESS",
+		})
 	}
 
 	return transactions, nil
@@ -132,6 +347,10 @@ func (s *TRC20Service) GetTransactions(address string, startTime int64, endTime
 
 // GetTokenBalance 获取地址的代币余额（TRC20只支持USDT）
 func (s *TRC20Service) GetTokenBalance(address string) (*blockchain.TokenBalance, error) {
+	if config.GetTrc20ApiProvider() == ProviderTronGrid {
+		return s.getTokenBalanceFromTronGrid(address)
+	}
+
 	client := http_client.GetHttpClient()
 
 	// 使用 TronScan 公开 API 获取账户信息（包含所有TRC20代币）
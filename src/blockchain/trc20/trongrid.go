@@ -0,0 +1,334 @@
+package trc20
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/assimon/luuu/blockchain"
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/util/hdwallet"
+	"github.com/assimon/luuu/util/http_client"
+	"github.com/assimon/luuu/util/json"
+	"github.com/go-resty/resty/v2"
+	"github.com/gookit/goutil/stdutil"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ProviderTronScan = "tronscan" // TronScan 公开 API
+	ProviderTronGrid = "trongrid" // TronGrid 或自建 java-tron 事件服务
+
+	TronGridPageSize = 200 // TronGrid 单页最大条数
+)
+
+type TronGridTrc20Resp struct {
+	Data    []TronGridTrc20Transfer `json:"data"`
+	Success bool                    `json:"success"`
+	Error   string                  `json:"error"`
+	Meta    struct {
+		At          int64  `json:"at"`
+		PageSize    int    `json:"page_size"`
+		Fingerprint string `json:"fingerprint"`
+	} `json:"meta"`
+}
+
+type TronGridTrc20Transfer struct {
+	TransactionId string `json:"transaction_id"`
+	TokenInfo     struct {
+		Symbol   string `json:"symbol"`
+		Address  string `json:"address"`
+		Decimals int32  `json:"decimals"`
+		Name     string `json:"name"`
+	} `json:"token_info"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Type           string `json:"type"`
+	Value          string `json:"value"`
+}
+
+// newTronGridRequest 创建 TronGrid 请求，配置了 API Key 时携带认证头
+func newTronGridRequest() *resty.Request {
+	req := http_client.GetHttpClient().R().SetHeader("Accept", "application/json")
+	if apiKey := config.GetTronGridApiKey(); apiKey != "" {
+		req.SetHeader("TRON-PRO-API-KEY", apiKey)
+	}
+	return req
+}
+
+// getTransactionsFromTronGrid 通过 TronGrid 查询TRC20 USDT转入记录，按 fingerprint 翻页
+func (s *TRC20Service) getTransactionsFromTronGrid(address string, startTime int64, endTime int64) ([]blockchain.Transaction, error) {
+	uri := fmt.Sprintf("%s/v1/accounts/%s/transactions/trc20", config.GetTronGridApiUri(), address)
+
+	transactions := make([]blockchain.Transaction, 0)
+	fingerprint := ""
+	// 单次查询最多翻页数，避免无限翻页
+	maxPages := config.GetBlockchainMaxPages()
+	for page := 0; page < maxPages; page++ {
+		params := map[string]string{
+			"only_to":          "true",
+			"only_confirmed":   "true",
+			"limit":            stdutil.ToString(TronGridPageSize),
+			"contract_address": USDTContractAddressTRC20,
+			"min_timestamp":    stdutil.ToString(startTime),
+			"max_timestamp":    stdutil.ToString(endTime),
+			"order_by":         "block_timestamp,desc",
+		}
+		if fingerprint != "" {
+			params["fingerprint"] = fingerprint
+		}
+
+		resp, err := newTronGridRequest().SetQueryParams(params).Get(uri)
+		if err != nil {
+			return nil, fmt.Errorf("TronGrid API 请求失败: %w", err)
+		}
+
+		if resp.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("TronGrid API 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
+		}
+
+		var gridResp TronGridTrc20Resp
+		err = json.Cjson.Unmarshal(resp.Body(), &gridResp)
+		if err != nil {
+			return nil, fmt.Errorf("解析 TronGrid 响应失败: %w", err)
+		}
+
+		if !gridResp.Success {
+			return nil, fmt.Errorf("TronGrid API 返回错误: %s", gridResp.Error)
+		}
+
+		for _, transfer := range gridResp.Data {
+			if transfer.To != address || transfer.Type != "Transfer" {
+				continue
+			}
+
+			decimalQuant, err := decimal.NewFromString(transfer.Value)
+			if err != nil {
+				continue
+			}
+			tokenDecimals := transfer.TokenInfo.Decimals
+			if tokenDecimals <= 0 {
+				tokenDecimals = 6 // TRC20 USDT 是6位小数
+			}
+			// 金额统一保留4位小数，避免精度不匹配问题
+			amount, _ := decimalQuant.Div(decimal.New(1, tokenDecimals)).Round(4).Float64()
+
+			transactions = append(transactions, blockchain.Transaction{
+				Hash:            transfer.TransactionId,
+				From:            transfer.From,
+				To:              transfer.To,
+				Amount:          amount,
+				BlockTimestamp:  transfer.BlockTimestamp,
+				Confirmations:   1, // only_confirmed 只返回已确认交易
+				Status:          "SUCCESS",
+				ContractAddress: USDTContractAddressTRC20,
+			})
+		}
+
+		// 没有下一页
+		fingerprint = gridResp.Meta.Fingerprint
+		if fingerprint == "" || len(gridResp.Data) < TronGridPageSize {
+			return transactions, nil
+		}
+	}
+
+	// 达到翻页上限时仍有更早的交易未获取，返回已获取的交易，调用方不推进游标
+	return transactions, blockchain.ErrMaxPagesReached
+}
+
+// getTokenBalanceFromTronGrid 通过 TronGrid 查询USDT余额
+func (s *TRC20Service) getTokenBalanceFromTronGrid(address string) (*blockchain.TokenBalance, error) {
+	uri := fmt.Sprintf("%s/v1/accounts/%s", config.GetTronGridApiUri(), address)
+	resp, err := newTronGridRequest().Get(uri)
+	if err != nil {
+		return nil, fmt.Errorf("TronGrid API 请求失败: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("TronGrid API 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var apiResp struct {
+		Data []struct {
+			Trc20 []map[string]string `json:"trc20"`
+		} `json:"data"`
+		Success bool `json:"success"`
+	}
+	err = json.Cjson.Unmarshal(resp.Body(), &apiResp)
+	if err != nil {
+		return nil, fmt.Errorf("解析 TronGrid 响应失败: %w, 响应内容: %s", err, string(resp.Body()))
+	}
+
+	balance := &blockchain.TokenBalance{}
+	// 未激活的账户 data 为空，余额为0
+	if len(apiResp.Data) == 0 {
+		return balance, nil
+	}
+
+	for _, token := range apiResp.Data[0].Trc20 {
+		value, ok := token[USDTContractAddressTRC20]
+		if !ok {
+			continue
+		}
+		balanceDecimal, err := decimal.NewFromString(value)
+		if err == nil {
+			balance.USDT, _ = balanceDecimal.Div(decimal.NewFromInt(1000000)).Round(4).Float64()
+		}
+		break
+	}
+
+	return balance, nil
+}
+
+// getTransfersByHashFromTronGrid 通过 TronGrid 查询已固化交易中转入 to 地址的 USDT 和 TRX 转账
+// USDT 转账从交易回执的 Transfer 事件解析，日志中的地址为不含 41 前缀的十六进制
+func (s *TRC20Service) getTransfersByHashFromTronGrid(hash string, to string) ([]blockchain.Transaction, error) {
+	toHex, err := hdwallet.TronAddressToHex(to)
+	if err != nil {
+		return nil, err
+	}
+	contractHex, err := hdwallet.TronAddressToHex(USDTContractAddressTRC20)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := newTronGridRequest().
+		SetBody(map[string]string{"value": hash}).
+		Post(config.GetTronGridApiUri() + "/walletsolidity/gettransactioninfobyid")
+	if err != nil {
+		return nil, fmt.Errorf("TronGrid API 请求失败: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("TronGrid API 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	// 交易不存在或尚未固化时返回空对象
+	var info struct {
+		Id             string `json:"id"`
+		BlockTimeStamp int64  `json:"blockTimeStamp"`
+		Result         string `json:"result"`
+		Receipt        struct {
+			Result string `json:"result"`
+		} `json:"receipt"`
+		Log []struct {
+			Address string   `json:"address"`
+			Topics  []string `json:"topics"`
+			Data    string   `json:"data"`
+		} `json:"log"`
+	}
+	err = json.Cjson.Unmarshal(resp.Body(), &info)
+	if err != nil {
+		return nil, fmt.Errorf("解析 TronGrid 响应失败: %w", err)
+	}
+
+	transactions := make([]blockchain.Transaction, 0)
+	if info.Id == "" {
+		return transactions, nil
+	}
+	if info.Result == "FAILED" || (info.Receipt.Result != "" && info.Receipt.Result != "SUCCESS") {
+		return nil, blockchain.ErrTransactionFailed
+	}
+
+	transferTopic := strings.TrimPrefix(blockchain.TransferEventSignature, "0x")
+	for _, log := range info.Log {
+		if !strings.EqualFold(log.Address, contractHex) || len(log.Topics) < 3 || !strings.EqualFold(log.Topics[0], transferTopic) {
+			continue
+		}
+		if len(log.Topics[1]) < 40 || len(log.Topics[2]) < 40 {
+			continue
+		}
+		if !strings.EqualFold(log.Topics[2][len(log.Topics[2])-40:], toHex) {
+			continue
+		}
+		value, ok := new(big.Int).SetString(log.Data, 16)
+		if !ok {
+			continue
+		}
+		from, err := hdwallet.TronAddressFromHex(log.Topics[1][len(log.Topics[1])-40:])
+		if err != nil {
+			continue
+		}
+		// TRC20 USDT 是6位小数
+		amount, _ := decimal.NewFromBigInt(value, 0).Div(decimal.NewFromInt(1000000)).Round(4).Float64()
+		transactions = append(transactions, blockchain.Transaction{
+			Hash:            info.Id,
+			From:            from,
+			To:              to,
+			Amount:          amount,
+			BlockTimestamp:  info.BlockTimeStamp,
+			Confirmations:   1, // walletsolidity 只返回已固化交易
+			Status:          "SUCCESS",
+			ContractAddress: USDTContractAddressTRC20,
+		})
+	}
+	if len(info.Log) > 0 {
+		return transactions, nil
+	}
+
+	// 没有事件日志时按 TRX 转账解析交易内容
+	resp, err = newTronGridRequest().
+		SetBody(map[string]string{"value": hash}).
+		Post(config.GetTronGridApiUri() + "/walletsolidity/gettransactionbyid")
+	if err != nil {
+		return nil, fmt.Errorf("TronGrid API 请求失败: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("TronGrid API 返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var tx struct {
+		Ret []struct {
+			ContractRet string `json:"contractRet"`
+		} `json:"ret"`
+		RawData struct {
+			Contract []struct {
+				Type      string `json:"type"`
+				Parameter struct {
+					Value struct {
+						Amount       int64  `json:"amount"`
+						OwnerAddress string `json:"owner_address"`
+						ToAddress    string `json:"to_address"`
+					} `json:"value"`
+				} `json:"parameter"`
+			} `json:"contract"`
+		} `json:"raw_data"`
+	}
+	err = json.Cjson.Unmarshal(resp.Body(), &tx)
+	if err != nil {
+		return nil, fmt.Errorf("解析 TronGrid 响应失败: %w", err)
+	}
+	if len(tx.Ret) > 0 && tx.Ret[0].ContractRet != "" && tx.Ret[0].ContractRet != "SUCCESS" {
+		return nil, blockchain.ErrTransactionFailed
+	}
+
+	for _, contract := range tx.RawData.Contract {
+		value := contract.Parameter.Value
+		if contract.Type != "TransferContract" || value.Amount <= 0 {
+			continue
+		}
+		if !strings.EqualFold(strings.TrimPrefix(value.ToAddress, "41"), toHex) {
+			continue
+		}
+		from, err := hdwallet.TronAddressFromHex(value.OwnerAddress)
+		if err != nil {
+			continue
+		}
+		// TRX 是6位小数（sun）
+		amount, _ := decimal.NewFromInt(value.Amount).Div(decimal.NewFromInt(1000000)).Round(blockchain.NativeAmountDecimals).Float64()
+		transactions = append(transactions, blockchain.Transaction{
+			Hash:           info.Id,
+			From:           from,
+			To:             to,
+			Amount:         amount,
+			BlockTimestamp: info.BlockTimeStamp,
+			Confirmations:  1,
+			Status:         "SUCCESS",
+		})
+	}
+
+	return transactions, nil
+}
@@ -29,6 +29,8 @@ func Start() {
 	http_client.TestProxy()
 	// MySQL启动
 	dao.MysqlInit()
+	// 恢复最近一次的汇率
+	task.LoadLatestRates()
 	// 队列启动
 	mq.Start()
 	// telegram机器人启动
@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/viper"
@@ -12,6 +15,9 @@ var (
 	AppDebug                 bool
 	LogDebug                 bool // 日志是否输出到控制台
 	BlockchainListenInterval int  // 区块链监听间隔（秒）
+	BlockchainMaxPages       int  // 单次查询交易记录最多翻页数
+	BlockchainCatchupHours   int  // 停机后补扫的最大时间窗口（小时）
+	BlockchainScanOverlap    int  // 增量扫描时回退的重叠时间（秒）
 	MysqlHost                string
 	MysqlPort                string
 	MysqlUser                string
@@ -23,11 +29,30 @@ var (
 	TgBotToken               string
 	Proxy                    string // 全局代理地址
 	TgManage                 int64
-	UsdtRate                 float64
 	EtherscanApiKey          string
 	BscScanApiKey            string // 已弃用，请使用 EtherscanApiKey，Etherscan API V2 支持多链
 	SolanaRpcEndpoint        string
-	Bep20RpcUrl              string // BEP20 RPC URL
+	Bep20RpcUrl              string   // BEP20 RPC URL
+	Erc20RpcUrl              string   // ERC20 RPC URL，区块扫描模式使用
+	PolygonRpcUrl            string   // Polygon RPC URL，区块扫描模式使用
+	ArbRpcUrl                string   // Arbitrum RPC URL，区块扫描模式使用
+	EvmListenMode            string   // EVM 链监听模式：address、block
+	AddressMode              string   // 收款地址模式：amount（金额区分订单）、derive（每单派生独立地址）
+	EvmXpub                  string   // EVM 链扩展公钥，m/44'/60'/0'
+	TronXpub                 string   // TRON 扩展公钥，m/44'/195'/0'
+	AmountStrategy           string   // 金额分配策略：increment、decrement、random
+	AmountDiscountBudget     float64  // decrement 策略最多少收的金额
+	AmountRandomRange        int      // random 策略随机偏移的最大步数
+	Trc20ApiProvider         string   // TRC20 数据源：tronscan、trongrid
+	TronGridApiUri           string   // TronGrid 或自建 java-tron 事件服务地址
+	TronGridApiKey           string   // TronGrid API Key
+	NativeRates              sync.Map // 原生币USDT价格，symbol => FiatRate
+	FiatRates                sync.Map // 法币汇率，currency => FiatRate
+	RateProviders            string   // 汇率数据源，逗号分隔，按顺序回退
+	RateAggregation          string   // 汇率聚合方式：fallback、median
+	RateMaxDeviation         float64  // 汇率最大偏差（百分比）
+	RateStaleSeconds         int      // 汇率过期阈值（秒）
+	RateStaleBlock           bool     // 汇率过期时是否禁止下单
 )
 
 func Init() {
@@ -47,6 +72,9 @@ func Init() {
 	if BlockchainListenInterval <= 0 {
 		BlockchainListenInterval = 10 // 默认10秒
 	}
+	BlockchainMaxPages = viper.GetInt("blockchain_max_pages")
+	BlockchainCatchupHours = viper.GetInt("blockchain_catchup_hours")
+	BlockchainScanOverlap = viper.GetInt("blockchain_scan_overlap")
 	StaticPath = viper.GetString("static_path")
 	RuntimePath = fmt.Sprintf(
 		"%s%s",
@@ -81,6 +109,24 @@ func Init() {
 	BscScanApiKey = viper.GetString("bscscan_api_key")
 	SolanaRpcEndpoint = viper.GetString("solana_rpc_endpoint")
 	Bep20RpcUrl = viper.GetString("bep20_rpc_url")
+	Erc20RpcUrl = viper.GetString("erc20_rpc_url")
+	PolygonRpcUrl = viper.GetString("polygon_rpc_url")
+	ArbRpcUrl = viper.GetString("arb_rpc_url")
+	EvmListenMode = viper.GetString("evm_listen_mode")
+	AddressMode = viper.GetString("address_mode")
+	EvmXpub = viper.GetString("evm_xpub")
+	TronXpub = viper.GetString("tron_xpub")
+	AmountStrategy = viper.GetString("amount_strategy")
+	AmountDiscountBudget = viper.GetFloat64("amount_discount_budget")
+	AmountRandomRange = viper.GetInt("amount_random_range")
+	Trc20ApiProvider = viper.GetString("trc20_api_provider")
+	TronGridApiUri = viper.GetString("trongrid_api_uri")
+	TronGridApiKey = viper.GetString("trongrid_api_key")
+	RateProviders = viper.GetString("rate_providers")
+	RateAggregation = viper.GetString("rate_aggregation")
+	RateMaxDeviation = viper.GetFloat64("rate_max_deviation")
+	RateStaleSeconds = viper.GetInt("rate_stale_seconds")
+	RateStaleBlock = viper.GetBool("rate_stale_block")
 	fmt.Println(SolanaRpcEndpoint)
 }
 
@@ -104,15 +150,131 @@ func GetApiAuthToken() string {
 	return viper.GetString("api_auth_token")
 }
 
-func GetUsdtRate() float64 {
-	forcedUsdtRate := viper.GetFloat64("forced_usdt_rate")
-	if forcedUsdtRate > 0 {
-		return forcedUsdtRate
+// GetDefaultCurrency 订单未指定法币时使用的默认法币，默认 CNY
+func GetDefaultCurrency() string {
+	currency := strings.ToUpper(strings.TrimSpace(viper.GetString("default_currency")))
+	if currency == "" {
+		return "CNY"
 	}
-	if UsdtRate <= 0 {
+	return currency
+}
+
+// GetFiatCurrencies 支持的法币列表，汇率任务按此列表刷新汇率
+func GetFiatCurrencies() []string {
+	currencies := make([]string, 0)
+	for _, currency := range strings.Split(viper.GetString("fiat_currencies"), ",") {
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if currency != "" {
+			currencies = append(currencies, currency)
+		}
+	}
+	if len(currencies) == 0 {
+		currencies = []string{"CNY", "USD", "EUR"}
+	}
+	return currencies
+}
+
+// IsFiatCurrencySupported 法币是否在支持列表中
+func IsFiatCurrencySupported(currency string) bool {
+	for _, supported := range GetFiatCurrencies() {
+		if supported == strings.ToUpper(currency) {
+			return true
+		}
+	}
+	return false
+}
+
+// FiatRate 法币汇率及更新时间
+type FiatRate struct {
+	Rate      float64
+	UpdatedAt time.Time
+}
+
+// SetFiatRate 更新法币汇率
+func SetFiatRate(currency string, rate float64) {
+	FiatRates.Store(strings.ToUpper(currency), FiatRate{Rate: rate, UpdatedAt: time.Now()})
+}
+
+// RestoreFiatRate 恢复历史汇率，保留原更新时间以便判断是否过期
+func RestoreFiatRate(currency string, rate float64, updatedAt time.Time) {
+	FiatRates.Store(strings.ToUpper(currency), FiatRate{Rate: rate, UpdatedAt: updatedAt})
+}
+
+// GetFetchedFiatRate 获取最近一次从数据源获取的汇率，未获取到时返回 nil
+func GetFetchedFiatRate(currency string) *FiatRate {
+	value, ok := FiatRates.Load(strings.ToUpper(currency))
+	if !ok {
+		return nil
+	}
+	rate := value.(FiatRate)
+	return &rate
+}
+
+// getForcedFiatRate 获取强制汇率，CNY 兼容旧配置 forced_usdt_rate
+func getForcedFiatRate(currency string) float64 {
+	forcedRate := viper.GetFloat64(fmt.Sprintf("forced_%s_rate", strings.ToLower(currency)))
+	if strings.ToUpper(currency) == "CNY" && forcedRate <= 0 {
+		forcedRate = viper.GetFloat64("forced_usdt_rate")
+	}
+	return forcedRate
+}
+
+// IsFiatRateStale 汇率是否超过 rate_stale_seconds 未更新，配置强制汇率时不会过期
+func IsFiatRateStale(currency string) bool {
+	if getForcedFiatRate(currency) > 0 {
+		return false
+	}
+	fetched := GetFetchedFiatRate(currency)
+	if fetched == nil {
+		return true
+	}
+	return time.Since(fetched.UpdatedAt) > time.Duration(GetRateStaleSeconds())*time.Second
+}
+
+// GetFiatRate 获取 1 USDT 折合的法币数量，优先使用强制汇率，未获取到时返回0
+// CNY 兼容旧配置 forced_usdt_rate，并在未获取到汇率时沿用默认值 6.4
+func GetFiatRate(currency string) float64 {
+	currency = strings.ToUpper(currency)
+	if forcedRate := getForcedFiatRate(currency); forcedRate > 0 {
+		return forcedRate
+	}
+	if fetched := GetFetchedFiatRate(currency); fetched != nil && fetched.Rate > 0 {
+		return fetched.Rate
+	}
+	if currency == "CNY" {
 		return 6.4
 	}
-	return UsdtRate
+	return 0
+}
+
+// SetNativeRate 更新原生币USDT价格
+func SetNativeRate(symbol string, rate float64) {
+	NativeRates.Store(strings.ToUpper(symbol), FiatRate{Rate: rate, UpdatedAt: time.Now()})
+}
+
+// IsNativeRateStale 原生币价格是否超过 rate_stale_seconds 未更新，配置强制价格时不会过期
+func IsNativeRateStale(symbol string) bool {
+	if viper.GetFloat64(fmt.Sprintf("forced_%s_rate", strings.ToLower(symbol))) > 0 {
+		return false
+	}
+	value, ok := NativeRates.Load(strings.ToUpper(symbol))
+	if !ok {
+		return true
+	}
+	return time.Since(value.(FiatRate).UpdatedAt) > time.Duration(GetRateStaleSeconds())*time.Second
+}
+
+// GetNativeRate 获取原生币USDT价格，优先使用强制价格，未获取到时返回0
+func GetNativeRate(symbol string) float64 {
+	forcedRate := viper.GetFloat64(fmt.Sprintf("forced_%s_rate", strings.ToLower(symbol)))
+	if forcedRate > 0 {
+		return forcedRate
+	}
+	value, ok := NativeRates.Load(strings.ToUpper(symbol))
+	if !ok {
+		return 0
+	}
+	return value.(FiatRate).Rate
 }
 
 func GetOrderExpirationTime() int {
@@ -128,6 +290,44 @@ func GetOrderExpirationTimeDuration() time.Duration {
 	return time.Minute * time.Duration(timer)
 }
 
+// GetOpenOrderExpirationTime 开放订单等待付款人选择链的时间（分钟），选择后重新按 order_expiration_time 计算过期
+func GetOpenOrderExpirationTime() int {
+	timer := viper.GetInt("open_order_expiration_time")
+	if timer <= 0 {
+		return 60
+	}
+	return timer
+}
+
+// GetCheckoutTemplatePath 收银台模板覆盖目录，为空时只使用内置模板
+// 目录下的 index.html 覆盖默认模板，{theme}/index.html 覆盖指定主题的订单，locales/*.json 覆盖或新增语言文案
+func GetCheckoutTemplatePath() string {
+	return viper.GetString("checkout_template_path")
+}
+
+// GetCheckoutDefaultLang 收银台默认语言，未通过 lang 参数或 Accept-Language 匹配到语言时使用，默认 zh-CN
+func GetCheckoutDefaultLang() string {
+	lang := viper.GetString("checkout_default_lang")
+	if lang == "" {
+		return "zh-CN"
+	}
+	return lang
+}
+
+// GetPayCorsOrigins 允许跨域访问 /pay 接口的来源，默认 * 允许所有来源
+func GetPayCorsOrigins() []string {
+	origins := make([]string, 0)
+	for _, origin := range strings.Split(viper.GetString("pay_cors_origins"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		return []string{"*"}
+	}
+	return origins
+}
+
 func GetEtherscanApiKey() string {
 	return EtherscanApiKey
 }
@@ -156,6 +356,30 @@ func GetBlockchainListenInterval() int {
 	return BlockchainListenInterval
 }
 
+// GetBlockchainMaxPages 获取单次查询交易记录最多翻页数
+func GetBlockchainMaxPages() int {
+	if BlockchainMaxPages <= 0 {
+		return 10 // 默认10页
+	}
+	return BlockchainMaxPages
+}
+
+// GetBlockchainCatchupHours 获取停机后补扫的最大时间窗口
+func GetBlockchainCatchupHours() int {
+	if BlockchainCatchupHours <= 0 {
+		return 24 // 默认24小时
+	}
+	return BlockchainCatchupHours
+}
+
+// GetBlockchainScanOverlap 获取增量扫描回退的重叠时间，覆盖区块浏览器索引延迟
+func GetBlockchainScanOverlap() int {
+	if BlockchainScanOverlap <= 0 {
+		return 120 // 默认120秒
+	}
+	return BlockchainScanOverlap
+}
+
 // GetBep20RpcUrl 获取 BEP20 RPC URL
 func GetBep20RpcUrl() string {
 	if Bep20RpcUrl == "" {
@@ -163,3 +387,247 @@ func GetBep20RpcUrl() string {
 	}
 	return Bep20RpcUrl
 }
+
+// GetErc20RpcUrl 获取 ERC20 RPC URL
+func GetErc20RpcUrl() string {
+	if Erc20RpcUrl == "" {
+		return "https://ethereum-rpc.publicnode.com" // 默认 PublicNode RPC
+	}
+	return Erc20RpcUrl
+}
+
+// GetPolygonRpcUrl 获取 Polygon RPC URL
+func GetPolygonRpcUrl() string {
+	if PolygonRpcUrl == "" {
+		return "https://polygon-bor-rpc.publicnode.com" // 默认 PublicNode RPC
+	}
+	return PolygonRpcUrl
+}
+
+// GetArbRpcUrl 获取 Arbitrum RPC URL
+func GetArbRpcUrl() string {
+	if ArbRpcUrl == "" {
+		return "https://arb1.arbitrum.io/rpc" // 默认 Arbitrum 官方 RPC
+	}
+	return ArbRpcUrl
+}
+
+// GetEvmListenMode 获取 EVM 链监听模式，默认 address（按地址轮询）
+func GetEvmListenMode() string {
+	if EvmListenMode == "" {
+		return "address"
+	}
+	return strings.ToLower(EvmListenMode)
+}
+
+// GetTrc20ApiProvider 获取 TRC20 数据源，默认 tronscan
+func GetTrc20ApiProvider() string {
+	if Trc20ApiProvider == "" {
+		return "tronscan"
+	}
+	return strings.ToLower(Trc20ApiProvider)
+}
+
+// GetTronGridApiUri 获取 TronGrid API 地址
+func GetTronGridApiUri() string {
+	if TronGridApiUri == "" {
+		return "https://api.trongrid.io" // 默认 TronGrid 主网
+	}
+	return strings.TrimRight(TronGridApiUri, "/")
+}
+
+// GetTronGridApiKey 获取 TronGrid API Key
+func GetTronGridApiKey() string {
+	return TronGridApiKey
+}
+
+// GetAddressMode 获取收款地址模式，默认 amount（共用钱包，按金额区分订单）
+func GetAddressMode() string {
+	if AddressMode == "" {
+		return "amount"
+	}
+	return strings.ToLower(AddressMode)
+}
+
+// GetEvmXpub 获取 EVM 链扩展公钥
+func GetEvmXpub() string {
+	return strings.TrimSpace(EvmXpub)
+}
+
+// GetTronXpub 获取 TRON 扩展公钥
+func GetTronXpub() string {
+	return strings.TrimSpace(TronXpub)
+}
+
+// GetAmountStrategy 获取金额分配策略，默认 increment（向上递增）
+func GetAmountStrategy() string {
+	if AmountStrategy == "" {
+		return "increment"
+	}
+	return strings.ToLower(AmountStrategy)
+}
+
+// GetAmountDiscountBudget 获取 decrement 策略最多少收的金额
+func GetAmountDiscountBudget() float64 {
+	if AmountDiscountBudget <= 0 {
+		return 0.01 // 默认最多少收0.01
+	}
+	return AmountDiscountBudget
+}
+
+// GetAmountRandomRange 获取 random 策略随机偏移的最大步数
+func GetAmountRandomRange() int {
+	if AmountRandomRange <= 0 {
+		return 100 // 默认±100步，即±0.01
+	}
+	return AmountRandomRange
+}
+
+// GetRateProviders 获取汇率数据源列表，按顺序回退，默认 coinmarketcap
+func GetRateProviders() []string {
+	providers := make([]string, 0)
+	for _, provider := range strings.Split(RateProviders, ",") {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if provider != "" {
+			providers = append(providers, provider)
+		}
+	}
+	if len(providers) == 0 {
+		providers = []string{"coinmarketcap"}
+	}
+	return providers
+}
+
+// GetRateAggregation 获取汇率聚合方式，fallback 取第一个成功的数据源，median 取所有数据源的中位数
+func GetRateAggregation() string {
+	if RateAggregation == "" {
+		return "fallback"
+	}
+	return strings.ToLower(RateAggregation)
+}
+
+// GetRateMaxDeviation 获取汇率最大偏差百分比，超出时丢弃该汇率
+func GetRateMaxDeviation() float64 {
+	if RateMaxDeviation <= 0 {
+		return 5 // 默认5%
+	}
+	return RateMaxDeviation
+}
+
+// GetRateStaleSeconds 获取汇率过期阈值（秒）
+func GetRateStaleSeconds() int {
+	if RateStaleSeconds <= 0 {
+		return 600 // 默认10分钟
+	}
+	return RateStaleSeconds
+}
+
+// GetRateStaleBlock 汇率过期时是否禁止下单
+func GetRateStaleBlock() bool {
+	return RateStaleBlock
+}
+
+// GetStaticRates 获取 static 数据源的固定汇率，格式 CNY:7.2,USD:1
+func GetStaticRates() map[string]float64 {
+	rates := make(map[string]float64)
+	for _, item := range strings.Split(viper.GetString("static_rates"), ",") {
+		parts := strings.SplitN(strings.TrimSpace(item), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || rate <= 0 {
+			continue
+		}
+		rates[strings.ToUpper(strings.TrimSpace(parts[0]))] = rate
+	}
+	return rates
+}
+
+// GetRateMarkupPercent 获取汇率加价百分比，例如 1.5 表示在市场汇率基础上多收 1.5%
+func GetRateMarkupPercent() float64 {
+	return viper.GetFloat64("rate_markup_percent")
+}
+
+// GetChainFee 获取链固定手续费（USDT计价），原生币优先读取 chain_fee_{链}_{币种}，未配置时使用 chain_fee_{链}
+func GetChainFee(chainType string, asset string) float64 {
+	chainKey := fmt.Sprintf("chain_fee_%s", strings.ToLower(chainType))
+	assetKey := fmt.Sprintf("%s_%s", chainKey, strings.ToLower(asset))
+	if viper.GetString(assetKey) != "" {
+		return viper.GetFloat64(assetKey)
+	}
+	return viper.GetFloat64(chainKey)
+}
+
+// GetAmountRounding 获取应付金额取整方式：round（四舍五入，默认）、up（向上取整）、down（向下取整）
+func GetAmountRounding() string {
+	rounding := strings.ToLower(viper.GetString("amount_rounding"))
+	if rounding == "" {
+		return "round"
+	}
+	return rounding
+}
+
+// GetAmountPrecision 获取应付金额保留的小数位数，范围 0-4，默认4
+func GetAmountPrecision() int32 {
+	if viper.GetString("amount_precision") == "" {
+		return 4
+	}
+	precision := viper.GetInt32("amount_precision")
+	if precision < 0 {
+		return 0
+	}
+	if precision > 4 {
+		return 4 // 金额锁按4位小数区分订单
+	}
+	return precision
+}
+
+// GetDepositNotifyUrl 获取客户充值地址的默认充值回调地址，分配地址时未指定 notify_url 的使用此地址
+func GetDepositNotifyUrl() string {
+	return strings.TrimSpace(viper.GetString("deposit_notify_url"))
+}
+
+// GetSubscriptionRetryInterval 获取订阅账单过期未支付后重新生成账单的间隔（小时），默认24
+func GetSubscriptionRetryInterval() int {
+	interval := viper.GetInt("subscription_retry_interval")
+	if interval <= 0 {
+		return 24
+	}
+	return interval
+}
+
+// GetSubscriptionMaxRetries 获取订阅逾期后最多重新生成账单的次数，超过后自动取消订阅，默认3
+func GetSubscriptionMaxRetries() int {
+	if viper.GetString("subscription_max_retries") == "" {
+		return 3
+	}
+	retries := viper.GetInt("subscription_max_retries")
+	if retries < 0 {
+		return 0
+	}
+	return retries
+}
+
+// GetSubscriptionEmailWebhook 获取订阅账单邮件 webhook 地址，设置后向有邮箱的客户发送账单
+func GetSubscriptionEmailWebhook() string {
+	return strings.TrimSpace(viper.GetString("subscription_email_webhook"))
+}
+
+// GetRefundVerifyTimeout 获取退款交易核验超时时间（小时），提交哈希后超过该时间仍未查询到已确认的交易则核验失败，默认24
+func GetRefundVerifyTimeout() int {
+	timeout := viper.GetInt("refund_verify_timeout")
+	if timeout <= 0 {
+		return 24
+	}
+	return timeout
+}
+
+// GetRateConfirmCount 获取汇率偏差过大时需连续获取一致的次数，达到后采用新汇率，默认3
+func GetRateConfirmCount() int {
+	count := viper.GetInt("rate_confirm_count")
+	if count <= 0 {
+		return 3
+	}
+	return count
+}
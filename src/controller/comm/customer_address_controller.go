@@ -0,0 +1,72 @@
+package comm
+
+import (
+	"github.com/assimon/luuu/model/request"
+	"github.com/assimon/luuu/model/service"
+	"github.com/assimon/luuu/util/constant"
+	"github.com/labstack/echo/v4"
+)
+
+// AssignCustomerAddress 为客户分配永久充值地址
+func (c *BaseCommController) AssignCustomerAddress(ctx echo.Context) (err error) {
+	req := new(request.AssignCustomerAddressRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.AssignCustomerAddress(req)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// CustomerAddressDetail 查询客户的充值地址
+func (c *BaseCommController) CustomerAddressDetail(ctx echo.Context) (err error) {
+	req := new(request.CustomerAddressDetailRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.GetCustomerAddresses(req.CustomerId)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// ImportCustomerAddress 导入客户充值地址池
+func (c *BaseCommController) ImportCustomerAddress(ctx echo.Context) (err error) {
+	req := new(request.ImportCustomerAddressRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.ImportCustomerAddresses(req)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// CustomerDepositList 客户充值记录列表
+func (c *BaseCommController) CustomerDepositList(ctx echo.Context) (err error) {
+	req := new(request.CustomerDepositListRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	list, pagination, err := service.ListCustomerDeposits(req.CustomerId, req.Page, req.PageSize)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJsonPage(ctx, list, pagination)
+}
@@ -1,13 +1,27 @@
 package comm
 
 import (
+	"bytes"
 	"fmt"
-	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/event"
 	"github.com/assimon/luuu/model/response"
 	"github.com/assimon/luuu/model/service"
+	"github.com/assimon/luuu/util/json"
+	"github.com/assimon/luuu/util/qr"
+	"github.com/assimon/luuu/view"
 	"github.com/labstack/echo/v4"
-	"html/template"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 状态推送心跳间隔，避免代理因连接空闲而断开
+	statusStreamHeartbeat = 15 * time.Second
+	// 兜底查询间隔，覆盖其他实例处理的订单及到期未触发过期任务的订单
+	statusStreamRecheck = 30 * time.Second
 )
 
 // CheckoutCounter 收银台
@@ -17,11 +31,24 @@ func (c *BaseCommController) CheckoutCounter(ctx echo.Context) (err error) {
 	if err != nil {
 		return ctx.String(http.StatusOK, err.Error())
 	}
-	tmpl, err := template.ParseFiles(fmt.Sprintf(".%s/%s", config.StaticPath, "index.html"))
-	if err != nil {
+	page := view.CheckoutPage{
+		CheckoutCounterResponse: resp,
+		Locale:                  view.NewLocale(ctx.QueryParam("lang"), ctx.Request().Header.Get("Accept-Language")),
+	}
+	var buf bytes.Buffer
+	if err = view.Render(&buf, view.CheckoutTemplate, resp.Theme, page); err != nil {
 		return ctx.String(http.StatusOK, err.Error())
 	}
-	return tmpl.Execute(ctx.Response(), resp)
+	return ctx.HTMLBlob(http.StatusOK, buf.Bytes())
+}
+
+// CheckoutApi 收银台详情 JSON 接口
+func (c *BaseCommController) CheckoutApi(ctx echo.Context) (err error) {
+	resp, err := service.GetCheckoutApiByTradeId(ctx.Param("trade_id"))
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
 }
 
 // CheckStatus 支付状态检测
@@ -37,3 +64,112 @@ func (c *BaseCommController) CheckStatus(ctx echo.Context) (err error) {
 	}
 	return c.SucJson(ctx, resp)
 }
+
+// QrCode 订单支付二维码，路径为 /pay/qrcode/:trade_id.png 或 .svg
+func (c *BaseCommController) QrCode(ctx echo.Context) (err error) {
+	file := ctx.Param("trade_id")
+	format := strings.ToLower(path.Ext(file))
+	if format != ".png" && format != ".svg" {
+		return ctx.NoContent(http.StatusNotFound)
+	}
+	content, err := service.GetOrderQrContent(strings.TrimSuffix(file, path.Ext(file)), ctx.QueryParam("content"))
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	size, _ := strconv.Atoi(ctx.QueryParam("size"))
+	size = qr.ClampSize(size)
+	level := qr.ParseLevel(ctx.QueryParam("level"))
+
+	// 订单金额和地址确定后二维码内容不再变化，允许浏览器短时间缓存
+	ctx.Response().Header().Set("Cache-Control", "private, max-age=60")
+	if format == ".svg" {
+		image, err := qr.SVG(content, level, size)
+		if err != nil {
+			return c.FailJson(ctx, err)
+		}
+		return ctx.Blob(http.StatusOK, "image/svg+xml", image)
+	}
+	image, err := qr.PNG(content, level, size)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return ctx.Blob(http.StatusOK, "image/png", image)
+}
+
+// SelectChain 开放订单选择支付网络
+func (c *BaseCommController) SelectChain(ctx echo.Context) (err error) {
+	tradeId := ctx.Param("trade_id")
+	resp, err := service.SelectOrderChain(tradeId, ctx.FormValue("chain_type"))
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// StatusStream 支付状态实时推送（Server-Sent Events），订单进入终态后关闭连接
+func (c *BaseCommController) StatusStream(ctx echo.Context) (err error) {
+	tradeId := ctx.Param("trade_id")
+	// 先订阅再查询，避免查询与订阅之间的状态变更丢失
+	events, cancel := event.Subscribe(tradeId)
+	defer cancel()
+	current, err := service.GetOrderStatusEvent(tradeId)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+
+	resp := ctx.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("Connection", "keep-alive")
+	// 关闭 Nginx 缓冲，保证事件即时送达
+	resp.Header().Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(http.StatusOK)
+
+	if err = writeStatusEvent(resp, current); err != nil || current.IsFinal() {
+		return nil
+	}
+
+	heartbeat := time.NewTicker(statusStreamHeartbeat)
+	defer heartbeat.Stop()
+	recheck := time.NewTicker(statusStreamRecheck)
+	defer recheck.Stop()
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+		case e := <-events:
+			current = e
+			if err = writeStatusEvent(resp, e); err != nil || e.IsFinal() {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err = fmt.Fprint(resp, ": ping\n\n"); err != nil {
+				return nil
+			}
+			resp.Flush()
+		case <-recheck.C:
+			e, err := service.GetOrderStatusEvent(tradeId)
+			// 只在状态变化时推送，避免覆盖 confirming 阶段
+			if err != nil || e.Status == current.Status {
+				continue
+			}
+			current = e
+			if err = writeStatusEvent(resp, e); err != nil || e.IsFinal() {
+				return nil
+			}
+		}
+	}
+}
+
+// writeStatusEvent 写入一条 status 事件并立即刷新
+func writeStatusEvent(resp *echo.Response, e event.OrderEvent) error {
+	payload, err := json.Cjson.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(resp, "event: status\ndata: %s\n\n", payload); err != nil {
+		return err
+	}
+	resp.Flush()
+	return nil
+}
@@ -0,0 +1,119 @@
+package comm
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/assimon/luuu/model/request"
+	"github.com/assimon/luuu/model/service"
+	"github.com/assimon/luuu/util/constant"
+	"github.com/assimon/luuu/view"
+	"github.com/labstack/echo/v4"
+)
+
+// PaymentLinkPage 支付链接页面
+func (c *BaseCommController) PaymentLinkPage(ctx echo.Context) (err error) {
+	resp, err := service.GetPaymentLinkPage(ctx.Param("slug"))
+	if err != nil {
+		return ctx.String(http.StatusOK, err.Error())
+	}
+	page := view.PaymentLinkPage{
+		PaymentLinkPageResponse: resp,
+		Locale:                  view.NewLocale(ctx.QueryParam("lang"), ctx.Request().Header.Get("Accept-Language")),
+	}
+	var buf bytes.Buffer
+	if err = view.Render(&buf, view.PaymentLinkTemplate, "", page); err != nil {
+		return ctx.String(http.StatusOK, err.Error())
+	}
+	return ctx.HTMLBlob(http.StatusOK, buf.Bytes())
+}
+
+// PayPaymentLink 付款人通过支付链接下单
+func (c *BaseCommController) PayPaymentLink(ctx echo.Context) (err error) {
+	amount, _ := strconv.ParseFloat(ctx.FormValue("amount"), 64)
+	resp, err := service.PayPaymentLink(ctx.Param("slug"), amount, ctx.FormValue("chain_type"))
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// CreatePaymentLink 创建支付链接
+func (c *BaseCommController) CreatePaymentLink(ctx echo.Context) (err error) {
+	req := new(request.CreatePaymentLinkRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.CreatePaymentLink(req)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// UpdatePaymentLink 修改支付链接
+func (c *BaseCommController) UpdatePaymentLink(ctx echo.Context) (err error) {
+	req := new(request.UpdatePaymentLinkRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.UpdatePaymentLink(req)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// DeletePaymentLink 删除支付链接
+func (c *BaseCommController) DeletePaymentLink(ctx echo.Context) (err error) {
+	req := new(request.PaymentLinkSlugRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	if err = service.DeletePaymentLink(req.Slug); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, nil)
+}
+
+// PaymentLinkDetail 支付链接详情
+func (c *BaseCommController) PaymentLinkDetail(ctx echo.Context) (err error) {
+	req := new(request.PaymentLinkSlugRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.GetPaymentLink(req.Slug)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// PaymentLinkList 支付链接列表
+func (c *BaseCommController) PaymentLinkList(ctx echo.Context) (err error) {
+	req := new(request.PaymentLinkListRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	list, pagination, err := service.ListPaymentLinks(req.Page, req.PageSize)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJsonPage(ctx, list, pagination)
+}
@@ -0,0 +1,87 @@
+package comm
+
+import (
+	"github.com/assimon/luuu/model/request"
+	"github.com/assimon/luuu/model/service"
+	"github.com/assimon/luuu/util/constant"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateRefund 创建退款
+func (c *BaseCommController) CreateRefund(ctx echo.Context) (err error) {
+	req := new(request.CreateRefundRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.CreateRefund(req)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// SubmitRefundTx 提交退款交易哈希
+func (c *BaseCommController) SubmitRefundTx(ctx echo.Context) (err error) {
+	req := new(request.SubmitRefundTxRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.SubmitRefundTx(req.RefundNo, req.TxHash)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// CancelRefund 取消退款
+func (c *BaseCommController) CancelRefund(ctx echo.Context) (err error) {
+	req := new(request.RefundNoRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	if err = service.CancelRefund(req.RefundNo); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, nil)
+}
+
+// RefundDetail 退款详情
+func (c *BaseCommController) RefundDetail(ctx echo.Context) (err error) {
+	req := new(request.RefundNoRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.GetRefund(req.RefundNo)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// RefundList 退款列表
+func (c *BaseCommController) RefundList(ctx echo.Context) (err error) {
+	req := new(request.RefundListRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	list, pagination, err := service.ListRefunds(req.TradeId, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJsonPage(ctx, list, pagination)
+}
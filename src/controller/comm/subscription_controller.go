@@ -0,0 +1,71 @@
+package comm
+
+import (
+	"github.com/assimon/luuu/model/request"
+	"github.com/assimon/luuu/model/service"
+	"github.com/assimon/luuu/util/constant"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateSubscription 创建订阅
+func (c *BaseCommController) CreateSubscription(ctx echo.Context) (err error) {
+	req := new(request.CreateSubscriptionRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.CreateSubscription(req)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// CancelSubscription 取消订阅
+func (c *BaseCommController) CancelSubscription(ctx echo.Context) (err error) {
+	req := new(request.SubscriptionNoRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	if err = service.CancelSubscription(req.SubscriptionNo); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, nil)
+}
+
+// SubscriptionDetail 订阅详情
+func (c *BaseCommController) SubscriptionDetail(ctx echo.Context) (err error) {
+	req := new(request.SubscriptionNoRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	resp, err := service.GetSubscription(req.SubscriptionNo)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJson(ctx, resp)
+}
+
+// SubscriptionList 订阅列表
+func (c *BaseCommController) SubscriptionList(ctx echo.Context) (err error) {
+	req := new(request.SubscriptionListRequest)
+	if err = ctx.Bind(req); err != nil {
+		return c.FailJson(ctx, constant.ParamsMarshalErr)
+	}
+	if err = c.ValidateStruct(ctx, req); err != nil {
+		return c.FailJson(ctx, err)
+	}
+	list, pagination, err := service.ListSubscriptions(req.CustomerId, req.Page, req.PageSize)
+	if err != nil {
+		return c.FailJson(ctx, err)
+	}
+	return c.SucJsonPage(ctx, list, pagination)
+}
@@ -0,0 +1,75 @@
+package event
+
+import (
+	"sync"
+)
+
+// 订单状态推送阶段
+const (
+	StageWaiting    = "waiting"    // 等待支付
+	StageConfirming = "confirming" // 已发现链上交易，正在确认
+	StagePaid       = "paid"       // 支付成功
+	StageExpired    = "expired"    // 已过期
+)
+
+// 每个订阅者的缓冲事件数，消费过慢时丢弃新事件，不阻塞发布方
+const subscriberBuffer = 8
+
+// OrderEvent 订单状态变更事件
+type OrderEvent struct {
+	TradeId string `json:"trade_id"` // epusdt订单号
+	Status  int    `json:"status"`   // 1=等待支付, 2=支付成功, 3=已过期
+	Stage   string `json:"stage"`    // waiting, confirming, paid, expired
+}
+
+// IsFinal 是否为终态（支付成功或已过期），终态后不会再有新事件
+func (e OrderEvent) IsFinal() bool {
+	return e.Stage == StagePaid || e.Stage == StageExpired
+}
+
+var (
+	mu          sync.RWMutex
+	subscribers = make(map[string]map[chan OrderEvent]struct{})
+)
+
+// Subscribe 订阅订单的状态变更，返回事件通道和取消订阅函数
+// 事件总线仅在进程内有效，多实例部署时订阅方需自行兜底查询订单状态
+func Subscribe(tradeId string) (<-chan OrderEvent, func()) {
+	ch := make(chan OrderEvent, subscriberBuffer)
+	mu.Lock()
+	if subscribers[tradeId] == nil {
+		subscribers[tradeId] = make(map[chan OrderEvent]struct{})
+	}
+	subscribers[tradeId][ch] = struct{}{}
+	mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			mu.Lock()
+			delete(subscribers[tradeId], ch)
+			if len(subscribers[tradeId]) == 0 {
+				delete(subscribers, tradeId)
+			}
+			mu.Unlock()
+		})
+	}
+	return ch, cancel
+}
+
+// Publish 向订单的所有订阅者推送事件，没有订阅者时直接返回
+func Publish(e OrderEvent) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for ch := range subscribers[e.TradeId] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// PublishStage 按阶段推送订单事件
+func PublishStage(tradeId string, status int, stage string) {
+	Publish(OrderEvent{TradeId: tradeId, Status: status, Stage: stage})
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/assimon/luuu/config"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// PayCors 收银台接口跨域，供自建前端和移动端内嵌页面调用
+func PayCors() echo.MiddlewareFunc {
+	return middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: config.GetPayCorsOrigins(),
+		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		MaxAge:       86400,
+	})
+}
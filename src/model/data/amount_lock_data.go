@@ -0,0 +1,107 @@
+package data
+
+import (
+	"errors"
+	"time"
+
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/go-sql-driver/mysql"
+)
+
+// IsDuplicateKeyError 判断是否为唯一索引冲突
+func IsDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+// ReserveAmountLock 原子预占 (钱包, 金额, 链标识) 支付金额，依赖唯一索引保证多实例下不会重复分配
+// 金额已被占用返回 false；占用记录已过期时先删除再重试一次
+func ReserveAmountLock(walletId uint64, tradeId string, amount float64, chainType string, expirationTime time.Duration) (bool, error) {
+	normalizedAmount := NormalizeAmount(amount, chainType)
+	// 使用 MySQL 服务器端计算过期时间，避免多实例时钟不一致
+	insert := `INSERT INTO amount_locks (wallet_id, chain_type, amount, trade_id, expires_at, created_at)
+			  VALUES (?, ?, ?, ?, DATE_ADD(NOW(), INTERVAL ? SECOND), CURRENT_TIMESTAMP)`
+	seconds := int(expirationTime.Seconds())
+
+	for attempt := 0; attempt < 2; attempt++ {
+		err := dao.Mdb.Exec(insert, walletId, chainType, normalizedAmount, tradeId, seconds).Error
+		if err == nil {
+			return true, nil
+		}
+		if !IsDuplicateKeyError(err) {
+			return false, err
+		}
+
+		// 清理该金额已过期的占用记录，未过期说明已被其他订单占用
+		result := dao.Mdb.Exec(`DELETE FROM amount_locks WHERE wallet_id = ? AND chain_type = ? AND amount = ? AND expires_at <= NOW()`,
+			walletId, chainType, normalizedAmount)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return false, nil
+		}
+	}
+	return false, nil
+}
+
+// GetTradeIdByWalletAddressAndAmountAndChainType 通过钱包地址、支付金额、链类型获取交易号
+func GetTradeIdByWalletAddressAndAmountAndChainType(token string, amount float64, chainType string) (string, error) {
+	lock := new(mdb.AmountLock)
+	err := dao.Mdb.Model(lock).Select("amount_locks.trade_id").
+		Joins("JOIN wallet_address ON wallet_address.id = amount_locks.wallet_id").
+		Where("wallet_address.token = ? AND amount_locks.chain_type = ? AND amount_locks.amount = ? AND amount_locks.expires_at > NOW()",
+			token, chainType, NormalizeAmount(amount, chainType)).
+		Limit(1).Find(lock).Error
+	return lock.TradeId, err
+}
+
+// UnLockTransactionByTradeId 释放订单占用的支付金额
+func UnLockTransactionByTradeId(tradeId string) error {
+	return dao.Mdb.Where("trade_id = ?", tradeId).Delete(&mdb.AmountLock{}).Error
+}
+
+// ReleaseAmountLock 释放订单预占的指定金额锁，不影响同一订单的其他金额锁
+func ReleaseAmountLock(walletId uint64, tradeId string, amount float64, chainType string) error {
+	return dao.Mdb.Where("wallet_id = ? AND chain_type = ? AND amount = ? AND trade_id = ?",
+		walletId, chainType, NormalizeAmount(amount, chainType), tradeId).Delete(&mdb.AmountLock{}).Error
+}
+
+// GetLockedAmountsByChainType 一次查询指定链标识下所有未过期的金额锁，返回 钱包ID => 已锁定金额集合
+// chainType 为 GetLockChainKey 生成的链标识，金额为 NormalizeAmount 规范化后的字符串
+func GetLockedAmountsByChainType(chainType string) (map[uint64]map[string]bool, error) {
+	var locks []mdb.AmountLock
+	err := dao.Mdb.Model(&mdb.AmountLock{}).Select("wallet_id, amount").
+		Where("chain_type = ? AND expires_at > NOW()", chainType).
+		Find(&locks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(map[uint64]map[string]bool)
+	for _, lock := range locks {
+		if locked[lock.WalletId] == nil {
+			locked[lock.WalletId] = make(map[string]bool)
+		}
+		locked[lock.WalletId][lock.Amount] = true
+	}
+	return locked, nil
+}
+
+// HasPendingOrderByAddress 检查指定地址是否有待支付订单（通过金额锁检查）
+// chainType 可传入 GetLockChainKey 生成的原生币链标识，用于检查原生币待支付订单
+func HasPendingOrderByAddress(token string, chainType string) (bool, error) {
+	var count int64
+	err := dao.Mdb.Model(&mdb.AmountLock{}).
+		Joins("JOIN wallet_address ON wallet_address.id = amount_locks.wallet_id").
+		Where("wallet_address.token = ? AND amount_locks.chain_type = ? AND amount_locks.expires_at > NOW()", token, chainType).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CleanExpiredAmountLocks 清理过期的金额锁
+func CleanExpiredAmountLocks() (int64, error) {
+	result := dao.Mdb.Exec(`DELETE FROM amount_locks WHERE expires_at <= NOW()`)
+	return result.RowsAffected, result.Error
+}
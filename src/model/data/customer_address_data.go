@@ -0,0 +1,111 @@
+package data
+
+import (
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/golang-module/carbon/v2"
+	"gorm.io/gorm"
+)
+
+// 地址池分配时并发抢占失败的最大重试次数
+const assignPoolCustomerAddressRetry = 3
+
+// CreateCustomerAddress 创建客户充值地址
+func CreateCustomerAddress(address *mdb.CustomerAddress) error {
+	return dao.Mdb.Create(address).Error
+}
+
+// GetCustomerAddressByCustomerId 获取客户在指定链上的充值地址，不存在时返回空记录
+func GetCustomerAddressByCustomerId(customerId string, chainType string) (*mdb.CustomerAddress, error) {
+	address := new(mdb.CustomerAddress)
+	err := dao.Mdb.Model(address).Where("customer_id = ? AND chain_type = ?", customerId, chainType).
+		Order("id ASC").Limit(1).Find(address).Error
+	return address, err
+}
+
+// GetCustomerAddressesByCustomerId 获取客户在所有链上的充值地址
+func GetCustomerAddressesByCustomerId(customerId string) ([]mdb.CustomerAddress, error) {
+	var addresses []mdb.CustomerAddress
+	err := dao.Mdb.Model(&mdb.CustomerAddress{}).Where("customer_id = ?", customerId).
+		Order("id ASC").Find(&addresses).Error
+	return addresses, err
+}
+
+// GetCustomerAddressByTokenAndChainType 通过地址和链类型获取客户充值地址，不存在时返回空记录
+func GetCustomerAddressByTokenAndChainType(token string, chainType string) (*mdb.CustomerAddress, error) {
+	address := new(mdb.CustomerAddress)
+	err := dao.Mdb.Model(address).Limit(1).Find(address, "token = ? AND chain_type = ?", token, chainType).Error
+	return address, err
+}
+
+// GetMonitoredCustomerAddresses 获取指定链上已分配且启用的客户充值地址，需持续监听
+func GetMonitoredCustomerAddresses(chainType string) ([]mdb.CustomerAddress, error) {
+	var addresses []mdb.CustomerAddress
+	err := dao.Mdb.Model(&mdb.CustomerAddress{}).
+		Where("status = ? AND chain_type = ? AND customer_id <> ''", mdb.CustomerAddressStatusEnable, chainType).
+		Find(&addresses).Error
+	return addresses, err
+}
+
+// AssignPoolCustomerAddress 从地址池中分配一个未使用的地址给客户，地址池为空时返回空记录
+func AssignPoolCustomerAddress(customerId string, chainType string, notifyUrl string) (*mdb.CustomerAddress, error) {
+	for i := 0; i < assignPoolCustomerAddressRetry; i++ {
+		address := new(mdb.CustomerAddress)
+		err := dao.Mdb.Model(address).
+			Where("chain_type = ? AND customer_id = '' AND status = ?", chainType, mdb.CustomerAddressStatusEnable).
+			Order("id ASC").Limit(1).Find(address).Error
+		if err != nil || address.ID == 0 {
+			return address, err
+		}
+		assignedAt := carbon.Now()
+		// 以 customer_id 为空作为条件抢占，避免并发时同一地址分配给多个客户
+		result := dao.Mdb.Model(&mdb.CustomerAddress{}).
+			Where("id = ? AND customer_id = ''", address.ID).
+			Updates(map[string]interface{}{
+				"customer_id": customerId,
+				"notify_url":  notifyUrl,
+				"assigned_at": assignedAt.ToDateTimeString(),
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		address.CustomerId = customerId
+		address.NotifyUrl = notifyUrl
+		address.AssignedAt = &carbon.Time{Carbon: assignedAt}
+		return address, nil
+	}
+	return new(mdb.CustomerAddress), nil
+}
+
+// UpdateCustomerAddressNotifyUrl 更新客户充值地址的回调地址
+func UpdateCustomerAddressNotifyUrl(id uint64, notifyUrl string) error {
+	return dao.Mdb.Model(&mdb.CustomerAddress{}).Where("id = ?", id).Update("notify_url", notifyUrl).Error
+}
+
+// CreateCustomerDeposit 创建充值记录，同一交易重复入账时返回唯一索引冲突
+func CreateCustomerDeposit(deposit *mdb.CustomerDeposit) error {
+	return dao.Mdb.Create(deposit).Error
+}
+
+// SaveCallBackDepositResp 保存充值回调结果
+func SaveCallBackDepositResp(deposit *mdb.CustomerDeposit) error {
+	return dao.Mdb.Model(deposit).Where("id = ?", deposit.ID).Updates(map[string]interface{}{
+		"callback_num":     gorm.Expr("callback_num + ?", 1),
+		"callback_confirm": deposit.CallBackConfirm,
+	}).Error
+}
+
+// GetCustomerDeposits 分页获取客户充值记录，按创建时间倒序
+func GetCustomerDeposits(customerId string, page int, pageSize int) ([]mdb.CustomerDeposit, int64, error) {
+	var deposits []mdb.CustomerDeposit
+	var total int64
+	query := dao.Mdb.Model(&mdb.CustomerDeposit{}).Where("customer_id = ?", customerId)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&deposits).Error
+	return deposits, total, err
+}
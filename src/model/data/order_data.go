@@ -1,25 +1,72 @@
 package data
 
 import (
-	"context"
-	"errors"
-	"fmt"
-	"time"
+	"strings"
 
+	"github.com/assimon/luuu/config"
 	"github.com/assimon/luuu/model/dao"
 	"github.com/assimon/luuu/model/mdb"
 	"github.com/assimon/luuu/model/request"
+	"github.com/golang-module/carbon/v2"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
-var (
-	CacheWalletAddressWithAmountToTradeIdKey = "wallet:%s_%s_%s" // 钱包_待支付金额_链类型 : 交易号
-)
+// nativeAmountDecimals 原生币金额锁定保留的小数位数，不超过链上最小单位（TRX 为6位）和订单金额字段的8位
+var nativeAmountDecimals = map[string]int32{
+	mdb.AssetTRX: 6,
+	mdb.AssetETH: 8,
+	mdb.AssetBNB: 8,
+	mdb.AssetSOL: 8,
+	mdb.AssetPOL: 8,
+}
+
+// GetAmountDecimals 获取金额锁定保留的小数位数，chainKey 为 GetLockChainKey 生成的链标识，稳定币4位，原生币按币种
+func GetAmountDecimals(chainKey string) int32 {
+	if index := strings.Index(chainKey, ":"); index >= 0 {
+		if decimals, ok := nativeAmountDecimals[chainKey[index+1:]]; ok {
+			return decimals
+		}
+	}
+	return 4
+}
+
+// NormalizeAmount 规范化金额，按链标识的精度保留小数，避免12.31和12.3100不匹配的问题
+func NormalizeAmount(amount float64, chainKey string) string {
+	return decimal.NewFromFloat(amount).StringFixed(GetAmountDecimals(chainKey))
+}
+
+// GetLockChainKey 获取金额锁定使用的链标识，稳定币沿用链类型，原生币为 链类型:币种
+func GetLockChainKey(chainType string, asset string) string {
+	if asset == "" || asset == mdb.AssetUSDT {
+		return chainType
+	}
+	return chainType + ":" + asset
+}
+
+// GetOrderExpirationTime 获取订单过期时间
+// 未选择链的开放订单按 open_order_expiration_time 计算，已选择链的开放订单从选择时间开始计算
+func GetOrderExpirationTime(order *mdb.Orders) carbon.Carbon {
+	if order.ChainType == "" {
+		return order.CreatedAt.AddMinutes(config.GetOpenOrderExpirationTime())
+	}
+	if order.SelectedAt != nil {
+		return order.SelectedAt.AddMinutes(config.GetOrderExpirationTime())
+	}
+	return order.CreatedAt.AddMinutes(config.GetOrderExpirationTime())
+}
 
-// normalizeAmount 规范化金额，统一保留4位小数，避免12.31和12.3100不匹配的问题
-func normalizeAmount(amount float64) string {
-	return decimal.NewFromFloat(amount).StringFixed(4)
+// SelectOrderChain 开放订单选择链，只更新尚未选择链的待支付订单，返回是否更新成功
+func SelectOrderChain(id uint64, chainType string, token string, actualAmount float64) (bool, error) {
+	result := dao.Mdb.Model(&mdb.Orders{}).
+		Where("id = ? AND chain_type = '' AND status = ?", id, mdb.StatusWaitPay).
+		Updates(map[string]interface{}{
+			"chain_type":    chainType,
+			"token":         token,
+			"actual_amount": actualAmount,
+			"selected_at":   carbon.Now().ToDateTimeString(),
+		})
+	return result.RowsAffected > 0, result.Error
 }
 
 // GetOrderInfoByOrderId 通过客户订单号查询订单
@@ -51,11 +98,17 @@ func GetOrderByBlockIdWithTransaction(tx *gorm.DB, blockId string) (*mdb.Orders,
 
 // OrderSuccessWithTransaction 事务支付成功
 func OrderSuccessWithTransaction(tx *gorm.DB, req *request.OrderProcessingRequest) error {
-	err := tx.Model(&mdb.Orders{}).Where("trade_id = ?", req.TradeId).Updates(map[string]interface{}{
+	updates := map[string]interface{}{
 		"block_transaction_id": req.BlockTransactionId,
 		"status":               mdb.StatusPaySuccess,
 		"callback_confirm":     mdb.CallBackConfirmNo,
-	}).Error
+	}
+	// 开放金额订单按实际到账金额结算
+	if req.SettleAmount > 0 {
+		updates["amount"] = req.SettleAmount
+		updates["actual_amount"] = req.Amount
+	}
+	err := tx.Model(&mdb.Orders{}).Where("trade_id = ?", req.TradeId).Updates(updates).Error
 	return err
 }
 
@@ -90,57 +143,3 @@ func DeleteOrderById(id uint64) error {
 	err := dao.Mdb.Where("id = ?", id).Delete(&mdb.Orders{}).Error
 	return err
 }
-
-// GetTradeIdByWalletAddressAndAmountAndChainType 通过钱包地址、支付金额、链类型获取交易号
-func GetTradeIdByWalletAddressAndAmountAndChainType(token string, amount float64, chainType string) (string, error) {
-	ctx := context.Background()
-	normalizedAmount := normalizeAmount(amount)
-	cacheKey := fmt.Sprintf(CacheWalletAddressWithAmountToTradeIdKey, token, normalizedAmount, chainType)
-	result, err := dao.CacheGet(ctx, cacheKey)
-	if errors.Is(err, dao.ErrCacheNotFound) {
-		return "", nil
-	}
-	if err != nil {
-		return "", err
-	}
-	return result, nil
-}
-
-// LockTransactionWithChainType 锁定交易（支持链类型）
-func LockTransactionWithChainType(token, tradeId string, amount float64, chainType string, expirationTime time.Duration) error {
-	ctx := context.Background()
-	normalizedAmount := normalizeAmount(amount)
-	cacheKey := fmt.Sprintf(CacheWalletAddressWithAmountToTradeIdKey, token, normalizedAmount, chainType)
-	err := dao.CacheSet(ctx, cacheKey, tradeId, expirationTime)
-	return err
-}
-
-// UnLockTransactionWithChainType 解锁交易（支持链类型）
-func UnLockTransactionWithChainType(token string, amount float64, chainType string) error {
-	ctx := context.Background()
-	normalizedAmount := normalizeAmount(amount)
-	cacheKey := fmt.Sprintf(CacheWalletAddressWithAmountToTradeIdKey, token, normalizedAmount, chainType)
-	err := dao.CacheDel(ctx, cacheKey)
-	return err
-}
-
-// HasPendingOrderByAddress 检查指定地址是否有待支付订单（通过缓存检查）
-func HasPendingOrderByAddress(token string, chainType string) (bool, error) {
-	ctx := context.Background()
-	// 缓存 key 格式: wallet:地址_金额_链类型
-	// 我们需要查询缓存表中是否有该地址的待支付订单
-	cacheKeyPrefix := fmt.Sprintf("wallet:%s_", token)
-
-	// 使用数据库查询缓存表，检查是否存在以该前缀开头且包含链类型的key
-	var count int64
-	query := `SELECT COUNT(*) FROM cache 
-			  WHERE cache_key LIKE ? 
-			  AND cache_key LIKE ?
-			  AND (expires_at IS NULL OR expires_at > NOW())`
-	err := dao.Mdb.WithContext(ctx).Raw(query, cacheKeyPrefix+"%", "%_"+chainType).Row().Scan(&count)
-	if err != nil {
-		return false, err
-	}
-
-	return count > 0, nil
-}
@@ -0,0 +1,60 @@
+package data
+
+import (
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/mdb"
+	"gorm.io/gorm"
+)
+
+// CreatePaymentLink 创建支付链接
+func CreatePaymentLink(link *mdb.PaymentLink) error {
+	return dao.Mdb.Create(link).Error
+}
+
+// GetPaymentLinkBySlug 通过标识获取支付链接，不存在时返回空记录
+func GetPaymentLinkBySlug(slug string) (*mdb.PaymentLink, error) {
+	link := new(mdb.PaymentLink)
+	err := dao.Mdb.Model(link).Limit(1).Find(link, "slug = ?", slug).Error
+	return link, err
+}
+
+// GetPaymentLinkById 通过ID获取支付链接，不存在时返回空记录
+func GetPaymentLinkById(id uint64) (*mdb.PaymentLink, error) {
+	link := new(mdb.PaymentLink)
+	err := dao.Mdb.Model(link).Limit(1).Find(link, id).Error
+	return link, err
+}
+
+// GetPaymentLinks 分页获取支付链接，按创建时间倒序
+func GetPaymentLinks(page int, pageSize int) ([]mdb.PaymentLink, int64, error) {
+	var links []mdb.PaymentLink
+	var total int64
+	query := dao.Mdb.Model(&mdb.PaymentLink{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&links).Error
+	return links, total, err
+}
+
+// UpdatePaymentLink 保存支付链接的可修改字段
+func UpdatePaymentLink(link *mdb.PaymentLink) error {
+	return dao.Mdb.Model(link).Select("title", "description", "max_uses", "expires_at", "status", "notify_url", "redirect_url").
+		Updates(link).Error
+}
+
+// ChangePaymentLinkStatus 启用或禁用支付链接
+func ChangePaymentLinkStatus(id uint64, status int) error {
+	return dao.Mdb.Model(&mdb.PaymentLink{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// DeletePaymentLinkById 删除支付链接，已生成的订单不受影响
+func DeletePaymentLinkById(id uint64) error {
+	return dao.Mdb.Where("id = ?", id).Delete(&mdb.PaymentLink{}).Error
+}
+
+// IncrPaymentLinkUseCount 支付链接的订单支付成功后累加已支付次数
+func IncrPaymentLinkUseCount(id uint64) error {
+	return dao.Mdb.Model(&mdb.PaymentLink{}).Where("id = ?", id).
+		UpdateColumn("use_count", gorm.Expr("use_count + 1")).Error
+}
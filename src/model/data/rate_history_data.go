@@ -0,0 +1,32 @@
+package data
+
+import (
+	"time"
+
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/mdb"
+)
+
+// AddRateHistory 记录汇率历史
+func AddRateHistory(currency string, rate float64, source string) error {
+	history := &mdb.RateHistory{
+		Currency:  currency,
+		Rate:      rate,
+		Source:    source,
+		CreatedAt: time.Now(),
+	}
+	return dao.Mdb.Create(history).Error
+}
+
+// GetLatestRateHistory 获取法币最近一次的汇率，不存在时返回空记录
+func GetLatestRateHistory(currency string) (*mdb.RateHistory, error) {
+	history := new(mdb.RateHistory)
+	err := dao.Mdb.Model(history).Where("currency = ?", currency).Order("id DESC").Limit(1).Find(history).Error
+	return history, err
+}
+
+// CleanRateHistory 清理指定天数之前的汇率历史
+func CleanRateHistory(days int) (int64, error) {
+	result := dao.Mdb.Exec(`DELETE FROM rate_history WHERE created_at < DATE_SUB(NOW(), INTERVAL ? DAY)`, days)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,135 @@
+package data
+
+import (
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/golang-module/carbon/v2"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateRefundWithinAmount 锁定订单后创建退款记录，未取消的退款总额超过 paidAmount 时不创建并返回 false
+func CreateRefundWithinAmount(refund *mdb.Refund, paidAmount float64) (bool, error) {
+	created := false
+	err := dao.Mdb.Transaction(func(tx *gorm.DB) error {
+		// 锁定订单行，避免并发创建的退款总额超出支付金额
+		order := new(mdb.Orders)
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(order).
+			Where("trade_id = ?", refund.TradeId).Limit(1).Find(order).Error
+		if err != nil {
+			return err
+		}
+		var refunded float64
+		err = tx.Model(&mdb.Refund{}).
+			Where("trade_id = ? AND status <> ?", refund.TradeId, mdb.RefundStatusCancelled).
+			Select("COALESCE(SUM(amount), 0)").Scan(&refunded).Error
+		if err != nil {
+			return err
+		}
+		total := decimal.NewFromFloat(refunded).Add(decimal.NewFromFloat(refund.Amount)).Round(4)
+		if total.GreaterThan(decimal.NewFromFloat(paidAmount).Round(4)) {
+			return nil
+		}
+		if err = tx.Create(refund).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// GetRefundByNo 通过退款编号获取退款，不存在时返回空记录
+func GetRefundByNo(refundNo string) (*mdb.Refund, error) {
+	refund := new(mdb.Refund)
+	err := dao.Mdb.Model(refund).Limit(1).Find(refund, "refund_no = ?", refundNo).Error
+	return refund, err
+}
+
+// GetRefundByTxHash 获取使用了该交易哈希且未取消的退款，不存在时返回空记录
+func GetRefundByTxHash(chainType string, txHash string) (*mdb.Refund, error) {
+	refund := new(mdb.Refund)
+	err := dao.Mdb.Model(refund).Limit(1).
+		Find(refund, "chain_type = ? AND tx_hash = ? AND status <> ?", chainType, txHash, mdb.RefundStatusCancelled).Error
+	return refund, err
+}
+
+// GetRefunds 分页获取退款，tradeId 不为空时只查询该订单的退款，status 大于0时按状态筛选，按创建时间倒序
+func GetRefunds(tradeId string, status int, page int, pageSize int) ([]mdb.Refund, int64, error) {
+	var refunds []mdb.Refund
+	var total int64
+	query := dao.Mdb.Model(&mdb.Refund{})
+	if tradeId != "" {
+		query = query.Where("trade_id = ?", tradeId)
+	}
+	if status > 0 {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&refunds).Error
+	return refunds, total, err
+}
+
+// GetVerifyingRefunds 获取等待链上核验的退款
+func GetVerifyingRefunds(limit int) ([]mdb.Refund, error) {
+	var refunds []mdb.Refund
+	err := dao.Mdb.Model(&mdb.Refund{}).Where("status = ?", mdb.RefundStatusVerifying).
+		Order("id ASC").Limit(limit).Find(&refunds).Error
+	return refunds, err
+}
+
+// SubmitRefundTxHash 提交退款交易哈希，只更新待退款或核验失败的退款，返回是否更新成功
+// 交易哈希已被其他未取消的退款使用时由 idx_refunds_active_tx_hash 唯一索引拦截，返回唯一索引冲突错误
+func SubmitRefundTxHash(id uint64, txHash string) (bool, error) {
+	result := dao.Mdb.Model(&mdb.Refund{}).
+		Where("id = ? AND status IN ?", id, []int{mdb.RefundStatusPending, mdb.RefundStatusFailed}).
+		Updates(map[string]interface{}{
+			"status":       mdb.RefundStatusVerifying,
+			"tx_hash":      txHash,
+			"fail_reason":  "",
+			"submitted_at": carbon.Now().ToDateTimeString(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CompleteRefund 退款交易核验通过，以交易哈希为条件避免覆盖重新提交的哈希
+func CompleteRefund(id uint64, txHash string, fromAddress string) (bool, error) {
+	result := dao.Mdb.Model(&mdb.Refund{}).
+		Where("id = ? AND status = ? AND tx_hash = ?", id, mdb.RefundStatusVerifying, txHash).
+		Updates(map[string]interface{}{
+			"status":       mdb.RefundStatusCompleted,
+			"from_address": fromAddress,
+			"completed_at": carbon.Now().ToDateTimeString(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FailRefund 退款交易核验失败，以交易哈希为条件避免覆盖重新提交的哈希
+func FailRefund(id uint64, txHash string, failReason string) (bool, error) {
+	result := dao.Mdb.Model(&mdb.Refund{}).
+		Where("id = ? AND status = ? AND tx_hash = ?", id, mdb.RefundStatusVerifying, txHash).
+		Updates(map[string]interface{}{
+			"status":      mdb.RefundStatusFailed,
+			"fail_reason": failReason,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CancelRefundById 取消待退款或核验失败的退款，返回是否取消成功
+func CancelRefundById(id uint64) (bool, error) {
+	result := dao.Mdb.Model(&mdb.Refund{}).
+		Where("id = ? AND status IN ?", id, []int{mdb.RefundStatusPending, mdb.RefundStatusFailed}).
+		Update("status", mdb.RefundStatusCancelled)
+	return result.RowsAffected > 0, result.Error
+}
+
+// SaveCallBackRefundResp 保存退款回调结果
+func SaveCallBackRefundResp(refund *mdb.Refund) error {
+	return dao.Mdb.Model(refund).Where("id = ?", refund.ID).Updates(map[string]interface{}{
+		"callback_num":     gorm.Expr("callback_num + ?", 1),
+		"callback_confirm": refund.CallBackConfirm,
+	}).Error
+}
@@ -0,0 +1,24 @@
+package data
+
+import (
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/mdb"
+)
+
+// GetScanCursor 获取扫描游标，不存在时返回空游标
+func GetScanCursor(chainType string, address string, scope string) (*mdb.ScanCursor, error) {
+	cursor := new(mdb.ScanCursor)
+	err := dao.Mdb.Model(cursor).Limit(1).Find(cursor, "chain_type = ? AND address = ? AND scope = ?", chainType, address, scope).Error
+	return cursor, err
+}
+
+// SaveScanCursor 保存扫描游标
+func SaveScanCursor(chainType string, address string, scope string, cursor string, lastTimestamp int64) error {
+	query := `INSERT INTO scan_cursor (chain_type, address, scope, cursor_value, last_timestamp, created_at, updated_at)
+			 VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+			 ON DUPLICATE KEY UPDATE
+			 cursor_value = VALUES(cursor_value),
+			 last_timestamp = VALUES(last_timestamp),
+			 updated_at = CURRENT_TIMESTAMP`
+	return dao.Mdb.Exec(query, chainType, address, scope, cursor, lastTimestamp).Error
+}
@@ -0,0 +1,66 @@
+package data
+
+import (
+	"os"
+	"testing"
+
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/mdb"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// 需要已执行 sql/mysql.sql 的测试库，例如:
+// EPUSDT_TEST_MYSQL_DSN="root:root@tcp(127.0.0.1:3306)/epusdt_test?charset=utf8mb4&parseTime=True&loc=Local"
+func openTestMdb(t *testing.T) {
+	dsn := os.Getenv("EPUSDT_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("未设置 EPUSDT_TEST_MYSQL_DSN，跳过数据库测试")
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+		Logger:         logger.Default.LogMode(logger.Error),
+	})
+	if err != nil {
+		t.Fatalf("连接测试库失败: %v", err)
+	}
+	dao.Mdb = db
+}
+
+func TestScanCursorRoundTrip(t *testing.T) {
+	openTestMdb(t)
+
+	const chainType, address = "TEST", "test-address"
+	t.Cleanup(func() {
+		dao.Mdb.Unscoped().Where("chain_type = ? AND address = ?", chainType, address).Delete(&mdb.ScanCursor{})
+	})
+
+	for _, want := range []struct {
+		cursor    string
+		timestamp int64
+	}{
+		{"100", 1000},
+		{"200", 2000}, // 第二次保存覆盖已有游标
+	} {
+		if err := SaveScanCursor(chainType, address, mdb.ScanScopeToken, want.cursor, want.timestamp); err != nil {
+			t.Fatalf("保存游标失败: %v", err)
+		}
+		got, err := GetScanCursor(chainType, address, mdb.ScanScopeToken)
+		if err != nil {
+			t.Fatalf("获取游标失败: %v", err)
+		}
+		if got.Cursor != want.cursor || got.LastTimestamp != want.timestamp {
+			t.Fatalf("游标不一致: got (%q, %d), want (%q, %d)", got.Cursor, got.LastTimestamp, want.cursor, want.timestamp)
+		}
+	}
+
+	other, err := GetScanCursor(chainType, address, mdb.ScanScopeNative)
+	if err != nil {
+		t.Fatalf("获取游标失败: %v", err)
+	}
+	if other.ID != 0 || other.Cursor != "" {
+		t.Fatalf("不同扫描范围的游标应为空: %+v", other)
+	}
+}
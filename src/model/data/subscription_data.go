@@ -0,0 +1,102 @@
+package data
+
+import (
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/golang-module/carbon/v2"
+	"gorm.io/gorm"
+)
+
+// CreateSubscription 创建订阅
+func CreateSubscription(subscription *mdb.Subscription) error {
+	return dao.Mdb.Create(subscription).Error
+}
+
+// GetSubscriptionByNo 通过订阅编号获取订阅，不存在时返回空记录
+func GetSubscriptionByNo(subscriptionNo string) (*mdb.Subscription, error) {
+	subscription := new(mdb.Subscription)
+	err := dao.Mdb.Model(subscription).Limit(1).Find(subscription, "subscription_no = ?", subscriptionNo).Error
+	return subscription, err
+}
+
+// GetSubscriptionById 通过ID获取订阅，不存在时返回空记录
+func GetSubscriptionById(id uint64) (*mdb.Subscription, error) {
+	subscription := new(mdb.Subscription)
+	err := dao.Mdb.Model(subscription).Limit(1).Find(subscription, id).Error
+	return subscription, err
+}
+
+// GetSubscriptions 分页获取订阅，customerId 不为空时只查询该客户的订阅，按创建时间倒序
+func GetSubscriptions(customerId string, page int, pageSize int) ([]mdb.Subscription, int64, error) {
+	var subscriptions []mdb.Subscription
+	var total int64
+	query := dao.Mdb.Model(&mdb.Subscription{})
+	if customerId != "" {
+		query = query.Where("customer_id = ?", customerId)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&subscriptions).Error
+	return subscriptions, total, err
+}
+
+// GetDueSubscriptions 获取到达处理时间的正常和逾期订阅
+func GetDueSubscriptions(limit int) ([]mdb.Subscription, error) {
+	var subscriptions []mdb.Subscription
+	err := dao.Mdb.Model(&mdb.Subscription{}).
+		Where("status IN ? AND next_run_at <= ?",
+			[]int{mdb.SubscriptionStatusActive, mdb.SubscriptionStatusPastDue}, carbon.Now().ToDateTimeString()).
+		Order("next_run_at ASC").Limit(limit).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// SetSubscriptionInvoice 记录当前账期生成的账单订单，以 current_trade_id 为空作为条件避免多实例重复记录
+// 下次处理时间推迟到账单过期时间，等待支付期间不再被每轮重复查询
+func SetSubscriptionInvoice(id uint64, tradeId string, expiresAt carbon.Carbon) (bool, error) {
+	result := dao.Mdb.Model(&mdb.Subscription{}).
+		Where("id = ? AND current_trade_id = ''", id).
+		Updates(map[string]interface{}{
+			"current_trade_id": tradeId,
+			"next_run_at":      expiresAt.ToDateTimeString(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateSubscriptionByInvoice 以当前账单订单号为条件更新订阅，账单已被其他实例处理时返回 false
+func UpdateSubscriptionByInvoice(id uint64, tradeId string, updates map[string]interface{}) (bool, error) {
+	result := dao.Mdb.Model(&mdb.Subscription{}).
+		Where("id = ? AND current_trade_id = ? AND status <> ?", id, tradeId, mdb.SubscriptionStatusCancelled).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateSubscriptionNextRunAt 推迟订阅的下次处理时间
+func UpdateSubscriptionNextRunAt(id uint64, nextRunAt carbon.Carbon) error {
+	return dao.Mdb.Model(&mdb.Subscription{}).Where("id = ?", id).
+		Update("next_run_at", nextRunAt.ToDateTimeString()).Error
+}
+
+// CancelSubscriptionById 取消订阅，已取消的订阅返回 false
+func CancelSubscriptionById(id uint64) (bool, error) {
+	result := dao.Mdb.Model(&mdb.Subscription{}).
+		Where("id = ? AND status <> ?", id, mdb.SubscriptionStatusCancelled).
+		Updates(map[string]interface{}{
+			"status":           mdb.SubscriptionStatusCancelled,
+			"current_trade_id": "",
+			"cancelled_at":     carbon.Now().ToDateTimeString(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// IncrSubscriptionPaidPeriods 账单支付成功后进入下一账期
+func IncrSubscriptionPaidPeriods(id uint64, tradeId string, dueAt carbon.Carbon) (bool, error) {
+	return UpdateSubscriptionByInvoice(id, tradeId, map[string]interface{}{
+		"status":           mdb.SubscriptionStatusActive,
+		"paid_periods":     gorm.Expr("paid_periods + 1"),
+		"retry_count":      0,
+		"current_trade_id": "",
+		"due_at":           dueAt.ToDateTimeString(),
+		"next_run_at":      dueAt.ToDateTimeString(),
+	})
+}
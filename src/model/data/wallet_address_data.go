@@ -1,10 +1,13 @@
 package data
 
 import (
+	"fmt"
+
 	"github.com/assimon/luuu/model/dao"
 	"github.com/assimon/luuu/model/mdb"
 	"github.com/assimon/luuu/util/constant"
 	"github.com/golang-module/carbon/v2"
+	"gorm.io/gorm"
 )
 
 // AddWalletAddress 创建钱包
@@ -16,6 +19,14 @@ func AddWalletAddress(token string, chainType string, remark string) (*mdb.Walle
 	if exist.ID > 0 {
 		return nil, constant.WalletAddressAlreadyExists
 	}
+	// 客户充值地址的转账按充值处理，不能同时作为收款池钱包
+	customerAddress, err := GetCustomerAddressByTokenAndChainType(token, chainType)
+	if err != nil {
+		return nil, err
+	}
+	if customerAddress.ID > 0 {
+		return nil, constant.WalletAddressAlreadyExists
+	}
 	walletAddress := &mdb.WalletAddress{
 		Token:     token,
 		ChainType: chainType,
@@ -47,6 +58,81 @@ func GetAvailableWalletAddressByChainType(chainType string) ([]mdb.WalletAddress
 	return WalletAddressList, err
 }
 
+// GetAvailablePoolWalletAddressByChainType 获得指定链类型的可用收款池钱包，不含订单派生地址
+func GetAvailablePoolWalletAddressByChainType(chainType string) ([]mdb.WalletAddress, error) {
+	var WalletAddressList []mdb.WalletAddress
+	err := dao.Mdb.Model(WalletAddressList).Where("status = ? AND chain_type = ? AND trade_id = ''", mdb.TokenStatusEnable, chainType).Find(&WalletAddressList).Error
+	return WalletAddressList, err
+}
+
+// AddDerivedWalletAddress 登记订单派生的收款地址（只读地址，服务端不持有私钥）
+func AddDerivedWalletAddress(token string, chainType string, tradeId string, index uint32) (*mdb.WalletAddress, error) {
+	walletAddress := &mdb.WalletAddress{
+		Token:           token,
+		ChainType:       chainType,
+		Remark:          "订单 " + tradeId,
+		Status:          mdb.TokenStatusEnable,
+		TradeId:         tradeId,
+		DerivationIndex: &index,
+	}
+	err := dao.Mdb.Create(walletAddress).Error
+	return walletAddress, err
+}
+
+// AllocateDerivationIndex 原子分配指定链类型的下一个派生地址索引，订单派生地址与客户充值地址共用计数器
+// 分配后的索引即使未使用也不会再次分配，避免并发派生出相同地址
+func AllocateDerivationIndex(chainType string) (uint32, error) {
+	counter := new(mdb.DerivationCounter)
+	err := dao.Mdb.Model(counter).Limit(1).Find(counter, "chain_type = ?", chainType).Error
+	if err != nil {
+		return 0, err
+	}
+	if counter.ChainType == "" {
+		// 首次分配时按两表已使用的最大索引初始化计数器，并发初始化时只有一个生效
+		err = dao.Mdb.Exec(`INSERT IGNORE INTO derivation_counters (chain_type, next_index, updated_at)
+			SELECT ?, GREATEST(
+				COALESCE((SELECT MAX(derivation_index) + 1 FROM wallet_address WHERE chain_type = ?), 0),
+				COALESCE((SELECT MAX(derivation_index) + 1 FROM customer_addresses WHERE chain_type = ?), 0)
+			), CURRENT_TIMESTAMP`, chainType, chainType, chainType).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var index uint32
+	err = dao.Mdb.Transaction(func(tx *gorm.DB) error {
+		// 先更新再读取，行锁保证并发分配的索引不重复
+		result := tx.Exec(`UPDATE derivation_counters SET next_index = next_index + 1, updated_at = CURRENT_TIMESTAMP WHERE chain_type = ?`, chainType)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("[%s] 派生索引计数器不存在", chainType)
+		}
+		updated := new(mdb.DerivationCounter)
+		if err := tx.Model(updated).Limit(1).Find(updated, "chain_type = ?", chainType).Error; err != nil {
+			return err
+		}
+		index = updated.NextIndex - 1
+		return nil
+	})
+	return index, err
+}
+
+// GetDerivedWalletAddress 通过派生地址获取关联的钱包记录
+func GetDerivedWalletAddress(token string, chainType string) (*mdb.WalletAddress, error) {
+	walletAddress := new(mdb.WalletAddress)
+	err := dao.Mdb.Model(walletAddress).Limit(1).Find(walletAddress, "token = ? AND chain_type = ? AND trade_id <> ''", token, chainType).Error
+	return walletAddress, err
+}
+
+// DisableDerivedWalletAddressByTradeId 订单结束后禁用其派生地址，监听任务不再轮询该地址
+func DisableDerivedWalletAddressByTradeId(tradeId string) error {
+	return dao.Mdb.Model(&mdb.WalletAddress{}).
+		Where("trade_id = ? AND status = ?", tradeId, mdb.TokenStatusEnable).
+		Update("status", mdb.TokenStatusDisable).Error
+}
+
 // GetWalletAddressById 通过id获取钱包
 func GetWalletAddressById(id uint64) (*mdb.WalletAddress, error) {
 	walletAddress := new(mdb.WalletAddress)
@@ -67,10 +153,10 @@ func GetAvailableWalletAddress() ([]mdb.WalletAddress, error) {
 	return WalletAddressList, err
 }
 
-// GetAllWalletAddress 获得所有钱包地址
+// GetAllWalletAddress 获得所有收款池钱包地址，订单派生地址数量较多，不在列表中展示
 func GetAllWalletAddress() ([]mdb.WalletAddress, error) {
 	var WalletAddressList []mdb.WalletAddress
-	err := dao.Mdb.Model(WalletAddressList).Find(&WalletAddressList).Error
+	err := dao.Mdb.Model(WalletAddressList).Where("trade_id = ''").Find(&WalletAddressList).Error
 	return WalletAddressList, err
 }
 
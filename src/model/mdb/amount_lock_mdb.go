@@ -0,0 +1,19 @@
+package mdb
+
+import "time"
+
+// AmountLock 支付金额锁表，(钱包, 金额, 链标识) 唯一，同一时间一个金额只对应一个待支付订单
+type AmountLock struct {
+	ID        uint64    `gorm:"column:id;primary_key" json:"id"`
+	WalletId  uint64    `gorm:"column:wallet_id" json:"wallet_id"`   //  钱包ID，对应 wallet_address.id
+	ChainType string    `gorm:"column:chain_type" json:"chain_type"` //  链标识，原生币为 链类型:币种
+	Amount    string    `gorm:"column:amount" json:"amount"`         //  锁定金额，保留4位小数
+	TradeId   string    `gorm:"column:trade_id" json:"trade_id"`     //  交易号
+	ExpiresAt time.Time `gorm:"column:expires_at" json:"expires_at"` //  过期时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (a *AmountLock) TableName() string {
+	return "amount_locks"
+}
@@ -0,0 +1,54 @@
+package mdb
+
+import "github.com/golang-module/carbon/v2"
+
+const (
+	CustomerAddressStatusEnable  = 1
+	CustomerAddressStatusDisable = 2
+)
+
+// 客户充值地址来源
+const (
+	CustomerAddressSourceDerive = "derive" // 由扩展公钥派生
+	CustomerAddressSourcePool   = "pool"   // 从预先导入的地址池分配
+)
+
+// CustomerAddress 商户客户的永久充值地址，持续监听，每笔转入都按充值回调
+type CustomerAddress struct {
+	CustomerId      string       `gorm:"column:customer_id" json:"customer_id"`           //  商户的客户ID，为空表示地址池中尚未分配的地址
+	ChainType       string       `gorm:"column:chain_type" json:"chain_type"`             //  链类型: TRC20, ERC20, BEP20, SOLANA, POLYGON, ARB
+	Token           string       `gorm:"column:token" json:"token"`                       //  充值地址
+	Source          string       `gorm:"column:source" json:"source"`                     //  地址来源: derive(派生), pool(地址池)
+	DerivationIndex *uint32      `gorm:"column:derivation_index" json:"derivation_index"` //  派生地址索引，xpub/0/index，与订单派生地址共用索引
+	NotifyUrl       string       `gorm:"column:notify_url" json:"notify_url"`             //  充值回调地址，为空时使用 deposit_notify_url
+	Status          int          `gorm:"column:status" json:"status"`                     //  1：启用，2：禁用
+	AssignedAt      *carbon.Time `gorm:"column:assigned_at" json:"assigned_at"`           //  分配时间，早于该时间的转账不计入充值
+	BaseModel
+}
+
+// TableName sets the insert table name for this struct type
+func (c *CustomerAddress) TableName() string {
+	return "customer_addresses"
+}
+
+// CustomerDeposit 客户充值记录，每笔转入充值地址的交易一条
+type CustomerDeposit struct {
+	CustomerAddressId  uint64  `gorm:"column:customer_address_id" json:"customer_address_id"`   //  充值地址ID
+	CustomerId         string  `gorm:"column:customer_id" json:"customer_id"`                   //  商户的客户ID
+	ChainType          string  `gorm:"column:chain_type" json:"chain_type"`                     //  链类型
+	Token              string  `gorm:"column:token" json:"token"`                               //  充值地址
+	FromAddress        string  `gorm:"column:from_address" json:"from_address"`                 //  转出地址
+	Asset              string  `gorm:"column:asset" json:"asset"`                               //  代币符号: USDT, USDC
+	Amount             float64 `gorm:"column:amount" json:"amount"`                             //  到账金额，保留4位小数
+	BlockTransactionId string  `gorm:"column:block_transaction_id" json:"block_transaction_id"` //  交易哈希
+	BlockTimestamp     int64   `gorm:"column:block_timestamp" json:"block_timestamp"`           //  区块时间戳，毫秒
+	NotifyUrl          string  `gorm:"column:notify_url" json:"notify_url"`                     //  充值回调地址，为空不回调
+	CallbackNum        int     `gorm:"column:callback_num" json:"callback_num"`                 //  回调次数
+	CallBackConfirm    int     `gorm:"column:callback_confirm" json:"callback_confirm"`         //  回调是否已确认 1是 2否
+	BaseModel
+}
+
+// TableName sets the insert table name for this struct type
+func (c *CustomerDeposit) TableName() string {
+	return "customer_deposits"
+}
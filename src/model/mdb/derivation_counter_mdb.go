@@ -0,0 +1,15 @@
+package mdb
+
+import "time"
+
+// DerivationCounter 派生地址索引计数器表，每条链一行，订单派生地址与客户充值地址共用
+type DerivationCounter struct {
+	ChainType string    `gorm:"column:chain_type;primary_key" json:"chain_type"` //  链类型
+	NextIndex uint32    `gorm:"column:next_index" json:"next_index"`             //  下一个未分配的派生索引
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName sets the insert table name for this struct type
+func (d *DerivationCounter) TableName() string {
+	return "derivation_counters"
+}
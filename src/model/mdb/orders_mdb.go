@@ -1,5 +1,7 @@
 package mdb
 
+import "github.com/golang-module/carbon/v2"
+
 const (
 	StatusWaitPay     = 1
 	StatusPaySuccess  = 2
@@ -8,19 +10,33 @@ const (
 	CallBackConfirmNo = 2
 )
 
+// 订单金额模式
+const (
+	AmountModeFixed = "FIXED" // 固定金额，按金额匹配到账交易（默认）
+	AmountModeOpen  = "OPEN"  // 开放金额，收款地址独占，首笔到账交易按实际金额结算
+)
+
 type Orders struct {
-	TradeId            string  `gorm:"column:trade_id" json:"trade_id"`                         //  epusdt订单号
-	OrderId            string  `gorm:"column:order_id" json:"order_id"`                         //  客户交易id
-	BlockTransactionId string  `gorm:"column:block_transaction_id" json:"block_transaction_id"` // 区块id
-	Amount             float64 `gorm:"column:amount" json:"amount"`                             //  订单金额，保留4位小数
-	ActualAmount       float64 `gorm:"column:actual_amount" json:"actual_amount"`               //  订单实际需要支付的金额，保留4位小数
-	Token              string  `gorm:"column:token" json:"token"`                               //  所属钱包地址
-	ChainType          string  `gorm:"column:chain_type" json:"chain_type"`                     //  链类型: TRC20, ERC20, BEP20, SOLANA, POLYGON, ARB
-	Status             int     `gorm:"column:status" json:"status"`                             //  1：等待支付，2：支付成功，3：已过期
-	NotifyUrl          string  `gorm:"column:notify_url" json:"notify_url"`                     //  异步回调地址
-	RedirectUrl        string  `gorm:"column:redirect_url" json:"redirect_url"`                 //  同步回调地址
-	CallbackNum        int     `gorm:"column:callback_num" json:"callback_num"`                 // 回调次数
-	CallBackConfirm    int     `gorm:"column:callback_confirm" json:"callback_confirm"`         // 回调是否已确认 1是 2否
+	TradeId            string       `gorm:"column:trade_id" json:"trade_id"`                         //  epusdt订单号
+	OrderId            string       `gorm:"column:order_id" json:"order_id"`                         //  客户交易id
+	BlockTransactionId string       `gorm:"column:block_transaction_id" json:"block_transaction_id"` // 区块id
+	Amount             float64      `gorm:"column:amount" json:"amount"`                             //  订单金额，保留4位小数
+	Currency           string       `gorm:"column:currency" json:"currency"`                         //  订单金额的法币币种: CNY, USD, EUR
+	Rate               float64      `gorm:"column:rate" json:"rate"`                                 //  下单时的汇率快照，1 USDT 折合的法币数量
+	ActualAmount       float64      `gorm:"column:actual_amount" json:"actual_amount"`               //  订单实际需要支付的金额，保留4位小数
+	Token              string       `gorm:"column:token" json:"token"`                               //  所属钱包地址
+	ChainType          string       `gorm:"column:chain_type" json:"chain_type"`                     //  链类型: TRC20, ERC20, BEP20, SOLANA, POLYGON, ARB
+	Asset              string       `gorm:"column:asset" json:"asset"`                               //  支付币种: USDT(稳定币), TRX, ETH, BNB, SOL, POL
+	Status             int          `gorm:"column:status" json:"status"`                             //  1：等待支付，2：支付成功，3：已过期
+	NotifyUrl          string       `gorm:"column:notify_url" json:"notify_url"`                     //  异步回调地址
+	RedirectUrl        string       `gorm:"column:redirect_url" json:"redirect_url"`                 //  同步回调地址
+	CallbackNum        int          `gorm:"column:callback_num" json:"callback_num"`                 // 回调次数
+	CallBackConfirm    int          `gorm:"column:callback_confirm" json:"callback_confirm"`         // 回调是否已确认 1是 2否
+	SelectedAt         *carbon.Time `gorm:"column:selected_at" json:"selected_at"`                   //  开放订单付款人选择链的时间，从该时间开始计算过期
+	Theme              string       `gorm:"column:theme" json:"theme"`                               //  收银台主题，对应模板覆盖目录下的子目录
+	PaymentLinkId      uint64       `gorm:"column:payment_link_id" json:"payment_link_id"`           //  来源支付链接ID，0 表示商户下单
+	AmountMode         string       `gorm:"column:amount_mode" json:"amount_mode"`                   //  金额模式: FIXED(固定金额), OPEN(开放金额)
+	SubscriptionId     uint64       `gorm:"column:subscription_id" json:"subscription_id"`           //  来源订阅ID，0 表示非订阅账单
 	BaseModel
 }
 
@@ -0,0 +1,30 @@
+package mdb
+
+import "github.com/golang-module/carbon/v2"
+
+const (
+	PaymentLinkStatusEnable  = 1
+	PaymentLinkStatusDisable = 2
+)
+
+// PaymentLink 可重复使用的支付链接，付款人每次提交生成一笔独立订单
+type PaymentLink struct {
+	Slug        string       `gorm:"column:slug" json:"slug"`                 //  链接标识，/pay/link/:slug
+	Title       string       `gorm:"column:title" json:"title"`               //  标题
+	Description string       `gorm:"column:description" json:"description"`   //  描述
+	Amount      float64      `gorm:"column:amount" json:"amount"`             //  固定金额，0 表示由付款人填写
+	Currency    string       `gorm:"column:currency" json:"currency"`         //  金额币种：法币(CNY, USD, EUR)或 USDT(直接按稳定币计价)
+	ChainTypes  string       `gorm:"column:chain_types" json:"chain_types"`   //  允许的链类型，逗号分隔，为空表示所有可用的链
+	MaxUses     int          `gorm:"column:max_uses" json:"max_uses"`         //  最多支付次数，0 表示不限
+	UseCount    int          `gorm:"column:use_count" json:"use_count"`       //  已支付次数
+	ExpiresAt   *carbon.Time `gorm:"column:expires_at" json:"expires_at"`     //  过期时间，为空表示永不过期
+	Status      int          `gorm:"column:status" json:"status"`             //  1：启用，2：禁用
+	NotifyUrl   string       `gorm:"column:notify_url" json:"notify_url"`     //  生成订单的异步回调地址，为空不回调
+	RedirectUrl string       `gorm:"column:redirect_url" json:"redirect_url"` //  生成订单的同步回调地址
+	BaseModel
+}
+
+// TableName sets the insert table name for this struct type
+func (p *PaymentLink) TableName() string {
+	return "payment_links"
+}
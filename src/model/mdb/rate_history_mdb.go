@@ -0,0 +1,17 @@
+package mdb
+
+import "time"
+
+// RateHistory 汇率历史表，记录每次获取的法币汇率
+type RateHistory struct {
+	ID        uint64    `gorm:"column:id;primary_key" json:"id"`
+	Currency  string    `gorm:"column:currency" json:"currency"`     //  法币币种
+	Rate      float64   `gorm:"column:rate" json:"rate"`             //  1 USDT 折合的法币数量
+	Source    string    `gorm:"column:source" json:"source"`         //  汇率来源，数据源名称或 median:数据源列表
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"` //  获取时间
+}
+
+// TableName sets the insert table name for this struct type
+func (r *RateHistory) TableName() string {
+	return "rate_history"
+}
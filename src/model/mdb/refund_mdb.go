@@ -0,0 +1,38 @@
+package mdb
+
+import "github.com/golang-module/carbon/v2"
+
+const (
+	RefundStatusPending   = 1 // 待退款，等待提交退款交易哈希
+	RefundStatusVerifying = 2 // 核验中，等待退款交易上链确认
+	RefundStatusCompleted = 3 // 已完成
+	RefundStatusFailed    = 4 // 核验失败，可重新提交交易哈希
+	RefundStatusCancelled = 5 // 已取消
+)
+
+// Refund 订单退款记录，退款交易由商户在外部签名发出，epusdt 只记录并在链上核验
+type Refund struct {
+	RefundNo        string       `gorm:"column:refund_no" json:"refund_no"`               //  退款编号
+	TradeId         string       `gorm:"column:trade_id" json:"trade_id"`                 //  epusdt订单号
+	OrderId         string       `gorm:"column:order_id" json:"order_id"`                 //  客户交易id
+	ChainType       string       `gorm:"column:chain_type" json:"chain_type"`             //  链类型，与订单支付链一致
+	Asset           string       `gorm:"column:asset" json:"asset"`                       //  退款币种，与订单支付币种一致: USDT(稳定币), TRX, ETH, BNB, SOL, POL
+	Amount          float64      `gorm:"column:amount" json:"amount"`                     //  退款金额（按支付币种），保留4位小数
+	ToAddress       string       `gorm:"column:to_address" json:"to_address"`             //  退款地址
+	Reason          string       `gorm:"column:reason" json:"reason"`                     //  退款原因
+	Status          int          `gorm:"column:status" json:"status"`                     //  1：待退款，2：核验中，3：已完成，4：核验失败，5：已取消
+	TxHash          string       `gorm:"column:tx_hash" json:"tx_hash"`                   //  退款交易哈希
+	FromAddress     string       `gorm:"column:from_address" json:"from_address"`         //  退款转出地址，核验通过后记录
+	FailReason      string       `gorm:"column:fail_reason" json:"fail_reason"`           //  核验失败原因
+	SubmittedAt     *carbon.Time `gorm:"column:submitted_at" json:"submitted_at"`         //  提交交易哈希的时间
+	CompletedAt     *carbon.Time `gorm:"column:completed_at" json:"completed_at"`         //  核验通过的时间
+	NotifyUrl       string       `gorm:"column:notify_url" json:"notify_url"`             //  退款回调地址，为空不回调
+	CallbackNum     int          `gorm:"column:callback_num" json:"callback_num"`         //  回调次数
+	CallBackConfirm int          `gorm:"column:callback_confirm" json:"callback_confirm"` //  回调是否已确认 1是 2否
+	BaseModel
+}
+
+// TableName sets the insert table name for this struct type
+func (r *Refund) TableName() string {
+	return "refunds"
+}
@@ -0,0 +1,22 @@
+package mdb
+
+// 扫描游标范围
+const (
+	ScanScopeToken  = "token"  // 稳定币转账
+	ScanScopeNative = "native" // 原生币转账
+)
+
+// ScanCursor 区块链增量扫描游标表
+type ScanCursor struct {
+	ChainType     string `gorm:"column:chain_type" json:"chain_type"`         //  链类型
+	Address       string `gorm:"column:address" json:"address"`               //  钱包地址，为空表示整条链的游标
+	Scope         string `gorm:"column:scope" json:"scope"`                   //  扫描范围: token, native
+	Cursor        string `gorm:"column:cursor_value" json:"cursor"`           //  链自定义游标，如区块号、slot
+	LastTimestamp int64  `gorm:"column:last_timestamp" json:"last_timestamp"` //  已扫描到的时间，毫秒
+	BaseModel
+}
+
+// TableName sets the insert table name for this struct type
+func (s *ScanCursor) TableName() string {
+	return "scan_cursor"
+}
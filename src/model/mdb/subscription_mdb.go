@@ -0,0 +1,47 @@
+package mdb
+
+import "github.com/golang-module/carbon/v2"
+
+const (
+	SubscriptionStatusActive    = 1 // 正常
+	SubscriptionStatusPastDue   = 2 // 逾期，账单过期未支付，按间隔重新生成账单催缴
+	SubscriptionStatusCancelled = 3 // 已取消
+)
+
+// 订阅扣款周期单位
+const (
+	SubscriptionIntervalDay   = "DAY"
+	SubscriptionIntervalWeek  = "WEEK"
+	SubscriptionIntervalMonth = "MONTH"
+	SubscriptionIntervalYear  = "YEAR"
+)
+
+// Subscription 订阅，按周期自动生成订单并将支付链接发送给客户
+type Subscription struct {
+	SubscriptionNo string       `gorm:"column:subscription_no" json:"subscription_no"`   //  订阅编号
+	CustomerId     string       `gorm:"column:customer_id" json:"customer_id"`           //  商户的客户ID
+	CustomerEmail  string       `gorm:"column:customer_email" json:"customer_email"`     //  客户邮箱，通过邮件 webhook 发送账单
+	TelegramChatId int64        `gorm:"column:telegram_chat_id" json:"telegram_chat_id"` //  客户 Telegram chat ID，通过机器人发送账单
+	Title          string       `gorm:"column:title" json:"title"`                       //  账单标题
+	Amount         float64      `gorm:"column:amount" json:"amount"`                     //  每期金额
+	Currency       string       `gorm:"column:currency" json:"currency"`                 //  金额币种，法币(CNY、USD、EUR等)或 USDT
+	ChainType      string       `gorm:"column:chain_type" json:"chain_type"`             //  支付链类型，OPEN 表示由客户在收银台选择
+	IntervalUnit   string       `gorm:"column:interval_unit" json:"interval_unit"`       //  周期单位: DAY, WEEK, MONTH, YEAR
+	IntervalCount  int          `gorm:"column:interval_count" json:"interval_count"`     //  周期数，例如 MONTH 和 3 表示每3个月
+	StartAt        carbon.Time  `gorm:"column:start_at" json:"start_at"`                 //  首期账单日，后续账单日按周期推算，避免月末日期漂移
+	DueAt          carbon.Time  `gorm:"column:due_at" json:"due_at"`                     //  当前账期的账单日
+	NextRunAt      carbon.Time  `gorm:"column:next_run_at" json:"next_run_at"`           //  下次生成账单的时间，逾期时为催缴重试时间
+	CurrentTradeId string       `gorm:"column:current_trade_id" json:"current_trade_id"` //  当前账期待支付的订单号
+	RetryCount     int          `gorm:"column:retry_count" json:"retry_count"`           //  当前账期已失败的账单数
+	PaidPeriods    int          `gorm:"column:paid_periods" json:"paid_periods"`         //  已支付期数
+	NotifyUrl      string       `gorm:"column:notify_url" json:"notify_url"`             //  订阅事件回调地址，为空不回调
+	RedirectUrl    string       `gorm:"column:redirect_url" json:"redirect_url"`         //  账单订单的同步回调地址
+	Status         int          `gorm:"column:status" json:"status"`                     //  1：正常，2：逾期，3：已取消
+	CancelledAt    *carbon.Time `gorm:"column:cancelled_at" json:"cancelled_at"`         //  取消时间
+	BaseModel
+}
+
+// TableName sets the insert table name for this struct type
+func (s *Subscription) TableName() string {
+	return "subscriptions"
+}
@@ -15,6 +15,18 @@ const (
 	ChainTypeSOLANA  = "SOLANA"   // Solana
 	ChainTypePOLYGON = "POLYGON"  // Polygon
 	ChainTypeARB     = "ARBITRUM" // Arbitrum
+
+	ChainTypeOpen = "OPEN" // 开放订单，下单时不指定链，由付款人在收银台选择
+)
+
+// 支付币种常量，USDT 表示稳定币（USDT/USDC），其余为各链原生币
+const (
+	AssetUSDT = "USDT" // 稳定币
+	AssetTRX  = "TRX"  // 波场原生币
+	AssetETH  = "ETH"  // 以太坊、Arbitrum 原生币
+	AssetBNB  = "BNB"  // 币安智能链原生币
+	AssetSOL  = "SOL"  // Solana 原生币
+	AssetPOL  = "POL"  // Polygon 原生币
 )
 
 // WalletAddress  钱包表
@@ -25,6 +37,8 @@ type WalletAddress struct {
 	Balance          float64      `gorm:"column:balance" json:"balance"`                       //  USDT余额
 	BalanceUpdatedAt *carbon.Time `gorm:"column:balance_updated_at" json:"balance_updated_at"` //  余额更新时间
 	Status           int64        `gorm:"column:status" json:"status"`                         //  1:启用 2:禁用
+	TradeId          string       `gorm:"column:trade_id" json:"trade_id"`                     //  派生地址关联的订单号，为空表示收款池钱包
+	DerivationIndex  *uint32      `gorm:"column:derivation_index" json:"derivation_index"`     //  派生地址索引，xpub/0/index
 	BaseModel
 }
 
@@ -0,0 +1,62 @@
+package request
+
+import "github.com/gookit/validate"
+
+// AssignCustomerAddressRequest 为客户分配永久充值地址请求
+type AssignCustomerAddressRequest struct {
+	CustomerId string `json:"customer_id" validate:"required|maxLen:64"`
+	ChainType  string `json:"chain_type" validate:"required"`
+	NotifyUrl  string `json:"notify_url"` // 充值回调地址，可选，默认 deposit_notify_url
+	Signature  string `json:"signature" validate:"required"`
+}
+
+func (r AssignCustomerAddressRequest) Translates() map[string]string {
+	return validate.MS{
+		"CustomerId": "客户ID",
+		"ChainType":  "链类型",
+		"Signature":  "签名",
+	}
+}
+
+// CustomerAddressDetailRequest 查询客户充值地址请求
+type CustomerAddressDetailRequest struct {
+	CustomerId string `json:"customer_id" validate:"required|maxLen:64"`
+	Signature  string `json:"signature" validate:"required"`
+}
+
+func (r CustomerAddressDetailRequest) Translates() map[string]string {
+	return validate.MS{
+		"CustomerId": "客户ID",
+		"Signature":  "签名",
+	}
+}
+
+// ImportCustomerAddressRequest 导入客户充值地址池请求
+type ImportCustomerAddressRequest struct {
+	ChainType string `json:"chain_type" validate:"required"`
+	Addresses string `json:"addresses" validate:"required"` // 地址列表，逗号分隔
+	Signature string `json:"signature" validate:"required"`
+}
+
+func (r ImportCustomerAddressRequest) Translates() map[string]string {
+	return validate.MS{
+		"ChainType": "链类型",
+		"Addresses": "地址列表",
+		"Signature": "签名",
+	}
+}
+
+// CustomerDepositListRequest 客户充值记录列表请求
+type CustomerDepositListRequest struct {
+	CustomerId string `json:"customer_id" validate:"required|maxLen:64"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	Signature  string `json:"signature" validate:"required"`
+}
+
+func (r CustomerDepositListRequest) Translates() map[string]string {
+	return validate.MS{
+		"CustomerId": "客户ID",
+		"Signature":  "签名",
+	}
+}
@@ -4,20 +4,29 @@ import "github.com/gookit/validate"
 
 // CreateTransactionRequest 创建交易请求
 type CreateTransactionRequest struct {
-	OrderId     string  `json:"order_id" validate:"required|maxLen:32"`
-	Amount      float64 `json:"amount" validate:"required|isFloat|gt:0.01"`
-	NotifyUrl   string  `json:"notify_url" validate:"required"`
-	Signature   string  `json:"signature"  validate:"required"`
-	RedirectUrl string  `json:"redirect_url"`
-	ChainType   string  `json:"chain_type"` // 链类型，TRC20、ERC20、BEP20、SOLANA、POLYGON、ARB，可选，默认TRC20
+	OrderId        string  `json:"order_id" validate:"required|maxLen:32"`
+	Amount         float64 `json:"amount" validate:"isFloat|min:0"`
+	NotifyUrl      string  `json:"notify_url" validate:"required"`
+	Signature      string  `json:"signature"  validate:"required"`
+	RedirectUrl    string  `json:"redirect_url"`
+	ChainType      string  `json:"chain_type"`                           // 链类型，TRC20、ERC20、BEP20、SOLANA、POLYGON、ARB，可选，默认TRC20
+	Asset          string  `json:"asset"`                                // 支付币种，USDT(稳定币)或链原生币TRX、ETH、BNB、SOL、POL，可选，默认USDT
+	Currency       string  `json:"currency"`                             // 订单金额的法币币种，CNY、USD、EUR等，可选，默认 default_currency
+	AmountCurrency string  `json:"amount_currency"`                      // 订单金额计价方式，FIAT(按 currency 法币计价)或USDT(直接按稳定币计价)，可选，默认FIAT
+	Theme          string  `json:"theme" validate:"maxLen:32|alphaDash"` // 收银台主题，对应 checkout_template_path 下的子目录，可选
+	AmountMode     string  `json:"amount_mode" validate:"in:FIXED,OPEN"` // 金额模式，FIXED(固定金额)或OPEN(开放金额，付款人转入任意金额)，可选，默认FIXED
+	PaymentLinkId  uint64  `json:"-"`                                    // 来源支付链接ID，由支付链接下单时设置
+	SubscriptionId uint64  `json:"-"`                                    // 来源订阅ID，由订阅生成账单时设置
 }
 
 func (r CreateTransactionRequest) Translates() map[string]string {
 	return validate.MS{
-		"OrderId":   "订单号",
-		"Amount":    "支付金额",
-		"NotifyUrl": "异步回调网址",
-		"Signature": "签名",
+		"OrderId":    "订单号",
+		"Amount":     "支付金额",
+		"NotifyUrl":  "异步回调网址",
+		"Signature":  "签名",
+		"Theme":      "收银台主题",
+		"AmountMode": "金额模式",
 	}
 }
 
@@ -27,4 +36,5 @@ type OrderProcessingRequest struct {
 	Amount             float64
 	TradeId            string
 	BlockTransactionId string
+	SettleAmount       float64 // 开放金额订单按到账金额结算的订单金额（按 currency 计价），大于0时同时以 Amount 更新实际支付金额
 }
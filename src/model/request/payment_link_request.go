@@ -0,0 +1,74 @@
+package request
+
+import "github.com/gookit/validate"
+
+// CreatePaymentLinkRequest 创建支付链接请求
+type CreatePaymentLinkRequest struct {
+	Title       string  `json:"title" validate:"required|maxLen:100"`
+	Description string  `json:"description" validate:"maxLen:500"`
+	Amount      float64 `json:"amount"`       // 固定金额，0 表示由付款人填写
+	Currency    string  `json:"currency"`     // 金额币种，法币(CNY、USD、EUR等)或 USDT，可选，默认 default_currency
+	ChainTypes  string  `json:"chain_types"`  // 允许的链类型，逗号分隔，可选，默认所有可用的链
+	MaxUses     int     `json:"max_uses"`     // 最多支付次数，0 表示不限
+	ExpiresAt   int64   `json:"expires_at"`   // 过期时间戳（秒），0 表示永不过期
+	NotifyUrl   string  `json:"notify_url"`   // 生成订单的异步回调地址，可选
+	RedirectUrl string  `json:"redirect_url"` // 生成订单的同步回调地址，可选
+	Signature   string  `json:"signature" validate:"required"`
+}
+
+func (r CreatePaymentLinkRequest) Translates() map[string]string {
+	return validate.MS{
+		"Title":       "标题",
+		"Description": "描述",
+		"Signature":   "签名",
+	}
+}
+
+// UpdatePaymentLinkRequest 修改支付链接请求，金额、币种和链类型创建后不可修改
+type UpdatePaymentLinkRequest struct {
+	Slug        string `json:"slug" validate:"required"`
+	Title       string `json:"title" validate:"required|maxLen:100"`
+	Description string `json:"description" validate:"maxLen:500"`
+	MaxUses     int    `json:"max_uses"`   // 最多支付次数，0 表示不限
+	ExpiresAt   int64  `json:"expires_at"` // 过期时间戳（秒），0 表示永不过期
+	Status      int    `json:"status" validate:"required|in:1,2"`
+	NotifyUrl   string `json:"notify_url"`
+	RedirectUrl string `json:"redirect_url"`
+	Signature   string `json:"signature" validate:"required"`
+}
+
+func (r UpdatePaymentLinkRequest) Translates() map[string]string {
+	return validate.MS{
+		"Slug":        "链接标识",
+		"Title":       "标题",
+		"Description": "描述",
+		"Status":      "状态",
+		"Signature":   "签名",
+	}
+}
+
+// PaymentLinkSlugRequest 按标识查询或删除支付链接
+type PaymentLinkSlugRequest struct {
+	Slug      string `json:"slug" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+func (r PaymentLinkSlugRequest) Translates() map[string]string {
+	return validate.MS{
+		"Slug":      "链接标识",
+		"Signature": "签名",
+	}
+}
+
+// PaymentLinkListRequest 支付链接列表请求
+type PaymentLinkListRequest struct {
+	Page      int    `json:"page"`
+	PageSize  int    `json:"page_size"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+func (r PaymentLinkListRequest) Translates() map[string]string {
+	return validate.MS{
+		"Signature": "签名",
+	}
+}
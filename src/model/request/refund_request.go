@@ -0,0 +1,66 @@
+package request
+
+import "github.com/gookit/validate"
+
+// CreateRefundRequest 创建退款请求
+type CreateRefundRequest struct {
+	TradeId   string  `json:"trade_id" validate:"required"`
+	Amount    float64 `json:"amount" validate:"required|isFloat|gt:0"` // 退款金额，按订单支付币种
+	ToAddress string  `json:"to_address" validate:"required|maxLen:64"`
+	Reason    string  `json:"reason" validate:"maxLen:255"`
+	NotifyUrl string  `json:"notify_url"` // 退款回调地址，可选，默认使用订单的 notify_url
+	Signature string  `json:"signature" validate:"required"`
+}
+
+func (r CreateRefundRequest) Translates() map[string]string {
+	return validate.MS{
+		"TradeId":   "订单号",
+		"Amount":    "退款金额",
+		"ToAddress": "退款地址",
+		"Reason":    "退款原因",
+		"Signature": "签名",
+	}
+}
+
+// SubmitRefundTxRequest 提交退款交易哈希请求
+type SubmitRefundTxRequest struct {
+	RefundNo  string `json:"refund_no" validate:"required"`
+	TxHash    string `json:"tx_hash" validate:"required|maxLen:128"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+func (r SubmitRefundTxRequest) Translates() map[string]string {
+	return validate.MS{
+		"RefundNo":  "退款编号",
+		"TxHash":    "交易哈希",
+		"Signature": "签名",
+	}
+}
+
+// RefundNoRequest 按退款编号查询或取消退款
+type RefundNoRequest struct {
+	RefundNo  string `json:"refund_no" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+func (r RefundNoRequest) Translates() map[string]string {
+	return validate.MS{
+		"RefundNo":  "退款编号",
+		"Signature": "签名",
+	}
+}
+
+// RefundListRequest 退款列表请求
+type RefundListRequest struct {
+	TradeId   string `json:"trade_id"` // 订单号，可选
+	Status    int    `json:"status"`   // 退款状态，可选
+	Page      int    `json:"page"`
+	PageSize  int    `json:"page_size"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+func (r RefundListRequest) Translates() map[string]string {
+	return validate.MS{
+		"Signature": "签名",
+	}
+}
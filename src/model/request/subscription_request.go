@@ -0,0 +1,58 @@
+package request
+
+import "github.com/gookit/validate"
+
+// CreateSubscriptionRequest 创建订阅请求
+type CreateSubscriptionRequest struct {
+	CustomerId     string  `json:"customer_id" validate:"required|maxLen:64"`
+	CustomerEmail  string  `json:"customer_email" validate:"email"`
+	TelegramChatId int64   `json:"telegram_chat_id"` // 客户 Telegram chat ID，可选，客户需先向机器人发送过消息
+	Title          string  `json:"title" validate:"required|maxLen:100"`
+	Amount         float64 `json:"amount" validate:"required|isFloat|gt:0"`
+	Currency       string  `json:"currency"`   // 金额币种，法币(CNY、USD、EUR等)或 USDT，可选，默认 default_currency
+	ChainType      string  `json:"chain_type"` // 支付链类型，可选，默认 OPEN（由客户在收银台选择）
+	IntervalUnit   string  `json:"interval_unit" validate:"required|in:DAY,WEEK,MONTH,YEAR"`
+	IntervalCount  int     `json:"interval_count"` // 周期数，可选，默认1
+	StartAt        int64   `json:"start_at"`       // 首期账单时间戳（秒），可选，默认立即生成首期账单
+	NotifyUrl      string  `json:"notify_url"`     // 订阅事件回调地址，可选
+	RedirectUrl    string  `json:"redirect_url"`   // 账单支付成功后的跳转地址，可选
+	Signature      string  `json:"signature" validate:"required"`
+}
+
+func (r CreateSubscriptionRequest) Translates() map[string]string {
+	return validate.MS{
+		"CustomerId":    "客户ID",
+		"CustomerEmail": "客户邮箱",
+		"Title":         "标题",
+		"Amount":        "金额",
+		"IntervalUnit":  "周期单位",
+		"Signature":     "签名",
+	}
+}
+
+// SubscriptionNoRequest 按订阅编号查询或取消订阅
+type SubscriptionNoRequest struct {
+	SubscriptionNo string `json:"subscription_no" validate:"required"`
+	Signature      string `json:"signature" validate:"required"`
+}
+
+func (r SubscriptionNoRequest) Translates() map[string]string {
+	return validate.MS{
+		"SubscriptionNo": "订阅编号",
+		"Signature":      "签名",
+	}
+}
+
+// SubscriptionListRequest 订阅列表请求
+type SubscriptionListRequest struct {
+	CustomerId string `json:"customer_id"` // 客户ID，可选
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	Signature  string `json:"signature" validate:"required"`
+}
+
+func (r SubscriptionListRequest) Translates() map[string]string {
+	return validate.MS{
+		"Signature": "签名",
+	}
+}
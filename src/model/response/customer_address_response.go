@@ -0,0 +1,47 @@
+package response
+
+// CustomerAddressResponse 客户充值地址
+type CustomerAddressResponse struct {
+	CustomerId string `json:"customer_id"` // 商户的客户ID
+	ChainType  string `json:"chain_type"`  // 链类型
+	Address    string `json:"address"`     // 充值地址
+	Source     string `json:"source"`      // 地址来源: derive(派生), pool(地址池)
+	NotifyUrl  string `json:"notify_url"`  // 充值回调地址
+	Status     int    `json:"status"`      // 1：启用，2：禁用
+	AssignedAt int64  `json:"assigned_at"` // 分配时间戳（秒）
+}
+
+// ImportCustomerAddressResponse 导入客户充值地址池结果
+type ImportCustomerAddressResponse struct {
+	Imported int `json:"imported"` // 新导入的地址数
+	Skipped  int `json:"skipped"`  // 已存在而跳过的地址数
+}
+
+// CustomerDepositResponse 客户充值记录
+type CustomerDepositResponse struct {
+	DepositId          uint64  `json:"deposit_id"`           // 充值记录ID
+	CustomerId         string  `json:"customer_id"`          // 商户的客户ID
+	ChainType          string  `json:"chain_type"`           // 链类型
+	Address            string  `json:"address"`              // 充值地址
+	FromAddress        string  `json:"from_address"`         // 转出地址
+	Asset              string  `json:"asset"`                // 代币符号
+	Amount             float64 `json:"amount"`               // 到账金额
+	BlockTransactionId string  `json:"block_transaction_id"` // 交易哈希
+	BlockTimestamp     int64   `json:"block_timestamp"`      // 区块时间戳（毫秒）
+	CreatedAt          int64   `json:"created_at"`           // 入账时间戳（秒）
+}
+
+// DepositNotifyResponse 充值异步回调
+type DepositNotifyResponse struct {
+	Event              string  `json:"event"`                // 事件类型，固定为 deposit
+	DepositId          uint64  `json:"deposit_id"`           // 充值记录ID，可用于幂等
+	CustomerId         string  `json:"customer_id"`          // 商户的客户ID
+	ChainType          string  `json:"chain_type"`           // 链类型
+	Address            string  `json:"address"`              // 充值地址
+	FromAddress        string  `json:"from_address"`         // 转出地址
+	Asset              string  `json:"asset"`                // 代币符号
+	Amount             float64 `json:"amount"`               // 到账金额
+	BlockTransactionId string  `json:"block_transaction_id"` // 交易哈希
+	BlockTimestamp     int64   `json:"block_timestamp"`      // 区块时间戳（毫秒）
+	Signature          string  `json:"signature"`            // 签名
+}
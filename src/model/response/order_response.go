@@ -5,11 +5,20 @@ type CreateTransactionResponse struct {
 	TradeId        string  `json:"trade_id"`        // epusdt订单号
 	OrderId        string  `json:"order_id"`        // 客户交易id
 	Amount         float64 `json:"amount"`          // 订单金额，保留4位小数
+	Currency       string  `json:"currency"`        // 订单金额的法币币种
+	Rate           float64 `json:"rate"`            // 下单时的汇率，1 USDT 折合的法币数量
+	BaseAmount     float64 `json:"base_amount"`     // 按汇率换算的基础金额，按支付币种计价
+	MarkupAmount   float64 `json:"markup_amount"`   // 汇率加价金额，按支付币种计价
+	FeeAmount      float64 `json:"fee_amount"`      // 链固定手续费，按支付币种计价
 	ActualAmount   float64 `json:"actual_amount"`   // 订单实际需要支付的金额，保留4位小数
 	Token          string  `json:"token"`           // 收款钱包地址
 	ChainType      string  `json:"chain_type"`      // 链类型，TRC20、ERC20、BEP20、SOLANA、POLYGON、ARB
+	Asset          string  `json:"asset"`           // 支付币种，USDT(稳定币)或链原生币
+	AmountMode     string  `json:"amount_mode"`     // 金额模式，FIXED(固定金额)或OPEN(开放金额)
 	ExpirationTime int64   `json:"expiration_time"` // 过期时间，时间戳
 	PaymentUrl     string  `json:"payment_url"`     // 收银台地址
+	PaymentUri     string  `json:"payment_uri"`     // 链标准支付链接，开放订单为空
+	QrCodeUrl      string  `json:"qrcode_url"`      // 支付二维码图片地址，开放订单为空
 }
 
 // OrderNotifyResponse 订单异步回调结构体
@@ -17,9 +26,12 @@ type OrderNotifyResponse struct {
 	TradeId            string  `json:"trade_id"`             // epusdt订单号
 	OrderId            string  `json:"order_id"`             // 客户交易id
 	Amount             float64 `json:"amount"`               // 订单金额，保留4位小数
+	Currency           string  `json:"currency"`             // 订单金额的法币币种
+	Rate               float64 `json:"rate"`                 // 下单时的汇率，1 USDT 折合的法币数量
 	ActualAmount       float64 `json:"actual_amount"`        // 订单实际需要支付的金额，保留4位小数
 	Token              string  `json:"token"`                // 收款钱包地址
 	ChainType          string  `json:"chain_type"`           // 链类型，TRC20、ERC20、BEP20、SOLANA、POLYGON、ARB
+	Asset              string  `json:"asset"`                // 支付币种，USDT(稳定币)或链原生币
 	BlockTransactionId string  `json:"block_transaction_id"` // 区块id
 	Signature          string  `json:"signature"`            // 签名
 	Status             int     `json:"status"`               // 1为等待支付，2为支付成功，3为已过期
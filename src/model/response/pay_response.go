@@ -1,16 +1,49 @@
 package response
 
 type CheckoutCounterResponse struct {
-	TradeId        string  `json:"trade_id"`        // epusdt订单号
-	ActualAmount   float64 `json:"actual_amount"`   // 订单实际需要支付的金额，保留4位小数
-	Token          string  `json:"token"`           // 收款钱包地址
-	TokenRemark    string  `json:"token_remark"`    // 钱包备注名称
-	ChainType      string  `json:"chain_type"`      // 链类型，TRC20、ERC20、BEP20、SOLANA、POLYGON、ARB
-	ExpirationTime int64   `json:"expiration_time"` // 过期时间，时间戳
-	RedirectUrl    string  `json:"redirect_url"`
+	TradeId         string       `json:"trade_id"`        // epusdt订单号
+	Amount          float64      `json:"amount"`          // 订单金额
+	Currency        string       `json:"currency"`        // 订单金额的法币币种
+	ActualAmount    float64      `json:"actual_amount"`   // 订单实际需要支付的金额，保留4位小数
+	Token           string       `json:"token"`           // 收款钱包地址
+	TokenRemark     string       `json:"token_remark"`    // 钱包备注名称
+	ChainType       string       `json:"chain_type"`      // 链类型，TRC20、ERC20、BEP20、SOLANA、POLYGON、ARB
+	Asset           string       `json:"asset"`           // 支付币种，USDT(稳定币)或链原生币
+	AmountMode      string       `json:"amount_mode"`     // 金额模式，FIXED(固定金额)或OPEN(开放金额，付款人转入任意金额)
+	ExpirationTime  int64        `json:"expiration_time"` // 过期时间，时间戳
+	RedirectUrl     string       `json:"redirect_url"`
+	AvailableChains []string     `json:"available_chains"` // 开放订单未选择链时可选的链类型
+	Theme           string       `json:"theme"`            // 收银台主题
+	PaymentUri      string       `json:"payment_uri"`      // 链标准支付链接（EIP-681、Solana Pay、TRON），钱包扫码后自动填写地址和金额
+	WalletLinks     []WalletLink `json:"wallet_links"`     // 在钱包App中打开的链接
+}
+
+// WalletLink 钱包App打开链接
+type WalletLink struct {
+	Name string `json:"name"` // 钱包名称
+	Url  string `json:"url"`  // 打开链接
 }
 
 type CheckStatusResponse struct {
 	TradeId string `json:"trade_id"` // epusdt订单号
 	Status  int    `json:"status"`
 }
+
+// CheckoutApiResponse 收银台 JSON 接口返回，在收银台详情基础上增加链展示信息
+type CheckoutApiResponse struct {
+	*CheckoutCounterResponse
+	ChainName        string        `json:"chain_name"`        // 链展示名称
+	TokenSymbol      string        `json:"token_symbol"`      // 支付币种符号
+	TokenDecimals    int32         `json:"token_decimals"`    // 支付币种链上精度
+	AcceptedTokens   []string      `json:"accepted_tokens"`   // 可用于支付的代币，稳定币订单部分链 USDT/USDC 均可
+	ExplorerTxUrl    string        `json:"explorer_tx_url"`   // 交易浏览器地址模板，{tx_hash} 替换为交易哈希
+	QrCodeUrl        string        `json:"qrcode_url"`        // 支付二维码图片地址
+	RemainingSeconds int64         `json:"remaining_seconds"` // 距过期的剩余秒数
+	ChainOptions     []ChainOption `json:"chain_options"`     // 开放订单未选择链时可选的支付网络
+}
+
+// ChainOption 可选的支付网络
+type ChainOption struct {
+	ChainType string `json:"chain_type"` // 链类型
+	Name      string `json:"name"`       // 展示名称
+}
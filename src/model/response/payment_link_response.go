@@ -0,0 +1,29 @@
+package response
+
+// PaymentLinkResponse 支付链接详情
+type PaymentLinkResponse struct {
+	Slug        string   `json:"slug"`         // 链接标识
+	Url         string   `json:"url"`          // 支付页面地址
+	Title       string   `json:"title"`        // 标题
+	Description string   `json:"description"`  // 描述
+	Amount      float64  `json:"amount"`       // 固定金额，0 表示由付款人填写
+	Currency    string   `json:"currency"`     // 金额币种，法币或 USDT
+	ChainTypes  []string `json:"chain_types"`  // 允许的链类型，为空表示所有可用的链
+	MaxUses     int      `json:"max_uses"`     // 最多支付次数，0 表示不限
+	UseCount    int      `json:"use_count"`    // 已支付次数
+	ExpiresAt   int64    `json:"expires_at"`   // 过期时间戳（秒），0 表示永不过期
+	Status      int      `json:"status"`       // 1：启用，2：禁用
+	NotifyUrl   string   `json:"notify_url"`   // 生成订单的异步回调地址
+	RedirectUrl string   `json:"redirect_url"` // 生成订单的同步回调地址
+	CreatedAt   int64    `json:"created_at"`   // 创建时间戳（秒）
+}
+
+// PaymentLinkPageResponse 支付链接页面数据
+type PaymentLinkPageResponse struct {
+	Slug        string        `json:"slug"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Amount      float64       `json:"amount"`   // 固定金额，0 表示由付款人填写
+	Currency    string        `json:"currency"` // 金额币种
+	Chains      []ChainOption `json:"chains"`   // 付款人可选的支付网络
+}
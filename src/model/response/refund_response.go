@@ -0,0 +1,38 @@
+package response
+
+// RefundResponse 退款详情
+type RefundResponse struct {
+	RefundNo    string  `json:"refund_no"`    // 退款编号
+	TradeId     string  `json:"trade_id"`     // epusdt订单号
+	OrderId     string  `json:"order_id"`     // 客户交易id
+	ChainType   string  `json:"chain_type"`   // 链类型
+	Asset       string  `json:"asset"`        // 退款币种
+	Amount      float64 `json:"amount"`       // 退款金额
+	ToAddress   string  `json:"to_address"`   // 退款地址
+	Reason      string  `json:"reason"`       // 退款原因
+	Status      int     `json:"status"`       // 1：待退款，2：核验中，3：已完成，4：核验失败，5：已取消
+	TxHash      string  `json:"tx_hash"`      // 退款交易哈希
+	FromAddress string  `json:"from_address"` // 退款转出地址
+	FailReason  string  `json:"fail_reason"`  // 核验失败原因
+	NotifyUrl   string  `json:"notify_url"`   // 退款回调地址
+	SubmittedAt int64   `json:"submitted_at"` // 提交交易哈希的时间戳（秒）
+	CompletedAt int64   `json:"completed_at"` // 核验通过的时间戳（秒）
+	CreatedAt   int64   `json:"created_at"`   // 创建时间戳（秒）
+}
+
+// RefundNotifyResponse 退款完成回调
+type RefundNotifyResponse struct {
+	Event       string  `json:"event"`        // 事件类型: refund.completed
+	RefundNo    string  `json:"refund_no"`    // 退款编号
+	TradeId     string  `json:"trade_id"`     // epusdt订单号
+	OrderId     string  `json:"order_id"`     // 客户交易id
+	ChainType   string  `json:"chain_type"`   // 链类型
+	Asset       string  `json:"asset"`        // 退款币种
+	Amount      float64 `json:"amount"`       // 退款金额
+	ToAddress   string  `json:"to_address"`   // 退款地址
+	FromAddress string  `json:"from_address"` // 退款转出地址
+	TxHash      string  `json:"tx_hash"`      // 退款交易哈希
+	Reason      string  `json:"reason"`       // 退款原因
+	CompletedAt int64   `json:"completed_at"` // 核验通过的时间戳（秒）
+	Signature   string  `json:"signature"`    // 签名
+}
@@ -0,0 +1,45 @@
+package response
+
+// SubscriptionResponse 订阅详情
+type SubscriptionResponse struct {
+	SubscriptionNo string  `json:"subscription_no"`  // 订阅编号
+	CustomerId     string  `json:"customer_id"`      // 商户的客户ID
+	CustomerEmail  string  `json:"customer_email"`   // 客户邮箱
+	TelegramChatId int64   `json:"telegram_chat_id"` // 客户 Telegram chat ID
+	Title          string  `json:"title"`            // 账单标题
+	Amount         float64 `json:"amount"`           // 每期金额
+	Currency       string  `json:"currency"`         // 金额币种
+	ChainType      string  `json:"chain_type"`       // 支付链类型
+	IntervalUnit   string  `json:"interval_unit"`    // 周期单位
+	IntervalCount  int     `json:"interval_count"`   // 周期数
+	DueAt          int64   `json:"due_at"`           // 当前账期的账单日，时间戳（秒）
+	NextRunAt      int64   `json:"next_run_at"`      // 下次生成账单的时间戳（秒）
+	CurrentTradeId string  `json:"current_trade_id"` // 当前待支付的账单订单号
+	RetryCount     int     `json:"retry_count"`      // 当前账期已失败的账单数
+	PaidPeriods    int     `json:"paid_periods"`     // 已支付期数
+	Status         int     `json:"status"`           // 1：正常，2：逾期，3：已取消
+	NotifyUrl      string  `json:"notify_url"`       // 订阅事件回调地址
+	RedirectUrl    string  `json:"redirect_url"`     // 账单支付成功后的跳转地址
+	CreatedAt      int64   `json:"created_at"`       // 创建时间戳（秒）
+}
+
+// SubscriptionNotifyResponse 订阅事件回调及账单邮件 webhook
+type SubscriptionNotifyResponse struct {
+	Event              string  `json:"event"`                // 事件类型: subscription.invoice, subscription.paid, subscription.past_due, subscription.cancelled
+	SubscriptionNo     string  `json:"subscription_no"`      // 订阅编号
+	CustomerId         string  `json:"customer_id"`          // 商户的客户ID
+	CustomerEmail      string  `json:"customer_email"`       // 客户邮箱
+	Title              string  `json:"title"`                // 账单标题
+	Amount             float64 `json:"amount"`               // 每期金额
+	Currency           string  `json:"currency"`             // 金额币种
+	Status             int     `json:"status"`               // 订阅状态
+	DueAt              int64   `json:"due_at"`               // 账单对应的账单日，时间戳（秒）
+	RetryCount         int     `json:"retry_count"`          // 当前账期已失败的账单数
+	PaidPeriods        int     `json:"paid_periods"`         // 已支付期数
+	TradeId            string  `json:"trade_id"`             // 账单订单号
+	PaymentUrl         string  `json:"payment_url"`          // 账单收银台地址
+	ActualAmount       float64 `json:"actual_amount"`        // 实际支付金额（按支付币种）
+	ChainType          string  `json:"chain_type"`           // 支付链类型
+	BlockTransactionId string  `json:"block_transaction_id"` // 区块交易号
+	Signature          string  `json:"signature"`            // 签名
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"math/rand"
+
+	"github.com/assimon/luuu/config"
+	"github.com/shopspring/decimal"
+)
+
+// 金额分配策略名称
+const (
+	AmountStrategyIncrement = "increment" // 向上递增
+	AmountStrategyDecrement = "decrement" // 在少收预算内向下递减
+	AmountStrategyRandom    = "random"    // 在±N步内随机偏移
+)
+
+// AmountStrategy 金额分配策略，按优先顺序返回候选金额
+type AmountStrategy interface {
+	Candidates(amount decimal.Decimal) []decimal.Decimal
+}
+
+// GetAmountStrategy 根据配置获取金额分配策略，step 为候选金额的间隔，原生币为按价格换算后的币量
+// 步数按 USDT 步长计算，少收预算和随机范围对应的价值与稳定币订单一致
+func GetAmountStrategy(step decimal.Decimal) AmountStrategy {
+	switch config.GetAmountStrategy() {
+	case AmountStrategyDecrement:
+		steps := decimal.NewFromFloat(config.GetAmountDiscountBudget()).Div(decimal.NewFromFloat(UsdtAmountPerIncrement)).IntPart()
+		return &decrementStrategy{step: step, maxSteps: int(steps)}
+	case AmountStrategyRandom:
+		return &randomStrategy{step: step, maxSteps: config.GetAmountRandomRange()}
+	default:
+		return &incrementStrategy{step: step, maxSteps: IncrementalMaximumNumber}
+	}
+}
+
+// incrementStrategy 从原金额开始向上递增
+type incrementStrategy struct {
+	step     decimal.Decimal
+	maxSteps int
+}
+
+func (s *incrementStrategy) Candidates(amount decimal.Decimal) []decimal.Decimal {
+	candidates := make([]decimal.Decimal, 0, s.maxSteps)
+	for i := 0; i < s.maxSteps; i++ {
+		candidates = append(candidates, amount.Add(s.step.Mul(decimal.NewFromInt(int64(i)))))
+	}
+	return candidates
+}
+
+// decrementStrategy 从原金额开始向下递减，最多少收预算内的金额
+type decrementStrategy struct {
+	step     decimal.Decimal
+	maxSteps int
+}
+
+func (s *decrementStrategy) Candidates(amount decimal.Decimal) []decimal.Decimal {
+	minimum := decimal.NewFromFloat(UsdtMinimumPaymentAmount)
+	candidates := make([]decimal.Decimal, 0, s.maxSteps+1)
+	for i := 0; i <= s.maxSteps; i++ {
+		candidate := amount.Sub(s.step.Mul(decimal.NewFromInt(int64(i))))
+		if candidate.LessThan(minimum) {
+			break
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// randomStrategy 优先原金额，其余在±maxSteps步内随机排列，避免金额集中在原金额附近
+type randomStrategy struct {
+	step     decimal.Decimal
+	maxSteps int
+}
+
+func (s *randomStrategy) Candidates(amount decimal.Decimal) []decimal.Decimal {
+	minimum := decimal.NewFromFloat(UsdtMinimumPaymentAmount)
+	offsets := make([]int, 0, s.maxSteps*2)
+	for i := 1; i <= s.maxSteps; i++ {
+		offsets = append(offsets, i, -i)
+	}
+	rand.Shuffle(len(offsets), func(i, j int) {
+		offsets[i], offsets[j] = offsets[j], offsets[i]
+	})
+
+	candidates := make([]decimal.Decimal, 0, len(offsets)+1)
+	candidates = append(candidates, amount)
+	for _, offset := range offsets {
+		candidate := amount.Add(s.step.Mul(decimal.NewFromInt(int64(offset))))
+		if candidate.LessThan(minimum) {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
@@ -2,42 +2,41 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/assimon/luuu/blockchain"
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/event"
 	"github.com/assimon/luuu/model/dao"
 	"github.com/assimon/luuu/model/data"
 	"github.com/assimon/luuu/model/mdb"
 	"github.com/assimon/luuu/model/request"
 	"github.com/assimon/luuu/mq/handle"
 	"github.com/assimon/luuu/notify"
+	"github.com/assimon/luuu/util/constant"
 	"github.com/assimon/luuu/util/log"
+	"github.com/assimon/luuu/util/math"
 	"github.com/golang-module/carbon/v2"
+	"github.com/shopspring/decimal"
 )
 
 // GetBlockchainExplorerURL 获取区块链浏览器URL
 func GetBlockchainExplorerURL(chainType string, txHash string) string {
-	switch chainType {
-	case mdb.ChainTypeTRC20:
-		return fmt.Sprintf("https://tronscan.org/#/transaction/%s", txHash)
-	case mdb.ChainTypeERC20:
-		return fmt.Sprintf("https://etherscan.io/tx/%s", txHash)
-	case mdb.ChainTypeBEP20:
-		return fmt.Sprintf("https://bscscan.com/tx/%s", txHash)
-	case mdb.ChainTypePOLYGON:
-		return fmt.Sprintf("https://polygonscan.com/tx/%s", txHash)
-	case mdb.ChainTypeSOLANA:
-		return fmt.Sprintf("https://solscan.io/tx/%s", txHash)
-	case mdb.ChainTypeARB:
-		return fmt.Sprintf("https://arbiscan.io/tx/%s", txHash)
-	default:
-		return ""
-	}
+	return strings.ReplaceAll(GetChainMeta(chainType).ExplorerTxUrl, ExplorerTxHashPlaceholder, txHash)
 }
 
 // GetTokenSymbol 根据合约地址获取代币符号
 func GetTokenSymbol(contractAddress string, chainType string) string {
+	// 合约地址为空表示原生币转账
+	if contractAddress == "" {
+		if chainService := blockchain.GetChainService(chainType); chainService != nil {
+			return chainService.GetNativeSymbol()
+		}
+	}
+
 	// USDT合约地址
 	usdtContracts := map[string]string{
 		"0xdac17f958d2ee523a2206206994597c13d831ec7":   "USDT", // ERC20
@@ -74,6 +73,17 @@ func GetTokenSymbol(contractAddress string, chainType string) string {
 // ChainCallBack 通用区块链回调处理
 func ChainCallBack(address string, chainType string, wg *sync.WaitGroup) {
 	defer wg.Done()
+	chainCallBack(address, chainType, true)
+}
+
+// NativeChainCallBack 只处理原生币转账的回调，用于代币已由区块扫描监听的链
+func NativeChainCallBack(address string, chainType string, wg *sync.WaitGroup) {
+	defer wg.Done()
+	chainCallBack(address, chainType, false)
+}
+
+// chainCallBack 查询地址交易并匹配订单，scanToken 为 false 时跳过稳定币查询
+func chainCallBack(address string, chainType string, scanToken bool) {
 	defer func() {
 		if err := recover(); err != nil {
 			log.Sugar.Errorf("[%s] 区块链回调异常: %v", chainType, err)
@@ -91,17 +101,81 @@ func ChainCallBack(address string, chainType string, wg *sync.WaitGroup) {
 
 	log.Sugar.Debugf("[%s] 已找到区块链服务，正在查询交易...", chainType)
 
-	// 查询最近24小时的交易
-	startTime := carbon.Now().AddHours(-24).TimestampWithMillisecond()
+	// 增量扫描：从上次扫描位置继续，停机过久时只补扫有限的时间窗口
 	endTime := carbon.Now().TimestampWithMillisecond()
+	catchupStart := carbon.Now().AddHours(-config.GetBlockchainCatchupHours()).TimestampWithMillisecond()
 
-	transactions, err := chainService.GetTransactions(address, startTime, endTime)
+	tokenCursor, err := data.GetScanCursor(chainType, address, mdb.ScanScopeToken)
 	if err != nil {
-		// API 失败时只记录警告，不中断服务
-		log.Sugar.Warnf("[%s] API调用失败 %s: %v (将在下次周期重试)", chainType, address, err)
-		return
+		log.Sugar.Warnf("[%s] 获取扫描游标失败 %s: %v", chainType, address, err)
+		tokenCursor = &mdb.ScanCursor{}
+	}
+
+	var transactions []blockchain.Transaction
+	newTokenCursor := tokenCursor.Cursor
+	tokenScanned := false
+	if scanToken {
+		if scanner, ok := chainService.(blockchain.CursorScanner); ok {
+			transactions, newTokenCursor, err = scanner.GetTransactionsByCursor(address, tokenCursor.Cursor, catchupStart, endTime)
+		} else {
+			transactions, err = chainService.GetTransactions(address, getScanStartTime(tokenCursor, catchupStart), endTime)
+		}
+		tokenScanned = err == nil
+		if errors.Is(err, blockchain.ErrMaxPagesReached) {
+			// 达到翻页上限时处理已获取的交易，但不推进游标，下个周期从原位置重新扫描
+			log.Sugar.Warnf("[%s] 地址 %s 交易过多，达到翻页上限，暂不推进扫描游标，可调大 blockchain_max_pages", chainType, address)
+			err = nil
+		}
+		if err != nil {
+			// API 失败时只记录警告，不中断服务
+			log.Sugar.Warnf("[%s] API调用失败 %s: %v (将在下次周期重试)", chainType, address, err)
+			return
+		}
 	}
 
+	// 有原生币待支付订单时才查询原生币转账
+	nativeChainKey := data.GetLockChainKey(chainType, chainService.GetNativeSymbol())
+	hasNativeOrder, err := data.HasPendingOrderByAddress(address, nativeChainKey)
+	if err != nil {
+		log.Sugar.Warnf("[%s] 检查原生币订单状态失败: %s, err=%v", chainType, address, err)
+	}
+	nativeScanned := false
+	if hasNativeOrder {
+		nativeCursor, err := data.GetScanCursor(chainType, address, mdb.ScanScopeNative)
+		if err != nil {
+			log.Sugar.Warnf("[%s] 获取原生币扫描游标失败 %s: %v", chainType, address, err)
+			nativeCursor = &mdb.ScanCursor{}
+		}
+		nativeTransactions, err := chainService.GetNativeTransactions(address, getScanStartTime(nativeCursor, catchupStart), endTime)
+		if errors.Is(err, blockchain.ErrMaxPagesReached) {
+			log.Sugar.Warnf("[%s] 地址 %s 原生币交易过多，达到翻页上限，暂不推进扫描游标，可调大 blockchain_max_pages", chainType, address)
+			transactions = append(transactions, nativeTransactions...)
+		} else if err != nil {
+			log.Sugar.Warnf("[%s] 原生币API调用失败 %s: %v (将在下次周期重试)", chainType, address, err)
+		} else {
+			transactions = append(transactions, nativeTransactions...)
+			nativeScanned = true
+		}
+	}
+
+	// 全部交易处理完成后才推进游标，处理失败时下个周期重新扫描
+	processFailed := false
+	defer func() {
+		if processFailed {
+			return
+		}
+		if tokenScanned {
+			if err := data.SaveScanCursor(chainType, address, mdb.ScanScopeToken, newTokenCursor, endTime); err != nil {
+				log.Sugar.Warnf("[%s] 保存扫描游标失败 %s: %v", chainType, address, err)
+			}
+		}
+		if nativeScanned {
+			if err := data.SaveScanCursor(chainType, address, mdb.ScanScopeNative, "", endTime); err != nil {
+				log.Sugar.Warnf("[%s] 保存原生币扫描游标失败 %s: %v", chainType, address, err)
+			}
+		}
+	}()
+
 	log.Sugar.Debugf("[%s] API返回 %d 笔交易", chainType, len(transactions))
 
 	if len(transactions) == 0 {
@@ -116,80 +190,171 @@ func ChainCallBack(address string, chainType string, wg *sync.WaitGroup) {
 		log.Sugar.Infof("[%s] 处理交易 %d/%d: 哈希=%s, 金额=%.4f, 发送方=%s, 接收方=%s",
 			chainType, i+1, len(transactions), tx.Hash, tx.Amount, tx.From, tx.To)
 
-		// 根据钱包地址和金额查询订单
-		log.Sugar.Debugf("[%s] 查找订单: 地址=%s, 金额=%.4f", chainType, address, tx.Amount)
-
-		tradeId, err := data.GetTradeIdByWalletAddressAndAmountAndChainType(address, tx.Amount, chainType)
-		if err != nil {
-			log.Sugar.Errorf("[%s] 获取交易号失败: %v", chainType, err)
-			continue
+		if !processChainTransaction(address, chainType, nativeChainKey, tx) {
+			processFailed = true
 		}
+	}
+}
 
-		if tradeId == "" {
-			log.Sugar.Debugf("[%s] 未找到匹配订单，金额=%.4f", chainType, tx.Amount)
-			continue
+// ProcessBlockTransactions 处理区块扫描得到的交易，交易的接收地址即收款钱包
+// 返回 false 表示存在需要重新扫描的处理失败
+func ProcessBlockTransactions(chainType string, transactions []blockchain.Transaction) bool {
+	chainService := blockchain.GetChainService(chainType)
+	if chainService == nil {
+		log.Sugar.Errorf("[%s] 未找到区块链服务", chainType)
+		return false
+	}
+	nativeChainKey := data.GetLockChainKey(chainType, chainService.GetNativeSymbol())
+
+	success := true
+	for i, tx := range transactions {
+		log.Sugar.Infof("[%s] 处理区块交易 %d/%d: 哈希=%s, 金额=%.4f, 发送方=%s, 接收方=%s",
+			chainType, i+1, len(transactions), tx.Hash, tx.Amount, tx.From, tx.To)
+		if !processChainTransaction(tx.To, chainType, nativeChainKey, tx) {
+			success = false
 		}
+	}
+	return success
+}
+
+// processChainTransaction 匹配并处理单笔交易，返回 false 表示处理失败需要重新扫描
+func processChainTransaction(address string, chainType string, nativeChainKey string, tx blockchain.Transaction) bool {
+	// 客户充值地址不关联订单，每笔转入按充值处理
+	customerAddress, err := data.GetCustomerAddressByTokenAndChainType(address, chainType)
+	if err != nil {
+		log.Sugar.Errorf("[%s] 获取客户充值地址失败: %v", chainType, err)
+		return false
+	}
+	if customerAddress.ID > 0 {
+		return processCustomerDeposit(customerAddress, chainType, tx)
+	}
 
-		log.Sugar.Infof("[%s] 找到匹配订单！交易号=%s, 金额=%.4f", chainType, tradeId, tx.Amount)
+	// 根据钱包地址和金额查询订单
+	log.Sugar.Debugf("[%s] 查找订单: 地址=%s, 金额=%.4f", chainType, address, tx.Amount)
 
-		// 获取订单信息
-		order, err := data.GetOrderInfoByTradeId(tradeId)
+	// 原生币转账使用原生币链标识匹配，避免与稳定币订单金额混淆
+	lockChainKey := chainType
+	if tx.ContractAddress == "" {
+		lockChainKey = nativeChainKey
+	}
+	tradeId, err := data.GetTradeIdByWalletAddressAndAmountAndChainType(address, tx.Amount, lockChainKey)
+	if err != nil {
+		log.Sugar.Errorf("[%s] 获取交易号失败: %v", chainType, err)
+		return false
+	}
+
+	// 金额未命中时，派生地址按地址匹配订单
+	paidAmount := tx.Amount
+	if tradeId == "" {
+		tradeId, paidAmount, err = matchDerivedAddressOrder(address, chainType, lockChainKey, tx.Amount)
 		if err != nil {
-			log.Sugar.Errorf("[%s] 获取订单信息失败: %v", chainType, err)
-			continue
+			log.Sugar.Errorf("[%s] 派生地址匹配订单失败: %v", chainType, err)
+			return false
 		}
+	}
 
-		log.Sugar.Infof("[%s] 订单信息: 交易号=%s, 订单号=%s, 状态=%d, 金额=%.2f, 实际金额=%.4f",
-			chainType, order.TradeId, order.OrderId, order.Status, order.Amount, order.ActualAmount)
+	if tradeId == "" {
+		log.Sugar.Debugf("[%s] 未找到匹配订单，金额=%.4f", chainType, tx.Amount)
+		return true
+	}
 
-		// 验证链类型匹配
-		if order.ChainType != chainType {
-			log.Sugar.Warnf("[%s] 链类型不匹配: 订单=%s, 交易=%s",
-				chainType, order.ChainType, chainType)
-			continue
-		}
+	log.Sugar.Infof("[%s] 找到匹配订单！交易号=%s, 金额=%.4f", chainType, tradeId, tx.Amount)
+
+	// 获取订单信息
+	order, err := data.GetOrderInfoByTradeId(tradeId)
+	if err != nil {
+		log.Sugar.Errorf("[%s] 获取订单信息失败: %v", chainType, err)
+		return false
+	}
 
-		// 区块的确认时间必须在订单创建时间之后
-		createTime := order.CreatedAt.TimestampWithMillisecond()
-		log.Sugar.Debugf("[%s] 时间检查: 交易时间=%d, 订单时间=%d", chainType, tx.BlockTimestamp, createTime)
+	log.Sugar.Infof("[%s] 订单信息: 交易号=%s, 订单号=%s, 状态=%d, 金额=%.2f, 实际金额=%.4f",
+		chainType, order.TradeId, order.OrderId, order.Status, order.Amount, order.ActualAmount)
 
-		if tx.BlockTimestamp < createTime {
-			log.Sugar.Warnf("[%s] 交易时间(%d) 早于订单创建时间(%d)",
-				chainType, tx.BlockTimestamp, createTime)
-			continue
-		}
+	// 验证链类型匹配
+	if order.ChainType != chainType {
+		log.Sugar.Warnf("[%s] 链类型不匹配: 订单=%s, 交易=%s",
+			chainType, order.ChainType, chainType)
+		return true
+	}
+
+	// 区块的确认时间必须在订单创建时间之后
+	createTime := order.CreatedAt.TimestampWithMillisecond()
+	log.Sugar.Debugf("[%s] 时间检查: 交易时间=%d, 订单时间=%d", chainType, tx.BlockTimestamp, createTime)
 
-		log.Sugar.Infof("[%s] 所有验证通过，正在处理支付...", chainType)
+	if tx.BlockTimestamp < createTime {
+		log.Sugar.Warnf("[%s] 交易时间(%d) 早于订单创建时间(%d)",
+			chainType, tx.BlockTimestamp, createTime)
+		return true
+	}
 
-		// 到这一步就完全算是支付成功了
-		req := &request.OrderProcessingRequest{
-			Token:              address,
-			TradeId:            tradeId,
-			Amount:             tx.Amount,
-			BlockTransactionId: tx.Hash,
+	// 开放金额订单按首笔到账金额结算，忽略低于最小支付单位的转账（如地址投毒的零金额转账）
+	var settleAmount float64
+	if order.AmountMode == mdb.AmountModeOpen {
+		if tx.Amount < UsdtMinimumPaymentAmount {
+			log.Sugar.Warnf("[%s] 开放金额订单 %s 忽略小额转账: 金额=%.8f, 哈希=%s", chainType, tradeId, tx.Amount, tx.Hash)
+			return true
 		}
+		paidAmount = math.MustParsePrecFloat64(tx.Amount, 4)
+		settleAmount = calculateSettleAmount(order, paidAmount)
+	}
 
-		log.Sugar.Infof("处理支付: 交易号=%s, 金额=%f, 交易哈希=%s", tradeId, tx.Amount, tx.Hash)
+	log.Sugar.Infof("[%s] 所有验证通过，正在处理支付...", chainType)
+	// 已匹配到账交易，收银台显示确认中；入账失败时按订单当前状态重新推送
+	event.PublishStage(tradeId, mdb.StatusWaitPay, event.StageConfirming)
+
+	// 到这一步就完全算是支付成功了
+	req := &request.OrderProcessingRequest{
+		Token:              address,
+		TradeId:            tradeId,
+		Amount:             paidAmount,
+		BlockTransactionId: tx.Hash,
+		SettleAmount:       settleAmount,
+	}
 
-		err = OrderProcessing(req)
-		if err != nil {
-			log.Sugar.Errorf("处理订单失败 %s: %v", tradeId, err)
-			continue
+	log.Sugar.Infof("处理支付: 交易号=%s, 金额=%f, 交易哈希=%s", tradeId, tx.Amount, tx.Hash)
+
+	err = OrderProcessing(req)
+	if err != nil {
+		log.Sugar.Errorf("处理订单失败 %s: %v", tradeId, err)
+		// 撤回确认中状态，避免收银台一直停留在确认中
+		if current, statusErr := GetOrderStatusEvent(tradeId); statusErr == nil {
+			event.Publish(current)
 		}
+		// 交易已被处理过属于正常情况，无需重新扫描
+		return err == constant.OrderBlockAlreadyProcess
+	}
+
+	log.Sugar.Infof("支付处理成功，交易号=%s", tradeId)
+	if settleAmount > 0 {
+		order.Amount = settleAmount
+		order.ActualAmount = paidAmount
+	}
 
-		log.Sugar.Infof("支付处理成功，交易号=%s", tradeId)
+	// 更新钱包余额
+	go UpdateWalletBalanceAfterPayment(address, chainType)
 
-		// 更新钱包余额
-		go UpdateWalletBalanceAfterPayment(address, chainType)
+	// 支付链接生成的订单累加链接的已支付次数
+	if order.PaymentLinkId > 0 {
+		if err := data.IncrPaymentLinkUseCount(order.PaymentLinkId); err != nil {
+			log.Sugar.Errorf("更新支付链接支付次数失败 %d: %v", order.PaymentLinkId, err)
+		}
+	}
 
-		// 回调队列
+	// 订阅账单支付成功后订阅进入下一账期
+	if order.SubscriptionId > 0 {
+		handleSubscriptionInvoicePaid(order)
+	}
+
+	// 回调队列，未设置回调地址的订单（如未配置回调的支付链接）不回调
+	if order.NotifyUrl != "" {
 		ctx := context.Background()
 		dao.EnqueueTaskNow(ctx, "default", handle.QueueOrderCallback, order, 5)
+	}
 
-		// 发送机器人消息
-		explorerURL := GetBlockchainExplorerURL(chainType, tx.Hash)
-		tokenSymbol := GetTokenSymbol(tx.ContractAddress, chainType)
-		msgTpl := `【支付成功通知】
+	// 发送机器人消息
+	explorerURL := GetBlockchainExplorerURL(chainType, tx.Hash)
+	tokenSymbol := GetTokenSymbol(tx.ContractAddress, chainType)
+	msgTpl := `【支付成功通知】
 
 区块链：%s
 交易号：%s
@@ -207,18 +372,65 @@ func ChainCallBack(address string, chainType string, wg *sync.WaitGroup) {
 
 订单创建时间：%s
 支付成功时间：%s`
-		msg := fmt.Sprintf(msgTpl,
-			chainType,
-			order.TradeId,
-			order.OrderId,
-			order.Amount,
-			tokenSymbol,
-			order.ActualAmount,
-			order.Token,
-			tx.Hash,
-			explorerURL,
-			order.CreatedAt.ToDateTimeString(),
-			carbon.Now().ToDateTimeString())
-		notify.SendToBot(msg)
+	msg := fmt.Sprintf(msgTpl,
+		chainType,
+		order.TradeId,
+		order.OrderId,
+		order.Amount,
+		tokenSymbol,
+		order.ActualAmount,
+		order.Token,
+		tx.Hash,
+		explorerURL,
+		order.CreatedAt.ToDateTimeString(),
+		carbon.Now().ToDateTimeString())
+	notify.SendToBot(msg)
+	return true
+}
+
+// calculateSettleAmount 按下单时的汇率快照将到账的稳定币金额折算为订单金额
+func calculateSettleAmount(order *mdb.Orders, paidAmount float64) float64 {
+	if order.Currency == mdb.AssetUSDT {
+		return paidAmount
+	}
+	settle := decimal.NewFromFloat(paidAmount).Mul(decimal.NewFromFloat(order.Rate)).Round(2).InexactFloat64()
+	// 折算结果过小时按最低法币金额记录，保证订单金额大于0
+	if settle < FiatMinimumPaymentAmount {
+		return FiatMinimumPaymentAmount
+	}
+	return settle
+}
+
+// matchDerivedAddressOrder 派生地址每单独立，按地址匹配待支付订单，转入金额不低于订单金额即可
+// 开放金额订单的订单金额为0，任意转入金额均可匹配
+// 返回订单的应付金额，用于释放金额锁
+func matchDerivedAddressOrder(address string, chainType string, lockChainKey string, amount float64) (string, float64, error) {
+	wallet, err := data.GetDerivedWalletAddress(address, chainType)
+	if err != nil || wallet.ID <= 0 {
+		return "", 0, err
+	}
+	order, err := data.GetOrderInfoByTradeId(wallet.TradeId)
+	if err != nil || order.ID <= 0 {
+		return "", 0, err
+	}
+	if order.Status != mdb.StatusWaitPay || data.GetLockChainKey(order.ChainType, order.Asset) != lockChainKey {
+		return "", 0, nil
+	}
+	if amount < order.ActualAmount {
+		log.Sugar.Warnf("[%s] 派生地址 %s 转入金额 %.4f 不足订单金额 %.4f", chainType, address, amount, order.ActualAmount)
+		return "", 0, nil
+	}
+	return order.TradeId, order.ActualAmount, nil
+}
+
+// getScanStartTime 根据游标计算本次扫描的开始时间，回退重叠时间以覆盖索引延迟，且不早于补扫窗口
+func getScanStartTime(cursor *mdb.ScanCursor, catchupStart int64) int64 {
+	if cursor.LastTimestamp <= 0 {
+		return catchupStart
+	}
+	startTime := cursor.LastTimestamp - int64(config.GetBlockchainScanOverlap())*1000
+	if startTime < catchupStart {
+		return catchupStart
 	}
+	return startTime
 }
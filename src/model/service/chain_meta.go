@@ -0,0 +1,42 @@
+package service
+
+import (
+	"github.com/assimon/luuu/model/mdb"
+)
+
+// ExplorerTxHashPlaceholder 浏览器地址模板中的交易哈希占位符
+const ExplorerTxHashPlaceholder = "{tx_hash}"
+
+// ChainMeta 链的展示信息
+type ChainMeta struct {
+	Name           string   // 展示名称
+	TokenDecimals  int32    // 稳定币精度
+	NativeDecimals int32    // 原生币精度
+	ExplorerTxUrl  string   // 交易浏览器地址模板，{tx_hash} 替换为交易哈希
+	Stablecoins    []string // 稳定币订单可用于支付的代币
+}
+
+var usdtAndUsdc = []string{"USDT", "USDC"}
+
+var chainMetas = map[string]ChainMeta{
+	mdb.ChainTypeTRC20:   {Name: "Tron (TRC20)", TokenDecimals: 6, NativeDecimals: 6, ExplorerTxUrl: "https://tronscan.org/#/transaction/{tx_hash}", Stablecoins: []string{"USDT"}},
+	mdb.ChainTypeERC20:   {Name: "Ethereum (ERC20)", TokenDecimals: 6, NativeDecimals: 18, ExplorerTxUrl: "https://etherscan.io/tx/{tx_hash}", Stablecoins: usdtAndUsdc},
+	mdb.ChainTypeBEP20:   {Name: "BNB Smart Chain (BEP20)", TokenDecimals: 18, NativeDecimals: 18, ExplorerTxUrl: "https://bscscan.com/tx/{tx_hash}", Stablecoins: usdtAndUsdc},
+	mdb.ChainTypePOLYGON: {Name: "Polygon", TokenDecimals: 6, NativeDecimals: 18, ExplorerTxUrl: "https://polygonscan.com/tx/{tx_hash}", Stablecoins: usdtAndUsdc},
+	mdb.ChainTypeSOLANA:  {Name: "Solana", TokenDecimals: 6, NativeDecimals: 9, ExplorerTxUrl: "https://solscan.io/tx/{tx_hash}", Stablecoins: usdtAndUsdc},
+	mdb.ChainTypeARB:     {Name: "Arbitrum One", TokenDecimals: 6, NativeDecimals: 18, ExplorerTxUrl: "https://arbiscan.io/tx/{tx_hash}", Stablecoins: usdtAndUsdc},
+}
+
+// GetChainMeta 获取链的展示信息，未知链返回空值
+func GetChainMeta(chainType string) ChainMeta {
+	return chainMetas[chainType]
+}
+
+// GetAssetDecimals 获取支付币种在链上的精度
+func GetAssetDecimals(chainType string, asset string) int32 {
+	meta := GetChainMeta(chainType)
+	if asset == mdb.AssetUSDT {
+		return meta.TokenDecimals
+	}
+	return meta.NativeDecimals
+}
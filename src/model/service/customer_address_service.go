@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/assimon/luuu/blockchain"
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/data"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/assimon/luuu/model/request"
+	"github.com/assimon/luuu/model/response"
+	"github.com/assimon/luuu/mq/handle"
+	"github.com/assimon/luuu/notify"
+	"github.com/assimon/luuu/util/constant"
+	"github.com/assimon/luuu/util/log"
+	"github.com/assimon/luuu/util/math"
+	"github.com/assimon/luuu/util/page"
+	"github.com/golang-module/carbon/v2"
+)
+
+// AssignCustomerAddress 为客户分配指定链上的永久充值地址，已分配过的直接返回原地址
+// 派生模式下由扩展公钥派生新地址，否则从地址池中分配
+func AssignCustomerAddress(req *request.AssignCustomerAddressRequest) (*response.CustomerAddressResponse, error) {
+	chainType := strings.ToUpper(strings.TrimSpace(req.ChainType))
+	if !IsSupportedChainType(chainType) || blockchain.GetChainService(chainType) == nil {
+		return nil, constant.ChainNotAvailableErr
+	}
+	notifyUrl := strings.TrimSpace(req.NotifyUrl)
+
+	exist, err := data.GetCustomerAddressByCustomerId(req.CustomerId, chainType)
+	if err != nil {
+		return nil, err
+	}
+	if exist.ID > 0 {
+		if notifyUrl != "" && notifyUrl != exist.NotifyUrl {
+			if err = data.UpdateCustomerAddressNotifyUrl(exist.ID, notifyUrl); err != nil {
+				return nil, err
+			}
+			exist.NotifyUrl = notifyUrl
+		}
+		return buildCustomerAddressResponse(exist), nil
+	}
+
+	if xpub := getDeriveXpub(chainType); xpub != "" {
+		token, index, err := deriveNextAddress(chainType, xpub)
+		if err != nil {
+			return nil, err
+		}
+		assignedAt := carbon.Now()
+		address := &mdb.CustomerAddress{
+			CustomerId:      req.CustomerId,
+			ChainType:       chainType,
+			Token:           token,
+			Source:          mdb.CustomerAddressSourceDerive,
+			DerivationIndex: &index,
+			NotifyUrl:       notifyUrl,
+			Status:          mdb.CustomerAddressStatusEnable,
+			AssignedAt:      &carbon.Time{Carbon: assignedAt},
+		}
+		if err = data.CreateCustomerAddress(address); err != nil {
+			return nil, err
+		}
+		log.Sugar.Infof("[%s] 为客户 %s 派生充值地址 %s，索引=%d", chainType, req.CustomerId, token, index)
+		return buildCustomerAddressResponse(address), nil
+	}
+
+	address, err := data.AssignPoolCustomerAddress(req.CustomerId, chainType, notifyUrl)
+	if err != nil {
+		return nil, err
+	}
+	if address.ID <= 0 {
+		return nil, constant.CustomerAddressUnavailable
+	}
+	log.Sugar.Infof("[%s] 为客户 %s 分配地址池充值地址 %s", chainType, req.CustomerId, address.Token)
+	return buildCustomerAddressResponse(address), nil
+}
+
+// GetCustomerAddresses 获取客户在所有链上的充值地址
+func GetCustomerAddresses(customerId string) ([]*response.CustomerAddressResponse, error) {
+	addresses, err := data.GetCustomerAddressesByCustomerId(customerId)
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses) == 0 {
+		return nil, constant.CustomerAddressNotExists
+	}
+	list := make([]*response.CustomerAddressResponse, 0, len(addresses))
+	for i := range addresses {
+		list = append(list, buildCustomerAddressResponse(&addresses[i]))
+	}
+	return list, nil
+}
+
+// ImportCustomerAddresses 向地址池导入未分配的客户充值地址，地址以逗号分隔，已存在的地址跳过
+func ImportCustomerAddresses(req *request.ImportCustomerAddressRequest) (*response.ImportCustomerAddressResponse, error) {
+	chainType := strings.ToUpper(strings.TrimSpace(req.ChainType))
+	chainService := blockchain.GetChainService(chainType)
+	if !IsSupportedChainType(chainType) || chainService == nil {
+		return nil, constant.ChainNotAvailableErr
+	}
+	var tokens []string
+	for _, token := range strings.Split(req.Addresses, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		if !chainService.ValidateAddress(token) {
+			return nil, constant.InvalidWalletAddressErr
+		}
+		tokens = append(tokens, token)
+	}
+
+	resp := &response.ImportCustomerAddressResponse{}
+	for _, token := range tokens {
+		// 收款池钱包和订单派生地址用于订单匹配，不能同时作为客户充值地址
+		wallet, err := data.GetWalletAddressByTokenAndChainType(token, chainType)
+		if err != nil {
+			return nil, err
+		}
+		exist, err := data.GetCustomerAddressByTokenAndChainType(token, chainType)
+		if err != nil {
+			return nil, err
+		}
+		if wallet.ID > 0 || exist.ID > 0 {
+			resp.Skipped++
+			continue
+		}
+		address := &mdb.CustomerAddress{
+			ChainType: chainType,
+			Token:     token,
+			Source:    mdb.CustomerAddressSourcePool,
+			Status:    mdb.CustomerAddressStatusEnable,
+		}
+		if err = data.CreateCustomerAddress(address); err != nil {
+			return nil, err
+		}
+		resp.Imported++
+	}
+	return resp, nil
+}
+
+// ListCustomerDeposits 分页获取客户充值记录
+func ListCustomerDeposits(customerId string, pageNum int, pageSize int) ([]*response.CustomerDepositResponse, page.Pagination, error) {
+	if pageNum <= 0 {
+		pageNum = page.DefaultPage
+	}
+	if pageSize <= 0 {
+		pageSize = page.DefaultPageSize
+	}
+	if pageSize > page.MaxPageSize {
+		pageSize = page.MaxPageSize
+	}
+	deposits, total, err := data.GetCustomerDeposits(customerId, pageNum, pageSize)
+	if err != nil {
+		return nil, page.Pagination{}, err
+	}
+	list := make([]*response.CustomerDepositResponse, 0, len(deposits))
+	for _, deposit := range deposits {
+		list = append(list, &response.CustomerDepositResponse{
+			DepositId:          deposit.ID,
+			CustomerId:         deposit.CustomerId,
+			ChainType:          deposit.ChainType,
+			Address:            deposit.Token,
+			FromAddress:        deposit.FromAddress,
+			Asset:              deposit.Asset,
+			Amount:             deposit.Amount,
+			BlockTransactionId: deposit.BlockTransactionId,
+			BlockTimestamp:     deposit.BlockTimestamp,
+			CreatedAt:          deposit.CreatedAt.Timestamp(),
+		})
+	}
+	return list, page.GetPagination(pageNum, pageSize, total), nil
+}
+
+// processCustomerDeposit 记录客户充值地址收到的稳定币转账并回调，返回 false 表示处理失败需要重新扫描
+func processCustomerDeposit(customerAddress *mdb.CustomerAddress, chainType string, tx blockchain.Transaction) bool {
+	// 充值只接受稳定币，忽略原生币和低于最小支付单位的转账（如地址投毒的零金额转账）
+	if tx.ContractAddress == "" {
+		return true
+	}
+	if tx.Amount < UsdtMinimumPaymentAmount {
+		log.Sugar.Warnf("[%s] 客户充值地址 %s 忽略小额转账: 金额=%.8f, 哈希=%s", chainType, customerAddress.Token, tx.Amount, tx.Hash)
+		return true
+	}
+	// 分配前转入的交易（如地址池中地址的历史转账）不属于该客户
+	if customerAddress.AssignedAt != nil && tx.BlockTimestamp < customerAddress.AssignedAt.TimestampWithMillisecond() {
+		return true
+	}
+
+	notifyUrl := customerAddress.NotifyUrl
+	if notifyUrl == "" {
+		notifyUrl = config.GetDepositNotifyUrl()
+	}
+	deposit := &mdb.CustomerDeposit{
+		CustomerAddressId:  customerAddress.ID,
+		CustomerId:         customerAddress.CustomerId,
+		ChainType:          chainType,
+		Token:              customerAddress.Token,
+		FromAddress:        tx.From,
+		Asset:              GetTokenSymbol(tx.ContractAddress, chainType),
+		Amount:             math.MustParsePrecFloat64(tx.Amount, 4),
+		BlockTransactionId: tx.Hash,
+		BlockTimestamp:     tx.BlockTimestamp,
+		NotifyUrl:          notifyUrl,
+		CallBackConfirm:    mdb.CallBackConfirmNo,
+	}
+	if err := data.CreateCustomerDeposit(deposit); err != nil {
+		// 同一交易已入账，重叠扫描时属于正常情况
+		if data.IsDuplicateKeyError(err) {
+			return true
+		}
+		log.Sugar.Errorf("[%s] 保存客户充值记录失败 %s: %v", chainType, tx.Hash, err)
+		return false
+	}
+	log.Sugar.Infof("[%s] 客户 %s 充值到账: 地址=%s, 金额=%.4f, 哈希=%s",
+		chainType, deposit.CustomerId, deposit.Token, deposit.Amount, tx.Hash)
+
+	if deposit.NotifyUrl != "" {
+		dao.EnqueueTaskNow(context.Background(), "default", handle.QueueDepositCallback, deposit, 5)
+	}
+
+	msgTpl := `【充值到账通知】
+
+区块链：%s
+客户ID：%s
+充值币种：%s
+充值金额：%.4f
+充值地址：%s
+转出地址：%s
+
+交易哈希：
+%s
+
+区块链浏览器：
+%s
+
+到账时间：%s`
+	msg := fmt.Sprintf(msgTpl,
+		chainType,
+		deposit.CustomerId,
+		deposit.Asset,
+		deposit.Amount,
+		deposit.Token,
+		deposit.FromAddress,
+		tx.Hash,
+		GetBlockchainExplorerURL(chainType, tx.Hash),
+		carbon.Now().ToDateTimeString())
+	notify.SendToBot(msg)
+	return true
+}
+
+func buildCustomerAddressResponse(address *mdb.CustomerAddress) *response.CustomerAddressResponse {
+	resp := &response.CustomerAddressResponse{
+		CustomerId: address.CustomerId,
+		ChainType:  address.ChainType,
+		Address:    address.Token,
+		Source:     address.Source,
+		NotifyUrl:  address.NotifyUrl,
+		Status:     address.Status,
+	}
+	if address.AssignedAt != nil {
+		resp.AssignedAt = address.AssignedAt.Timestamp()
+	}
+	return resp
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/model/data"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/assimon/luuu/util/hdwallet"
+	"github.com/assimon/luuu/util/log"
+)
+
+// DeriveMaxAttempts 派生地址已被占用时最多尝试的索引数
+const DeriveMaxAttempts = 10
+
+// getDeriveXpub 获取链类型对应的扩展公钥，未启用派生模式或该链不支持时返回空
+// Solana 使用 ed25519，只支持硬化推导，必须持有私钥种子，因此不支持派生模式，仍按金额区分订单
+func getDeriveXpub(chainType string) string {
+	if config.GetAddressMode() != "derive" {
+		return ""
+	}
+	switch chainType {
+	case mdb.ChainTypeERC20, mdb.ChainTypeBEP20, mdb.ChainTypePOLYGON, mdb.ChainTypeARB:
+		return config.GetEvmXpub()
+	case mdb.ChainTypeTRC20:
+		return config.GetTronXpub()
+	default:
+		return ""
+	}
+}
+
+// DeriveOrderAddress 为订单派生独立的只读收款地址（xpub/0/index）并登记到钱包表
+func DeriveOrderAddress(chainType string, xpub string, tradeId string) (*mdb.WalletAddress, error) {
+	address, index, err := deriveNextAddress(chainType, xpub)
+	if err != nil {
+		return nil, err
+	}
+	return data.AddDerivedWalletAddress(address, chainType, tradeId, index)
+}
+
+// deriveNextAddress 按计数器分配的下一个索引派生收款地址（xpub/0/index），跳过已登记在钱包表或客户充值地址表中的地址
+func deriveNextAddress(chainType string, xpub string) (string, uint32, error) {
+	extendedKey, err := hdwallet.ParseXpub(xpub)
+	if err != nil {
+		return "", 0, fmt.Errorf("解析%s扩展公钥失败: %w", chainType, err)
+	}
+	// 外部链（收款地址）
+	receiveKey, err := extendedKey.Child(0)
+	if err != nil {
+		return "", 0, err
+	}
+
+	for attempt := 0; attempt < DeriveMaxAttempts; attempt++ {
+		// 每次尝试都从计数器分配新索引，并发派生时不会拿到相同索引
+		index, err := data.AllocateDerivationIndex(chainType)
+		if err != nil {
+			return "", 0, err
+		}
+		childKey, err := receiveKey.Child(index)
+		if err != nil {
+			log.Sugar.Warnf("[%s] 派生地址索引 %d 无效: %v", chainType, index, err)
+			continue
+		}
+
+		address := childKey.EvmAddress()
+		if chainType == mdb.ChainTypeTRC20 {
+			address = childKey.TronAddress()
+		}
+
+		// 地址已作为收款池钱包手动添加过时跳过
+		exist, err := data.GetWalletAddressByTokenAndChainType(address, chainType)
+		if err != nil {
+			return "", 0, err
+		}
+		if exist.ID > 0 {
+			continue
+		}
+		// 地址已作为客户充值地址导入地址池时跳过
+		customerAddress, err := data.GetCustomerAddressByTokenAndChainType(address, chainType)
+		if err != nil {
+			return "", 0, err
+		}
+		if customerAddress.ID > 0 {
+			continue
+		}
+
+		return address, index, nil
+	}
+
+	return "", 0, fmt.Errorf("[%s] 连续 %d 个派生地址不可用", chainType, DeriveMaxAttempts)
+}
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
-	"sync"
+	"strings"
 	"time"
 
+	"github.com/assimon/luuu/blockchain"
 	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/event"
 	"github.com/assimon/luuu/model/dao"
 	"github.com/assimon/luuu/model/data"
 	"github.com/assimon/luuu/model/mdb"
@@ -16,35 +18,84 @@ import (
 	"github.com/assimon/luuu/model/response"
 	"github.com/assimon/luuu/mq/handle"
 	"github.com/assimon/luuu/util/constant"
+	"github.com/assimon/luuu/util/log"
 	"github.com/assimon/luuu/util/math"
 	"github.com/golang-module/carbon/v2"
 	"github.com/shopspring/decimal"
 )
 
 const (
-	CnyMinimumPaymentAmount  = 0.01   // 人民币最低支付金额
+	FiatMinimumPaymentAmount = 0.01   // 法币最低支付金额
 	UsdtMinimumPaymentAmount = 0.0001 // USDT最低支付金额
 	UsdtAmountPerIncrement   = 0.0001 // USDT每次递增金额
 	IncrementalMaximumNumber = 1000   // 最大递增次数
 )
 
-var gCreateTransactionLock sync.Mutex
+// SupportedChainTypes 支持的链类型，收银台按此顺序展示可选的支付网络
+var SupportedChainTypes = []string{
+	mdb.ChainTypeTRC20,
+	mdb.ChainTypeERC20,
+	mdb.ChainTypeBEP20,
+	mdb.ChainTypeSOLANA,
+	mdb.ChainTypePOLYGON,
+	mdb.ChainTypeARB,
+}
+
+// 订单金额计价方式
+const (
+	AmountCurrencyFiat = "FIAT" // 按法币计价，按汇率换算为USDT（默认）
+	AmountCurrencyUSDT = "USDT" // 直接按稳定币计价
+)
 
-// CreateTransaction 创建订单
+// CreateTransaction 创建订单，金额分配依赖 amount_locks 唯一索引，多实例部署无需全局锁
 func CreateTransaction(req *request.CreateTransactionRequest) (*response.CreateTransactionResponse, error) {
-	gCreateTransactionLock.Lock()
-	defer gCreateTransactionLock.Unlock()
-	payAmount := math.MustParsePrecFloat64(req.Amount, 2)
-	// 按照汇率转化USDT
-	decimalPayAmount := decimal.NewFromFloat(payAmount)
-	decimalRate := decimal.NewFromFloat(config.GetUsdtRate())
-	decimalUsdt := decimalPayAmount.Div(decimalRate)
-	// 人民币是否可以满足最低支付金额
-	if decimalPayAmount.Cmp(decimal.NewFromFloat(CnyMinimumPaymentAmount)) == -1 {
-		return nil, constant.PayAmountErr
+	// 开放金额订单由到账金额决定订单金额，不校验下单金额
+	amountMode := strings.ToUpper(req.AmountMode)
+	if amountMode == "" {
+		amountMode = mdb.AmountModeFixed
+	}
+	openAmount := amountMode == mdb.AmountModeOpen
+	var currency string
+	var rate float64
+	var decimalUsdt decimal.Decimal
+	markupPercent := config.GetRateMarkupPercent()
+	switch strings.ToUpper(req.AmountCurrency) {
+	case AmountCurrencyUSDT:
+		// 金额直接按稳定币计价，跳过汇率换算和汇率加价
+		currency = mdb.AssetUSDT
+		rate = 1
+		markupPercent = 0
+		decimalUsdt = decimal.NewFromFloat(math.MustParsePrecFloat64(req.Amount, 4))
+	case "", AmountCurrencyFiat:
+		payAmount := math.MustParsePrecFloat64(req.Amount, 2)
+		// 确定法币币种，默认使用 default_currency
+		currency = strings.ToUpper(req.Currency)
+		if currency == "" {
+			currency = config.GetDefaultCurrency()
+		}
+		if !config.IsFiatCurrencySupported(currency) {
+			return nil, constant.CurrencyNotSupportedErr
+		}
+		// 汇率过期时按配置暂停下单，避免按过时汇率收款
+		if config.GetRateStaleBlock() && config.IsFiatRateStale(currency) {
+			return nil, constant.RateStaleErr
+		}
+		rate = config.GetFiatRate(currency)
+		if rate <= 0 {
+			return nil, constant.RateAmountErr
+		}
+		// 按照汇率转化USDT
+		decimalPayAmount := decimal.NewFromFloat(payAmount)
+		decimalUsdt = decimalPayAmount.Div(decimal.NewFromFloat(rate))
+		// 法币是否可以满足最低支付金额
+		if !openAmount && decimalPayAmount.Cmp(decimal.NewFromFloat(FiatMinimumPaymentAmount)) == -1 {
+			return nil, constant.PayAmountErr
+		}
+	default:
+		return nil, constant.CurrencyNotSupportedErr
 	}
 	// USDT是否可以满足最低支付金额
-	if decimalUsdt.Cmp(decimal.NewFromFloat(UsdtMinimumPaymentAmount)) == -1 {
+	if !openAmount && decimalUsdt.Cmp(decimal.NewFromFloat(UsdtMinimumPaymentAmount)) == -1 {
 		return nil, constant.PayAmountErr
 	}
 	// 已经存在了的交易
@@ -55,88 +106,252 @@ func CreateTransaction(req *request.CreateTransactionRequest) (*response.CreateT
 	if exist.ID > 0 {
 		return nil, constant.OrderAlreadyExists
 	}
-	// 确定链类型，默认为TRC20
-	chainType := req.ChainType
-	if chainType == "" {
-		chainType = mdb.ChainTypeTRC20
+	// 确定支付币种，默认为稳定币
+	asset := strings.ToUpper(req.Asset)
+	if asset == "" {
+		asset = mdb.AssetUSDT
 	}
-	// 验证链类型是否有效
-	validChainTypes := map[string]bool{
-		mdb.ChainTypeTRC20:   true,
-		mdb.ChainTypeERC20:   true,
-		mdb.ChainTypeBEP20:   true,
-		mdb.ChainTypeSOLANA:  true,
-		mdb.ChainTypePOLYGON: true,
-		mdb.ChainTypeARB:     true,
-	}
-	if !validChainTypes[chainType] {
-		chainType = mdb.ChainTypeTRC20 // 无效时使用默认值
-	}
-
-	// 检查是否有可用钱包，根据链类型
-	walletAddress, err := data.GetAvailableWalletAddressByChainType(chainType)
-	if err != nil {
-		return nil, err
+	// 开放金额订单按稳定币到账金额和汇率快照折算订单金额，只支持稳定币
+	if openAmount && asset != mdb.AssetUSDT {
+		return nil, constant.AssetNotSupportedErr
 	}
-	if len(walletAddress) <= 0 {
-		return nil, constant.NotAvailableWalletAddress
+	if openAmount {
+		req.Amount = 0
 	}
-	// 金额保留4位小数，与缓存key的规范化保持一致，避免12.31和12.3100不匹配
-	amount := math.MustParsePrecFloat64(decimalUsdt.InexactFloat64(), 4)
-	availableToken, availableAmount, err := CalculateAvailableWalletAndAmount(amount, walletAddress, chainType)
-	if err != nil {
-		return nil, err
+	tradeId := GenerateCode()
+	order := &mdb.Orders{
+		TradeId:        tradeId,
+		OrderId:        req.OrderId,
+		Amount:         req.Amount,
+		Currency:       currency,
+		Rate:           rate,
+		Asset:          asset,
+		Status:         mdb.StatusWaitPay,
+		NotifyUrl:      req.NotifyUrl,
+		RedirectUrl:    req.RedirectUrl,
+		Theme:          req.Theme,
+		PaymentLinkId:  req.PaymentLinkId,
+		SubscriptionId: req.SubscriptionId,
+		AmountMode:     amountMode,
 	}
-	if availableToken == "" {
-		return nil, constant.NotAvailableAmountErr
+	// 开放订单暂不分配钱包，由付款人在收银台选择链后再分配地址和金额
+	var allocation *paymentAllocation
+	openChain := strings.ToUpper(req.ChainType) == mdb.ChainTypeOpen
+	if openChain {
+		// 原生币与链绑定，开放订单只支持稳定币
+		if asset != mdb.AssetUSDT {
+			return nil, constant.AssetNotSupportedErr
+		}
+	} else {
+		// 确定链类型，无效时使用默认值TRC20
+		chainType := req.ChainType
+		if !IsSupportedChainType(chainType) {
+			chainType = mdb.ChainTypeTRC20
+		}
+		if openAmount {
+			allocation, err = allocateOpenAmountPayment(tradeId, chainType)
+		} else {
+			allocation, err = allocatePayment(tradeId, chainType, asset, decimalUsdt, markupPercent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		order.ChainType = chainType
+		order.Token = allocation.Token
+		order.ActualAmount = allocation.Amount
 	}
 	tx := dao.Mdb.Begin()
-	order := &mdb.Orders{
-		TradeId:      GenerateCode(),
-		OrderId:      req.OrderId,
-		Amount:       req.Amount,
-		ActualAmount: availableAmount,
-		Token:        availableToken,
-		ChainType:    chainType,
-		Status:       mdb.StatusWaitPay,
-		NotifyUrl:    req.NotifyUrl,
-		RedirectUrl:  req.RedirectUrl,
-	}
 	err = data.CreateOrderWithTransaction(tx, order)
-	if err != nil {
+	if err == nil {
+		err = tx.Commit().Error
+	} else {
 		tx.Rollback()
-		return nil, err
-	}
-	// 先提交事务
-	err = tx.Commit().Error
-	if err != nil {
-		return nil, err
 	}
-
-	// 提交事务后再锁定支付池，避免SQLite写锁冲突
-	err = data.LockTransactionWithChainType(availableToken, order.TradeId, availableAmount, chainType, config.GetOrderExpirationTimeDuration())
 	if err != nil {
-		// 如果缓存失败，需要回滚订单，删除已创建的订单
-		data.DeleteOrderById(order.ID)
+		// 订单创建失败，释放已预占的金额
+		if allocation != nil {
+			allocation.release(tradeId)
+		}
+		// 并发提交相同商户订单号时由唯一索引拦截
+		if data.IsDuplicateKeyError(err) {
+			return nil, constant.OrderAlreadyExists
+		}
 		return nil, err
 	}
 	// 超时过期消息队列
+	expirationMinutes := config.GetOrderExpirationTime()
+	if openChain {
+		expirationMinutes = config.GetOpenOrderExpirationTime()
+	}
 	ctx := context.Background()
-	dao.EnqueueTaskDelay(ctx, "default", handle.QueueOrderExpiration, order.TradeId, config.GetOrderExpirationTimeDuration(), 3)
-	ExpirationTime := carbon.Now().AddMinutes(config.GetOrderExpirationTime()).Timestamp()
+	dao.EnqueueTaskDelay(ctx, "default", handle.QueueOrderExpiration, order.TradeId, time.Duration(expirationMinutes)*time.Minute, 3)
 	resp := &response.CreateTransactionResponse{
 		TradeId:        order.TradeId,
 		OrderId:        order.OrderId,
 		Amount:         order.Amount,
+		Currency:       order.Currency,
+		Rate:           order.Rate,
 		ActualAmount:   order.ActualAmount,
 		Token:          order.Token,
 		ChainType:      order.ChainType,
-		ExpirationTime: ExpirationTime,
+		Asset:          order.Asset,
+		AmountMode:     order.AmountMode,
+		ExpirationTime: carbon.Now().AddMinutes(expirationMinutes).Timestamp(),
 		PaymentUrl:     fmt.Sprintf("%s/pay/checkout-counter/%s", config.GetAppUri(), order.TradeId),
 	}
+	if allocation != nil {
+		resp.BaseAmount = allocation.Breakdown.Base.Round(4).InexactFloat64()
+		resp.MarkupAmount = allocation.Breakdown.Markup.Round(4).InexactFloat64()
+		resp.FeeAmount = allocation.Breakdown.Fee.Round(4).InexactFloat64()
+		resp.PaymentUri = GetPaymentURI(order.ChainType, order.Token, order.Asset, order.ActualAmount)
+		resp.QrCodeUrl = GetQrCodeUrl(order.TradeId)
+	}
 	return resp, nil
 }
 
+// IsSupportedChainType 链类型是否支持
+func IsSupportedChainType(chainType string) bool {
+	for _, supported := range SupportedChainTypes {
+		if supported == chainType {
+			return true
+		}
+	}
+	return false
+}
+
+// paymentAllocation 订单收款地址和金额的分配结果
+type paymentAllocation struct {
+	Token         string             // 收款地址
+	Amount        float64            // 应付金额
+	Breakdown     PriceBreakdown     // 应付金额明细
+	DerivedWallet *mdb.WalletAddress // 派生模式下为订单派生的地址
+	LockWalletId  uint64             // 预占金额锁的钱包ID
+	LockChainKey  string             // 预占金额锁的链标识
+}
+
+// release 订单保存失败时释放本次预占的金额和派生地址
+// 只删除本次分配的金额锁，并发选择链失败时不影响同一订单已生效的分配
+func (a *paymentAllocation) release(tradeId string) {
+	data.ReleaseAmountLock(a.LockWalletId, tradeId, a.Amount, a.LockChainKey)
+	deleteDerivedWallet(a.DerivedWallet)
+}
+
+// allocatePayment 按链和支付币种计算应付金额，分配收款地址并预占金额
+func allocatePayment(tradeId string, chainType string, asset string, usdt decimal.Decimal, markupPercent float64) (*paymentAllocation, error) {
+	// 原生币必须与链匹配
+	if asset != mdb.AssetUSDT {
+		chainService := blockchain.GetChainService(chainType)
+		if chainService == nil || chainService.GetNativeSymbol() != asset {
+			return nil, constant.AssetNotSupportedErr
+		}
+	}
+	// 原生币按价格从USDT换算
+	assetPrice := decimal.NewFromInt(1)
+	if asset != mdb.AssetUSDT {
+		// 价格过期时按配置暂停下单，避免按过时价格收款
+		if config.GetRateStaleBlock() && config.IsNativeRateStale(asset) {
+			return nil, constant.RateStaleErr
+		}
+		nativeRate := config.GetNativeRate(asset)
+		if nativeRate <= 0 {
+			return nil, constant.RateAmountErr
+		}
+		assetPrice = decimal.NewFromFloat(nativeRate)
+	}
+	// 计入汇率加价和链手续费，并按配置取整
+	breakdown := CalculatePayAmount(usdt, chainType, asset, assetPrice, markupPercent)
+	if breakdown.Total.Cmp(decimal.NewFromFloat(UsdtMinimumPaymentAmount)) == -1 {
+		return nil, constant.PayAmountErr
+	}
+	lockChainKey := data.GetLockChainKey(chainType, asset)
+
+	// 金额按链标识的精度保留小数，与金额锁的规范化保持一致，避免12.31和12.3100不匹配
+	decimals := data.GetAmountDecimals(lockChainKey)
+	amount := math.MustParsePrecFloat64(breakdown.Total.InexactFloat64(), int(decimals))
+	allocation := &paymentAllocation{
+		Breakdown:    breakdown,
+		LockChainKey: lockChainKey,
+	}
+	if xpub := getDeriveXpub(chainType); xpub != "" {
+		// 派生模式：每单独立地址，按地址匹配，无需递增金额
+		derivedWallet, err := DeriveOrderAddress(chainType, xpub, tradeId)
+		if err != nil {
+			log.Sugar.Errorf("[%s] 派生收款地址失败: %v", chainType, err)
+			return nil, constant.NotAvailableWalletAddress
+		}
+		allocation.DerivedWallet = derivedWallet
+		allocation.Token = derivedWallet.Token
+		allocation.Amount = amount
+		allocation.LockWalletId = derivedWallet.ID
+		// 派生地址独占，仍登记金额锁供监听任务筛选待支付地址
+		reserved, err := data.ReserveAmountLock(derivedWallet.ID, tradeId, amount, lockChainKey, config.GetOrderExpirationTimeDuration())
+		if err != nil || !reserved {
+			deleteDerivedWallet(derivedWallet)
+			if err != nil {
+				return nil, err
+			}
+			return nil, constant.NotAvailableAmountErr
+		}
+		return allocation, nil
+	}
+
+	// 检查是否有可用钱包，根据链类型
+	walletAddress, err := data.GetAvailablePoolWalletAddressByChainType(chainType)
+	if err != nil {
+		return nil, err
+	}
+	if len(walletAddress) <= 0 {
+		return nil, constant.NotAvailableWalletAddress
+	}
+	// 递增步长按价格换算为与 UsdtAmountPerIncrement 等值的币量，原生币订单的调整金额与稳定币一致
+	step := decimal.NewFromFloat(UsdtAmountPerIncrement).Div(assetPrice).RoundCeil(decimals)
+	allocation.LockWalletId, allocation.Token, allocation.Amount, err = CalculateAvailableWalletAndAmount(amount, step, walletAddress, lockChainKey, tradeId)
+	if err != nil {
+		return nil, err
+	}
+	if allocation.Token == "" {
+		return nil, constant.NotAvailableAmountErr
+	}
+	return allocation, nil
+}
+
+// allocateOpenAmountPayment 为开放金额订单分配收款地址
+// 到账金额不固定，无法按金额区分同一地址上的订单，只能使用每单独立的派生地址
+func allocateOpenAmountPayment(tradeId string, chainType string) (*paymentAllocation, error) {
+	xpub := getDeriveXpub(chainType)
+	if xpub == "" {
+		return nil, constant.OpenAmountNotAvailableErr
+	}
+	derivedWallet, err := DeriveOrderAddress(chainType, xpub, tradeId)
+	if err != nil {
+		log.Sugar.Errorf("[%s] 派生收款地址失败: %v", chainType, err)
+		return nil, constant.NotAvailableWalletAddress
+	}
+	// 登记金额为0的金额锁，供监听任务筛选待支付地址
+	lockChainKey := data.GetLockChainKey(chainType, mdb.AssetUSDT)
+	reserved, err := data.ReserveAmountLock(derivedWallet.ID, tradeId, 0, lockChainKey, config.GetOrderExpirationTimeDuration())
+	if err != nil || !reserved {
+		deleteDerivedWallet(derivedWallet)
+		if err != nil {
+			return nil, err
+		}
+		return nil, constant.NotAvailableAmountErr
+	}
+	return &paymentAllocation{
+		Token:         derivedWallet.Token,
+		DerivedWallet: derivedWallet,
+		LockWalletId:  derivedWallet.ID,
+		LockChainKey:  lockChainKey,
+	}, nil
+}
+
+// deleteDerivedWallet 订单创建失败时删除已登记的派生地址，派生索引不会被复用
+func deleteDerivedWallet(wallet *mdb.WalletAddress) {
+	if wallet != nil {
+		data.DeleteWalletAddressById(wallet.ID)
+	}
+}
+
 // OrderProcessing 成功处理订单
 func OrderProcessing(req *request.OrderProcessingRequest) error {
 	tx := dao.Mdb.Begin()
@@ -149,13 +364,6 @@ func OrderProcessing(req *request.OrderProcessingRequest) error {
 		return constant.OrderBlockAlreadyProcess
 	}
 
-	// 获取订单信息以获得链类型
-	order, err := data.GetOrderInfoByTradeId(req.TradeId)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
 	// 标记订单成功
 	err = data.OrderSuccessWithTransaction(tx, req)
 	if err != nil {
@@ -169,8 +377,16 @@ func OrderProcessing(req *request.OrderProcessingRequest) error {
 		return err
 	}
 
+	// 推送支付成功事件，收银台实时跳转
+	event.PublishStage(req.TradeId, mdb.StatusPaySuccess, event.StagePaid)
+
+	// 订单已支付，派生地址不再需要监听
+	if err = data.DisableDerivedWalletAddressByTradeId(req.TradeId); err != nil {
+		log.Sugar.Warnf("[%s] 禁用订单派生地址失败: %v", req.TradeId, err)
+	}
+
 	// 提交事务后再解锁交易，避免SQLite写锁冲突
-	err = data.UnLockTransactionWithChainType(req.Token, req.Amount, order.ChainType)
+	err = data.UnLockTransactionByTradeId(req.TradeId)
 	if err != nil {
 		// 缓存解锁失败不影响订单处理结果，只记录错误
 		// 缓存会自动过期
@@ -180,44 +396,34 @@ func OrderProcessing(req *request.OrderProcessingRequest) error {
 	return nil
 }
 
-// CalculateAvailableWalletAndAmount 计算可用钱包地址和金额
-func CalculateAvailableWalletAndAmount(amount float64, walletAddress []mdb.WalletAddress, chainType string) (string, float64, error) {
-	availableToken := ""
-	availableAmount := amount
-	calculateAvailableWalletFunc := func(amount float64) (string, error) {
-		availableWallet := ""
+// CalculateAvailableWalletAndAmount 计算并预占可用钱包地址和金额，返回预占的钱包ID、地址和金额，chainType 为 data.GetLockChainKey 生成的链标识
+// 一次查询该链所有已锁定金额，按配置的分配策略以 step 为间隔依次尝试空闲金额，由唯一索引保证并发下只有一个订单预占成功
+func CalculateAvailableWalletAndAmount(amount float64, step decimal.Decimal, walletAddress []mdb.WalletAddress, chainType string, tradeId string) (uint64, string, float64, error) {
+	locked, err := data.GetLockedAmountsByChainType(chainType)
+	if err != nil {
+		return 0, "", 0, err
+	}
+
+	decimals := int(data.GetAmountDecimals(chainType))
+	for _, candidate := range GetAmountStrategy(step).Candidates(decimal.NewFromFloat(amount)) {
+		// 确保金额精度与锁的规范化一致
+		candidateAmount := math.MustParsePrecFloat64(candidate.InexactFloat64(), decimals)
+		normalized := data.NormalizeAmount(candidateAmount, chainType)
 		for _, address := range walletAddress {
-			token := address.Token
-			result, err := data.GetTradeIdByWalletAddressAndAmountAndChainType(token, amount, chainType)
+			if locked[address.ID][normalized] {
+				continue
+			}
+			reserved, err := data.ReserveAmountLock(address.ID, tradeId, candidateAmount, chainType, config.GetOrderExpirationTimeDuration())
 			if err != nil {
-				return "", err
+				return 0, "", 0, err
 			}
-			if result == "" {
-				availableWallet = token
-				break
+			// 已被其他实例抢先占用，继续尝试下一个
+			if reserved {
+				return address.ID, address.Token, candidateAmount, nil
 			}
 		}
-		return availableWallet, nil
 	}
-	for i := 0; i < IncrementalMaximumNumber; i++ {
-		token, err := calculateAvailableWalletFunc(availableAmount)
-		if err != nil {
-			return "", 0, err
-		}
-		// 拿不到可用钱包就累加金额
-		if token == "" {
-			decimalOldAmount := decimal.NewFromFloat(availableAmount)
-			decimalIncr := decimal.NewFromFloat(UsdtAmountPerIncrement)
-			// 确保累加后的金额也保持4位小数精度
-			availableAmount = math.MustParsePrecFloat64(decimalOldAmount.Add(decimalIncr).InexactFloat64(), 4)
-			continue
-		}
-		availableToken = token
-		break
-	}
-	// 确保最终返回的金额保持4位小数精度
-	availableAmount = math.MustParsePrecFloat64(availableAmount, 4)
-	return availableToken, availableAmount, nil
+	return 0, "", amount, nil
 }
 
 // GenerateCode 订单号生成
@@ -253,7 +459,7 @@ func CheckAndUpdateOrderExpiration(order *mdb.Orders) error {
 	}
 
 	// 计算订单过期时间
-	expirationTime := order.CreatedAt.AddMinutes(config.GetOrderExpirationTime())
+	expirationTime := data.GetOrderExpirationTime(order)
 	currentTime := carbon.Now()
 
 	// 如果当前时间已超过过期时间，立即更新订单状态
@@ -264,14 +470,20 @@ func CheckAndUpdateOrderExpiration(order *mdb.Orders) error {
 			return err
 		}
 
+		// 订单已过期，派生地址不再需要监听
+		if err = data.DisableDerivedWalletAddressByTradeId(order.TradeId); err != nil {
+			log.Sugar.Warnf("[%s] 禁用订单派生地址失败: %v", order.TradeId, err)
+		}
+
 		// 解锁交易缓存
-		err = data.UnLockTransactionWithChainType(order.Token, order.ActualAmount, order.ChainType)
+		err = data.UnLockTransactionByTradeId(order.TradeId)
 		if err != nil {
 			// 缓存解锁失败不影响订单过期状态，缓存会自动过期
 		}
 
 		// 更新内存中的订单状态
 		order.Status = mdb.StatusExpired
+		event.PublishStage(order.TradeId, mdb.StatusExpired, event.StageExpired)
 
 		// 如果订单设置了回调地址，发送过期通知
 		if order.NotifyUrl != "" {
@@ -1,12 +1,23 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
 
+	"github.com/assimon/luuu/blockchain"
 	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/event"
+	"github.com/assimon/luuu/model/dao"
 	"github.com/assimon/luuu/model/data"
 	"github.com/assimon/luuu/model/mdb"
 	"github.com/assimon/luuu/model/response"
+	"github.com/assimon/luuu/mq/handle"
+	"github.com/assimon/luuu/util/constant"
+	"github.com/assimon/luuu/util/json"
+	"github.com/golang-module/carbon/v2"
+	"github.com/shopspring/decimal"
 )
 
 // GetCheckoutCounterByTradeId 获取收银台详情，通过订单
@@ -16,7 +27,7 @@ func GetCheckoutCounterByTradeId(tradeId string) (*response.CheckoutCounterRespo
 		return nil, err
 	}
 	if orderInfo.ID <= 0 {
-		return nil, errors.New("订单不存在！")
+		return nil, constant.OrderNotExists
 	}
 
 	// 实时检查订单是否已过期
@@ -27,24 +38,269 @@ func GetCheckoutCounterByTradeId(tradeId string) (*response.CheckoutCounterRespo
 
 	// 检查订单状态
 	if orderInfo.Status != mdb.StatusWaitPay {
-		return nil, errors.New("不存在待支付订单或已过期！")
-	}
-
-	// 获取钱包备注信息
-	walletInfo, _ := data.GetWalletAddressByTokenAndChainType(orderInfo.Token, orderInfo.ChainType)
-	tokenRemark := ""
-	if walletInfo != nil && walletInfo.ID > 0 {
-		tokenRemark = walletInfo.Remark
+		return nil, constant.OrderNotWaitPayErr
 	}
 
 	resp := &response.CheckoutCounterResponse{
 		TradeId:        orderInfo.TradeId,
+		Amount:         orderInfo.Amount,
+		Currency:       orderInfo.Currency,
 		ActualAmount:   orderInfo.ActualAmount,
 		Token:          orderInfo.Token,
-		TokenRemark:    tokenRemark,
 		ChainType:      orderInfo.ChainType,
-		ExpirationTime: orderInfo.CreatedAt.AddMinutes(config.GetOrderExpirationTime()).TimestampWithMillisecond(),
+		Asset:          orderInfo.Asset,
+		AmountMode:     orderInfo.AmountMode,
+		ExpirationTime: data.GetOrderExpirationTime(orderInfo).TimestampWithMillisecond(),
 		RedirectUrl:    orderInfo.RedirectUrl,
+		Theme:          orderInfo.Theme,
+	}
+
+	// 开放订单未选择链时返回可选的支付网络
+	if orderInfo.ChainType == "" {
+		resp.AvailableChains, err = getOrderAvailableChainTypes(orderInfo)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	// 获取钱包备注信息
+	walletInfo, _ := data.GetWalletAddressByTokenAndChainType(orderInfo.Token, orderInfo.ChainType)
+	if walletInfo != nil && walletInfo.ID > 0 {
+		resp.TokenRemark = walletInfo.Remark
 	}
+
+	resp.PaymentUri = GetPaymentURI(orderInfo.ChainType, orderInfo.Token, orderInfo.Asset, orderInfo.ActualAmount)
+	resp.WalletLinks = GetWalletLinks(orderInfo.ChainType, resp.PaymentUri, orderInfo.TradeId)
 	return resp, nil
 }
+
+// GetCheckoutApiByTradeId 获取收银台详情及链展示信息，供自建前端使用
+func GetCheckoutApiByTradeId(tradeId string) (*response.CheckoutApiResponse, error) {
+	checkout, err := GetCheckoutCounterByTradeId(tradeId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &response.CheckoutApiResponse{
+		CheckoutCounterResponse: checkout,
+		ChainOptions:            make([]response.ChainOption, 0, len(checkout.AvailableChains)),
+	}
+	remaining := (checkout.ExpirationTime - carbon.Now().TimestampWithMillisecond()) / 1000
+	if remaining > 0 {
+		resp.RemainingSeconds = remaining
+	}
+	for _, chainType := range checkout.AvailableChains {
+		resp.ChainOptions = append(resp.ChainOptions, response.ChainOption{
+			ChainType: chainType,
+			Name:      GetChainMeta(chainType).Name,
+		})
+	}
+	// 开放订单未选择链时没有链信息和二维码
+	if checkout.ChainType == "" {
+		return resp, nil
+	}
+	meta := GetChainMeta(checkout.ChainType)
+	resp.ChainName = meta.Name
+	resp.TokenSymbol = checkout.Asset
+	resp.TokenDecimals = GetAssetDecimals(checkout.ChainType, checkout.Asset)
+	resp.AcceptedTokens = []string{checkout.Asset}
+	if checkout.Asset == mdb.AssetUSDT {
+		resp.AcceptedTokens = meta.Stablecoins
+	}
+	resp.ExplorerTxUrl = meta.ExplorerTxUrl
+	resp.QrCodeUrl = GetQrCodeUrl(checkout.TradeId)
+	return resp, nil
+}
+
+// 二维码内容
+const (
+	QrContentUri     = "uri"     // 链标准支付链接，链不支持时使用收款地址（默认）
+	QrContentAddress = "address" // 收款地址
+)
+
+// GetOrderQrContent 获取待支付订单的二维码内容
+func GetOrderQrContent(tradeId string, content string) (string, error) {
+	order, err := GetOrderInfoByTradeId(tradeId)
+	if err != nil {
+		return "", err
+	}
+	if order.Status != mdb.StatusWaitPay {
+		return "", constant.OrderNotWaitPayErr
+	}
+	if order.ChainType == "" {
+		return "", constant.OrderChainNotSelectedErr
+	}
+	if content != QrContentAddress {
+		if uri := GetPaymentURI(order.ChainType, order.Token, order.Asset, order.ActualAmount); uri != "" {
+			return uri, nil
+		}
+	}
+	return order.Token, nil
+}
+
+// GetQrCodeUrl 订单二维码图片地址
+func GetQrCodeUrl(tradeId string) string {
+	return fmt.Sprintf("%s/pay/qrcode/%s.png", config.GetAppUri(), tradeId)
+}
+
+// GetPaymentURI 生成订单的链标准支付链接，链服务不支持或开放金额订单（金额为0）时返回空
+func GetPaymentURI(chainType string, address string, asset string, amount float64) string {
+	if amount <= 0 {
+		return ""
+	}
+	provider, ok := blockchain.GetChainService(chainType).(blockchain.PaymentURIProvider)
+	if !ok {
+		return ""
+	}
+	return provider.GetPaymentURI(address, asset, amount)
+}
+
+// GetWalletLinks 生成在钱包App中打开的链接
+// MetaMask 直接打开转账，TronLink 在内置浏览器打开收银台，Phantom 通过 solana: 链接唤起
+func GetWalletLinks(chainType string, paymentUri string, tradeId string) []response.WalletLink {
+	links := make([]response.WalletLink, 0)
+	if paymentUri == "" {
+		return links
+	}
+	switch chainType {
+	case mdb.ChainTypeTRC20:
+		checkoutUrl := fmt.Sprintf("%s/pay/checkout-counter/%s", config.GetAppUri(), tradeId)
+		param, _ := json.Cjson.MarshalToString(map[string]string{
+			"url":      checkoutUrl,
+			"action":   "open",
+			"protocol": "tronlink",
+			"version":  "1.0",
+		})
+		links = append(links, response.WalletLink{
+			Name: "TronLink",
+			Url:  "tronlinkoutside://pull.activity?param=" + url.QueryEscape(param),
+		})
+	case mdb.ChainTypeERC20, mdb.ChainTypeBEP20, mdb.ChainTypePOLYGON, mdb.ChainTypeARB:
+		links = append(links, response.WalletLink{
+			Name: "MetaMask",
+			Url:  "https://metamask.app.link/send/" + strings.TrimPrefix(paymentUri, "ethereum:"),
+		})
+	case mdb.ChainTypeSOLANA:
+		links = append(links, response.WalletLink{
+			Name: "Phantom",
+			Url:  paymentUri,
+		})
+	}
+	return links
+}
+
+// GetAvailableChainTypes 获取可收款的链类型，即有可用收款池钱包或配置了派生公钥的链
+func GetAvailableChainTypes() ([]string, error) {
+	chainTypes := make([]string, 0)
+	for _, chainType := range SupportedChainTypes {
+		if blockchain.GetChainService(chainType) == nil {
+			continue
+		}
+		if getDeriveXpub(chainType) != "" {
+			chainTypes = append(chainTypes, chainType)
+			continue
+		}
+		walletAddress, err := data.GetAvailablePoolWalletAddressByChainType(chainType)
+		if err != nil {
+			return nil, err
+		}
+		if len(walletAddress) > 0 {
+			chainTypes = append(chainTypes, chainType)
+		}
+	}
+	return chainTypes, nil
+}
+
+// GetOpenAmountChainTypes 获取可创建开放金额订单的链类型，即配置了派生公钥的链
+func GetOpenAmountChainTypes() []string {
+	chainTypes := make([]string, 0)
+	for _, chainType := range SupportedChainTypes {
+		if blockchain.GetChainService(chainType) != nil && getDeriveXpub(chainType) != "" {
+			chainTypes = append(chainTypes, chainType)
+		}
+	}
+	return chainTypes
+}
+
+// getOrderAvailableChainTypes 开放订单可选择的链类型，开放金额订单只能选择支持地址派生的链
+func getOrderAvailableChainTypes(order *mdb.Orders) ([]string, error) {
+	if order.AmountMode == mdb.AmountModeOpen {
+		return GetOpenAmountChainTypes(), nil
+	}
+	return GetAvailableChainTypes()
+}
+
+// SelectOrderChain 开放订单由付款人选择链，分配收款地址和唯一金额，并从此时开始计算过期时间
+func SelectOrderChain(tradeId string, chainType string) (*response.CheckoutCounterResponse, error) {
+	order, err := GetOrderInfoByTradeId(tradeId)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != mdb.StatusWaitPay {
+		return nil, constant.OrderNotWaitPayErr
+	}
+	if order.ChainType != "" {
+		return nil, constant.OrderChainSelectedErr
+	}
+	chainType = strings.ToUpper(chainType)
+	availableChains, err := getOrderAvailableChainTypes(order)
+	if err != nil {
+		return nil, err
+	}
+	available := false
+	for _, availableChain := range availableChains {
+		if availableChain == chainType {
+			available = true
+			break
+		}
+	}
+	if !available {
+		return nil, constant.ChainNotAvailableErr
+	}
+
+	// 按下单时的汇率快照换算，按稳定币计价的订单不加收汇率加价
+	usdt := decimal.NewFromFloat(order.Amount).Div(decimal.NewFromFloat(order.Rate))
+	markupPercent := config.GetRateMarkupPercent()
+	if order.Currency == mdb.AssetUSDT {
+		markupPercent = 0
+	}
+	var allocation *paymentAllocation
+	if order.AmountMode == mdb.AmountModeOpen {
+		allocation, err = allocateOpenAmountPayment(order.TradeId, chainType)
+	} else {
+		allocation, err = allocatePayment(order.TradeId, chainType, order.Asset, usdt, markupPercent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	selected, err := data.SelectOrderChain(order.ID, chainType, allocation.Token, allocation.Amount)
+	if err != nil || !selected {
+		// 并发选择时只有一个请求生效
+		allocation.release(order.TradeId)
+		if err != nil {
+			return nil, err
+		}
+		return nil, constant.OrderChainSelectedErr
+	}
+
+	// 选择链后重新计算过期时间
+	ctx := context.Background()
+	dao.EnqueueTaskDelay(ctx, "default", handle.QueueOrderExpiration, order.TradeId, config.GetOrderExpirationTimeDuration(), 3)
+	return GetCheckoutCounterByTradeId(order.TradeId)
+}
+
+// GetOrderStatusEvent 查询订单当前状态并转换为推送事件
+func GetOrderStatusEvent(tradeId string) (event.OrderEvent, error) {
+	order, err := GetOrderInfoByTradeId(tradeId)
+	if err != nil {
+		return event.OrderEvent{}, err
+	}
+	stage := event.StageWaiting
+	switch order.Status {
+	case mdb.StatusPaySuccess:
+		stage = event.StagePaid
+	case mdb.StatusExpired:
+		stage = event.StageExpired
+	}
+	return event.OrderEvent{TradeId: order.TradeId, Status: order.Status, Stage: stage}, nil
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/model/data"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/assimon/luuu/model/request"
+	"github.com/assimon/luuu/model/response"
+	"github.com/assimon/luuu/util/constant"
+	"github.com/assimon/luuu/util/page"
+	"github.com/golang-module/carbon/v2"
+)
+
+const (
+	paymentLinkSlugLength   = 12
+	paymentLinkSlugAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+)
+
+// CreatePaymentLink 创建支付链接
+func CreatePaymentLink(req *request.CreatePaymentLinkRequest) (*response.PaymentLinkResponse, error) {
+	if req.Amount < 0 || req.MaxUses < 0 {
+		return nil, constant.PaymentLinkParamsErr
+	}
+	currency, err := normalizePaymentLinkCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	chainTypes, err := normalizePaymentLinkChains(req.ChainTypes)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := parsePaymentLinkExpiresAt(req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	link := &mdb.PaymentLink{
+		Title:       req.Title,
+		Description: req.Description,
+		Amount:      req.Amount,
+		Currency:    currency,
+		ChainTypes:  strings.Join(chainTypes, ","),
+		MaxUses:     req.MaxUses,
+		ExpiresAt:   expiresAt,
+		Status:      mdb.PaymentLinkStatusEnable,
+		NotifyUrl:   req.NotifyUrl,
+		RedirectUrl: req.RedirectUrl,
+	}
+	// 标识随机生成，极小概率冲突时重新生成
+	for i := 0; i < 3; i++ {
+		link.Slug, err = generatePaymentLinkSlug()
+		if err != nil {
+			return nil, err
+		}
+		err = data.CreatePaymentLink(link)
+		if !data.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buildPaymentLinkResponse(link), nil
+}
+
+// UpdatePaymentLink 修改支付链接
+func UpdatePaymentLink(req *request.UpdatePaymentLinkRequest) (*response.PaymentLinkResponse, error) {
+	link, err := getPaymentLink(req.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if req.MaxUses < 0 {
+		return nil, constant.PaymentLinkParamsErr
+	}
+	expiresAt, err := parsePaymentLinkExpiresAt(req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	link.Title = req.Title
+	link.Description = req.Description
+	link.MaxUses = req.MaxUses
+	link.ExpiresAt = expiresAt
+	link.Status = req.Status
+	link.NotifyUrl = req.NotifyUrl
+	link.RedirectUrl = req.RedirectUrl
+	if err = data.UpdatePaymentLink(link); err != nil {
+		return nil, err
+	}
+	return buildPaymentLinkResponse(link), nil
+}
+
+// ChangePaymentLinkStatus 启用或禁用支付链接
+func ChangePaymentLinkStatus(slug string, status int) error {
+	link, err := getPaymentLink(slug)
+	if err != nil {
+		return err
+	}
+	return data.ChangePaymentLinkStatus(link.ID, status)
+}
+
+// DeletePaymentLink 删除支付链接
+func DeletePaymentLink(slug string) error {
+	link, err := getPaymentLink(slug)
+	if err != nil {
+		return err
+	}
+	return data.DeletePaymentLinkById(link.ID)
+}
+
+// GetPaymentLink 获取支付链接详情
+func GetPaymentLink(slug string) (*response.PaymentLinkResponse, error) {
+	link, err := getPaymentLink(slug)
+	if err != nil {
+		return nil, err
+	}
+	return buildPaymentLinkResponse(link), nil
+}
+
+// ListPaymentLinks 分页获取支付链接
+func ListPaymentLinks(pageNum int, pageSize int) ([]*response.PaymentLinkResponse, page.Pagination, error) {
+	if pageNum <= 0 {
+		pageNum = page.DefaultPage
+	}
+	if pageSize <= 0 {
+		pageSize = page.DefaultPageSize
+	}
+	if pageSize > page.MaxPageSize {
+		pageSize = page.MaxPageSize
+	}
+	links, total, err := data.GetPaymentLinks(pageNum, pageSize)
+	if err != nil {
+		return nil, page.Pagination{}, err
+	}
+	list := make([]*response.PaymentLinkResponse, 0, len(links))
+	for i := range links {
+		list = append(list, buildPaymentLinkResponse(&links[i]))
+	}
+	return list, page.GetPagination(pageNum, pageSize, total), nil
+}
+
+// GetPaymentLinkPage 获取支付链接页面数据，链接不可用时返回错误
+func GetPaymentLinkPage(slug string) (*response.PaymentLinkPageResponse, error) {
+	link, err := getUsablePaymentLink(slug)
+	if err != nil {
+		return nil, err
+	}
+	chainTypes, err := getPaymentLinkAvailableChains(link)
+	if err != nil {
+		return nil, err
+	}
+	resp := &response.PaymentLinkPageResponse{
+		Slug:        link.Slug,
+		Title:       link.Title,
+		Description: link.Description,
+		Amount:      link.Amount,
+		Currency:    link.Currency,
+		Chains:      make([]response.ChainOption, 0, len(chainTypes)),
+	}
+	for _, chainType := range chainTypes {
+		resp.Chains = append(resp.Chains, response.ChainOption{
+			ChainType: chainType,
+			Name:      GetChainMeta(chainType).Name,
+		})
+	}
+	return resp, nil
+}
+
+// PayPaymentLink 付款人通过支付链接下单，每次生成一笔独立订单
+// amount 仅在链接未设置固定金额时使用，chainType 须为链接允许且当前可收款的链
+func PayPaymentLink(slug string, amount float64, chainType string) (*response.CreateTransactionResponse, error) {
+	link, err := getUsablePaymentLink(slug)
+	if err != nil {
+		return nil, err
+	}
+	if link.Amount > 0 {
+		amount = link.Amount
+	}
+	if amount <= 0 {
+		return nil, constant.PayAmountErr
+	}
+	chainTypes, err := getPaymentLinkAvailableChains(link)
+	if err != nil {
+		return nil, err
+	}
+	chainType = strings.ToUpper(chainType)
+	if !containsString(chainTypes, chainType) {
+		return nil, constant.ChainNotAvailableErr
+	}
+	req := &request.CreateTransactionRequest{
+		OrderId:       "PL" + GenerateCode(),
+		Amount:        amount,
+		NotifyUrl:     link.NotifyUrl,
+		RedirectUrl:   link.RedirectUrl,
+		ChainType:     chainType,
+		Currency:      link.Currency,
+		PaymentLinkId: link.ID,
+	}
+	if link.Currency == mdb.AssetUSDT {
+		req.Currency = ""
+		req.AmountCurrency = AmountCurrencyUSDT
+	}
+	return CreateTransaction(req)
+}
+
+// GetPaymentLinkUrl 支付链接页面地址
+func GetPaymentLinkUrl(slug string) string {
+	return fmt.Sprintf("%s/pay/link/%s", config.GetAppUri(), slug)
+}
+
+func getPaymentLink(slug string) (*mdb.PaymentLink, error) {
+	link, err := data.GetPaymentLinkBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	if link.ID <= 0 {
+		return nil, constant.PaymentLinkNotExists
+	}
+	return link, nil
+}
+
+// getUsablePaymentLink 获取可下单的支付链接：已启用、未过期且未达到最大支付次数
+// 支付次数按已支付订单计算，同时打开的多个付款人可能使支付次数略超过上限
+func getUsablePaymentLink(slug string) (*mdb.PaymentLink, error) {
+	link, err := getPaymentLink(slug)
+	if err != nil {
+		return nil, err
+	}
+	if link.Status != mdb.PaymentLinkStatusEnable {
+		return nil, constant.PaymentLinkUnavailableErr
+	}
+	if link.ExpiresAt != nil && link.ExpiresAt.Lt(carbon.Now()) {
+		return nil, constant.PaymentLinkUnavailableErr
+	}
+	if link.MaxUses > 0 && link.UseCount >= link.MaxUses {
+		return nil, constant.PaymentLinkExhaustedErr
+	}
+	return link, nil
+}
+
+// getPaymentLinkAvailableChains 链接允许的链中当前可收款的链
+func getPaymentLinkAvailableChains(link *mdb.PaymentLink) ([]string, error) {
+	available, err := GetAvailableChainTypes()
+	if err != nil {
+		return nil, err
+	}
+	if link.ChainTypes == "" {
+		return available, nil
+	}
+	allowed := strings.Split(link.ChainTypes, ",")
+	chainTypes := make([]string, 0, len(allowed))
+	for _, chainType := range available {
+		if containsString(allowed, chainType) {
+			chainTypes = append(chainTypes, chainType)
+		}
+	}
+	return chainTypes, nil
+}
+
+// normalizePaymentLinkCurrency 校验金额币种，为空时使用 default_currency
+func normalizePaymentLinkCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return config.GetDefaultCurrency(), nil
+	}
+	if currency != mdb.AssetUSDT && !config.IsFiatCurrencySupported(currency) {
+		return "", constant.CurrencyNotSupportedErr
+	}
+	return currency, nil
+}
+
+// normalizePaymentLinkChains 校验允许的链类型
+func normalizePaymentLinkChains(chainTypes string) ([]string, error) {
+	result := make([]string, 0)
+	for _, chainType := range strings.Split(chainTypes, ",") {
+		chainType = strings.ToUpper(strings.TrimSpace(chainType))
+		if chainType == "" || containsString(result, chainType) {
+			continue
+		}
+		if !IsSupportedChainType(chainType) {
+			return nil, constant.ChainNotAvailableErr
+		}
+		result = append(result, chainType)
+	}
+	return result, nil
+}
+
+func parsePaymentLinkExpiresAt(timestamp int64) (*carbon.Time, error) {
+	if timestamp <= 0 {
+		return nil, nil
+	}
+	expiresAt := carbon.CreateFromTimestamp(timestamp)
+	if expiresAt.Lt(carbon.Now()) {
+		return nil, constant.PaymentLinkParamsErr
+	}
+	return &carbon.Time{Carbon: expiresAt}, nil
+}
+
+func generatePaymentLinkSlug() (string, error) {
+	slug := make([]byte, paymentLinkSlugLength)
+	max := big.NewInt(int64(len(paymentLinkSlugAlphabet)))
+	for i := range slug {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		slug[i] = paymentLinkSlugAlphabet[n.Int64()]
+	}
+	return string(slug), nil
+}
+
+func buildPaymentLinkResponse(link *mdb.PaymentLink) *response.PaymentLinkResponse {
+	resp := &response.PaymentLinkResponse{
+		Slug:        link.Slug,
+		Url:         GetPaymentLinkUrl(link.Slug),
+		Title:       link.Title,
+		Description: link.Description,
+		Amount:      link.Amount,
+		Currency:    link.Currency,
+		ChainTypes:  make([]string, 0),
+		MaxUses:     link.MaxUses,
+		UseCount:    link.UseCount,
+		Status:      link.Status,
+		NotifyUrl:   link.NotifyUrl,
+		RedirectUrl: link.RedirectUrl,
+		CreatedAt:   link.CreatedAt.Timestamp(),
+	}
+	if link.ChainTypes != "" {
+		resp.ChainTypes = strings.Split(link.ChainTypes, ",")
+	}
+	if link.ExpiresAt != nil {
+		resp.ExpiresAt = link.ExpiresAt.Timestamp()
+	}
+	return resp
+}
+
+func containsString(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
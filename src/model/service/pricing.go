@@ -0,0 +1,40 @@
+package service
+
+import (
+	"github.com/assimon/luuu/config"
+	"github.com/shopspring/decimal"
+)
+
+// PriceBreakdown 订单应付金额明细，单位为支付币种
+type PriceBreakdown struct {
+	Base   decimal.Decimal // 按汇率换算的基础金额
+	Markup decimal.Decimal // 汇率加价
+	Fee    decimal.Decimal // 链固定手续费
+	Total  decimal.Decimal // 按 amount_rounding 取整后的应付金额
+}
+
+// CalculatePayAmount 计算订单应付金额，usdt 为按法币汇率换算的USDT金额，assetPrice 为支付币种的USDT价格（稳定币为1）
+// 应付金额 = 基础金额 + 基础金额 × markupPercent% + 链固定手续费
+func CalculatePayAmount(usdt decimal.Decimal, chainType string, asset string, assetPrice decimal.Decimal, markupPercent float64) PriceBreakdown {
+	breakdown := PriceBreakdown{
+		Base: usdt.Div(assetPrice),
+	}
+	breakdown.Markup = breakdown.Base.Mul(decimal.NewFromFloat(markupPercent)).Div(decimal.NewFromInt(100))
+	// 手续费按USDT配置，原生币订单按价格换算
+	breakdown.Fee = decimal.NewFromFloat(config.GetChainFee(chainType, asset)).Div(assetPrice)
+	breakdown.Total = RoundPayAmount(breakdown.Base.Add(breakdown.Markup).Add(breakdown.Fee))
+	return breakdown
+}
+
+// RoundPayAmount 按 amount_rounding 和 amount_precision 对应付金额取整
+func RoundPayAmount(amount decimal.Decimal) decimal.Decimal {
+	precision := config.GetAmountPrecision()
+	switch config.GetAmountRounding() {
+	case "up":
+		return amount.RoundCeil(precision)
+	case "down":
+		return amount.RoundFloor(precision)
+	default:
+		return amount.Round(precision)
+	}
+}
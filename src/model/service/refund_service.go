@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/assimon/luuu/blockchain"
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/data"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/assimon/luuu/model/request"
+	"github.com/assimon/luuu/model/response"
+	"github.com/assimon/luuu/mq/handle"
+	"github.com/assimon/luuu/notify"
+	"github.com/assimon/luuu/util/constant"
+	"github.com/assimon/luuu/util/log"
+	"github.com/assimon/luuu/util/math"
+	"github.com/assimon/luuu/util/page"
+	"github.com/golang-module/carbon/v2"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	refundNoPrefix = "RF"
+	// 每轮最多核验的退款数，其余的在下一轮处理
+	refundVerifyBatchSize = 100
+)
+
+// CreateRefund 为已支付订单创建退款记录，退款币种和链与订单支付一致，未取消的退款总额不能超过实际支付金额
+func CreateRefund(req *request.CreateRefundRequest) (*response.RefundResponse, error) {
+	order, err := data.GetOrderInfoByTradeId(req.TradeId)
+	if err != nil {
+		return nil, err
+	}
+	if order.ID <= 0 {
+		return nil, constant.OrderNotExists
+	}
+	if order.Status != mdb.StatusPaySuccess {
+		return nil, constant.OrderNotPaidErr
+	}
+	chainService := blockchain.GetChainService(order.ChainType)
+	if chainService == nil {
+		return nil, constant.ChainNotAvailableErr
+	}
+	toAddress := strings.TrimSpace(req.ToAddress)
+	if !chainService.ValidateAddress(toAddress) {
+		return nil, constant.InvalidWalletAddressErr
+	}
+	amount := math.MustParsePrecFloat64(req.Amount, 4)
+	if amount < UsdtMinimumPaymentAmount {
+		return nil, constant.PayAmountErr
+	}
+	asset := order.Asset
+	if asset == "" {
+		asset = mdb.AssetUSDT
+	}
+	notifyUrl := strings.TrimSpace(req.NotifyUrl)
+	if notifyUrl == "" {
+		notifyUrl = order.NotifyUrl
+	}
+
+	refund := &mdb.Refund{
+		RefundNo:        refundNoPrefix + GenerateCode(),
+		TradeId:         order.TradeId,
+		OrderId:         order.OrderId,
+		ChainType:       order.ChainType,
+		Asset:           asset,
+		Amount:          amount,
+		ToAddress:       toAddress,
+		Reason:          strings.TrimSpace(req.Reason),
+		Status:          mdb.RefundStatusPending,
+		NotifyUrl:       notifyUrl,
+		CallBackConfirm: mdb.CallBackConfirmNo,
+	}
+	created, err := data.CreateRefundWithinAmount(refund, order.ActualAmount)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, constant.RefundAmountExceededErr
+	}
+	log.Sugar.Infof("[退款] 创建退款 %s: 订单=%s, 金额=%.4f %s, 退款地址=%s",
+		refund.RefundNo, refund.TradeId, refund.Amount, refund.Asset, refund.ToAddress)
+	return buildRefundResponse(refund), nil
+}
+
+// SubmitRefundTx 提交商户已发出的退款交易哈希并立即核验一次，未确认的交易由定时任务继续核验
+func SubmitRefundTx(refundNo string, txHash string) (*response.RefundResponse, error) {
+	refund, err := getRefund(refundNo)
+	if err != nil {
+		return nil, err
+	}
+	txHash = strings.TrimSpace(txHash)
+	// Solana 交易签名为 Base58 编码区分大小写，其余链为十六进制，统一转为小写
+	if refund.ChainType != mdb.ChainTypeSOLANA {
+		txHash = strings.ToLower(txHash)
+	}
+	if refund.Status != mdb.RefundStatusPending && refund.Status != mdb.RefundStatusFailed {
+		return nil, constant.RefundStatusErr
+	}
+	exist, err := data.GetRefundByTxHash(refund.ChainType, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if exist.ID > 0 && exist.ID != refund.ID {
+		return nil, constant.RefundTxHashUsedErr
+	}
+
+	submitted, err := data.SubmitRefundTxHash(refund.ID, txHash)
+	if err != nil {
+		// 并发提交相同交易哈希时由唯一索引拦截
+		if data.IsDuplicateKeyError(err) {
+			return nil, constant.RefundTxHashUsedErr
+		}
+		return nil, err
+	}
+	if !submitted {
+		return nil, constant.RefundStatusErr
+	}
+	log.Sugar.Infof("[退款] 退款 %s 提交交易哈希 %s", refund.RefundNo, txHash)
+
+	refund, err = getRefund(refundNo)
+	if err != nil {
+		return nil, err
+	}
+	verifyRefund(refund)
+	return GetRefund(refundNo)
+}
+
+// CancelRefund 取消待退款或核验失败的退款，取消后的金额不再计入已退款金额
+func CancelRefund(refundNo string) error {
+	refund, err := getRefund(refundNo)
+	if err != nil {
+		return err
+	}
+	cancelled, err := data.CancelRefundById(refund.ID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return constant.RefundStatusErr
+	}
+	log.Sugar.Infof("[退款] 取消退款 %s", refund.RefundNo)
+	return nil
+}
+
+// GetRefund 获取退款详情
+func GetRefund(refundNo string) (*response.RefundResponse, error) {
+	refund, err := getRefund(refundNo)
+	if err != nil {
+		return nil, err
+	}
+	return buildRefundResponse(refund), nil
+}
+
+// ListRefunds 分页获取退款，tradeId 为空时返回所有订单的退款
+func ListRefunds(tradeId string, status int, pageNum int, pageSize int) ([]*response.RefundResponse, page.Pagination, error) {
+	if pageNum <= 0 {
+		pageNum = page.DefaultPage
+	}
+	if pageSize <= 0 {
+		pageSize = page.DefaultPageSize
+	}
+	if pageSize > page.MaxPageSize {
+		pageSize = page.MaxPageSize
+	}
+	refunds, total, err := data.GetRefunds(tradeId, status, pageNum, pageSize)
+	if err != nil {
+		return nil, page.Pagination{}, err
+	}
+	list := make([]*response.RefundResponse, 0, len(refunds))
+	for i := range refunds {
+		list = append(list, buildRefundResponse(&refunds[i]))
+	}
+	return list, page.GetPagination(pageNum, pageSize, total), nil
+}
+
+// RunRefundVerification 核验所有等待链上确认的退款交易，由定时任务调用
+func RunRefundVerification() {
+	refunds, err := data.GetVerifyingRefunds(refundVerifyBatchSize)
+	if err != nil {
+		log.Sugar.Errorf("[退款] 获取待核验退款失败: %v", err)
+		return
+	}
+	for i := range refunds {
+		verifyRefund(&refunds[i])
+	}
+}
+
+// verifyRefund 在链上核验退款交易：交易成功、转入退款地址的对应币种金额不少于退款金额
+// 交易未查询到或未确认时保持核验中，超过 refund_verify_timeout 后核验失败
+func verifyRefund(refund *mdb.Refund) {
+	if refund.Status != mdb.RefundStatusVerifying || refund.TxHash == "" {
+		return
+	}
+	chainService := blockchain.GetChainService(refund.ChainType)
+	if chainService == nil {
+		failRefund(refund, fmt.Sprintf("不支持的链类型: %s", refund.ChainType))
+		return
+	}
+
+	transfers, err := chainService.GetTransfersByHash(refund.TxHash, refund.ToAddress)
+	if errors.Is(err, blockchain.ErrTransactionFailed) {
+		failRefund(refund, "退款交易执行失败")
+		return
+	}
+	if err != nil {
+		// 查询失败时保持核验中，下一轮重试
+		log.Sugar.Warnf("[退款] 查询退款交易失败 %s: 哈希=%s, 错误=%v", refund.RefundNo, refund.TxHash, err)
+		return
+	}
+
+	order, err := data.GetOrderInfoByTradeId(refund.TradeId)
+	if err != nil {
+		log.Sugar.Errorf("[退款] 获取退款订单失败 %s: %v", refund.RefundNo, err)
+		return
+	}
+
+	received := decimal.Zero
+	fromAddress := ""
+	matched, confirmed, early := false, false, false
+	for _, transfer := range transfers {
+		// 稳定币退款 USDT/USDC 均可，原生币退款只统计原生币转账
+		if (refund.Asset == mdb.AssetUSDT) != (transfer.ContractAddress != "") {
+			continue
+		}
+		// 早于订单创建的转账不可能是该订单的退款，避免用历史转账冒充
+		if transfer.BlockTimestamp > 0 && transfer.BlockTimestamp < order.CreatedAt.TimestampWithMillisecond() {
+			early = true
+			continue
+		}
+		matched = true
+		if transfer.Confirmations <= 0 {
+			continue
+		}
+		confirmed = true
+		received = received.Add(decimal.NewFromFloat(transfer.Amount))
+		fromAddress = transfer.From
+	}
+
+	if early && !matched {
+		failRefund(refund, "退款交易早于订单创建时间")
+		return
+	}
+	if len(transfers) > 0 && !matched {
+		failRefund(refund, fmt.Sprintf("交易中没有转入退款地址的 %s 转账", refund.Asset))
+		return
+	}
+	if !confirmed {
+		if refund.SubmittedAt != nil && carbon.Now().Gt(refund.SubmittedAt.AddHours(config.GetRefundVerifyTimeout())) {
+			failRefund(refund, "超时未查询到已确认的退款交易")
+		}
+		return
+	}
+	if received.Round(4).LessThan(decimal.NewFromFloat(refund.Amount).Round(4)) {
+		failRefund(refund, fmt.Sprintf("转入退款地址的金额 %s 小于退款金额 %.4f", received.Round(4).String(), refund.Amount))
+		return
+	}
+	completeRefund(refund, fromAddress)
+}
+
+// completeRefund 退款核验通过，回调商户并通知管理员
+func completeRefund(refund *mdb.Refund, fromAddress string) {
+	completed, err := data.CompleteRefund(refund.ID, refund.TxHash, fromAddress)
+	if err != nil {
+		log.Sugar.Errorf("[退款] 更新退款状态失败 %s: %v", refund.RefundNo, err)
+		return
+	}
+	// 已被其他实例处理或哈希已重新提交
+	if !completed {
+		return
+	}
+	refund.Status = mdb.RefundStatusCompleted
+	refund.FromAddress = fromAddress
+	refund.CompletedAt = &carbon.Time{Carbon: carbon.Now()}
+	log.Sugar.Infof("[退款] 退款 %s 核验通过: 金额=%.4f %s, 哈希=%s", refund.RefundNo, refund.Amount, refund.Asset, refund.TxHash)
+
+	if refund.NotifyUrl != "" {
+		dao.EnqueueTaskNow(context.Background(), "default", handle.QueueRefundCallback, refund, 5)
+	}
+
+	msgTpl := `【退款完成通知】
+
+退款编号：%s
+订单号：%s
+区块链：%s
+退款币种：%s
+退款金额：%.4f
+退款地址：%s
+退款原因：%s
+
+交易哈希：
+%s
+
+区块链浏览器：
+%s
+
+核验时间：%s`
+	msg := fmt.Sprintf(msgTpl,
+		refund.RefundNo,
+		refund.TradeId,
+		refund.ChainType,
+		refund.Asset,
+		refund.Amount,
+		refund.ToAddress,
+		refund.Reason,
+		refund.TxHash,
+		GetBlockchainExplorerURL(refund.ChainType, refund.TxHash),
+		carbon.Now().ToDateTimeString())
+	notify.SendToBot(msg)
+}
+
+// failRefund 退款核验失败，商户可重新提交交易哈希
+func failRefund(refund *mdb.Refund, failReason string) {
+	failed, err := data.FailRefund(refund.ID, refund.TxHash, failReason)
+	if err != nil {
+		log.Sugar.Errorf("[退款] 更新退款状态失败 %s: %v", refund.RefundNo, err)
+		return
+	}
+	if !failed {
+		return
+	}
+	refund.Status = mdb.RefundStatusFailed
+	refund.FailReason = failReason
+	log.Sugar.Warnf("[退款] 退款 %s 核验失败: 哈希=%s, 原因=%s", refund.RefundNo, refund.TxHash, failReason)
+	notify.SendToBot(fmt.Sprintf("【退款核验失败】\n\n退款编号：%s\n订单号：%s\n区块链：%s\n交易哈希：%s\n原因：%s\n\n请确认后重新提交交易哈希",
+		refund.RefundNo, refund.TradeId, refund.ChainType, refund.TxHash, failReason))
+}
+
+func getRefund(refundNo string) (*mdb.Refund, error) {
+	refund, err := data.GetRefundByNo(refundNo)
+	if err != nil {
+		return nil, err
+	}
+	if refund.ID <= 0 {
+		return nil, constant.RefundNotExists
+	}
+	return refund, nil
+}
+
+func buildRefundResponse(refund *mdb.Refund) *response.RefundResponse {
+	resp := &response.RefundResponse{
+		RefundNo:    refund.RefundNo,
+		TradeId:     refund.TradeId,
+		OrderId:     refund.OrderId,
+		ChainType:   refund.ChainType,
+		Asset:       refund.Asset,
+		Amount:      refund.Amount,
+		ToAddress:   refund.ToAddress,
+		Reason:      refund.Reason,
+		Status:      refund.Status,
+		TxHash:      refund.TxHash,
+		FromAddress: refund.FromAddress,
+		FailReason:  refund.FailReason,
+		NotifyUrl:   refund.NotifyUrl,
+		CreatedAt:   refund.CreatedAt.Timestamp(),
+	}
+	if refund.SubmittedAt != nil {
+		resp.SubmittedAt = refund.SubmittedAt.Timestamp()
+	}
+	if refund.CompletedAt != nil {
+		resp.CompletedAt = refund.CompletedAt.Timestamp()
+	}
+	return resp
+}
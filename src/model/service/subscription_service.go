@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/data"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/assimon/luuu/model/request"
+	"github.com/assimon/luuu/model/response"
+	"github.com/assimon/luuu/mq/handle"
+	"github.com/assimon/luuu/notify"
+	"github.com/assimon/luuu/util/constant"
+	"github.com/assimon/luuu/util/log"
+	"github.com/assimon/luuu/util/page"
+	"github.com/golang-module/carbon/v2"
+)
+
+const (
+	subscriptionNoPrefix = "SUB"
+	// 每轮最多处理的订阅数，其余的在下一轮处理
+	subscriptionBatchSize = 100
+	// 账单订单创建失败（如无可用钱包）时的重试间隔（分钟），不计入逾期次数
+	subscriptionErrorRetryMinutes = 10
+	// 账单到达过期时间仍待支付（如开放订单选择链后重新计时）时再次检查的间隔（分钟）
+	subscriptionInvoiceRecheckMinutes = 5
+)
+
+// 订阅事件
+const (
+	SubscriptionEventInvoice   = "subscription.invoice"
+	SubscriptionEventPaid      = "subscription.paid"
+	SubscriptionEventPastDue   = "subscription.past_due"
+	SubscriptionEventCancelled = "subscription.cancelled"
+)
+
+// CreateSubscription 创建订阅，到达首期账单时间后由定时任务生成账单
+func CreateSubscription(req *request.CreateSubscriptionRequest) (*response.SubscriptionResponse, error) {
+	currency, err := normalizePaymentLinkCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if req.Amount < FiatMinimumPaymentAmount {
+		return nil, constant.PayAmountErr
+	}
+	chainType := strings.ToUpper(strings.TrimSpace(req.ChainType))
+	if chainType == "" {
+		chainType = mdb.ChainTypeOpen
+	}
+	if chainType != mdb.ChainTypeOpen && !IsSupportedChainType(chainType) {
+		return nil, constant.ChainNotAvailableErr
+	}
+	intervalCount := req.IntervalCount
+	if intervalCount <= 0 {
+		intervalCount = 1
+	}
+	startAt := carbon.Now()
+	if req.StartAt > 0 {
+		startAt = carbon.CreateFromTimestamp(req.StartAt)
+	}
+	subscription := &mdb.Subscription{
+		SubscriptionNo: subscriptionNoPrefix + GenerateCode(),
+		CustomerId:     req.CustomerId,
+		CustomerEmail:  strings.TrimSpace(req.CustomerEmail),
+		TelegramChatId: req.TelegramChatId,
+		Title:          req.Title,
+		Amount:         req.Amount,
+		Currency:       currency,
+		ChainType:      chainType,
+		IntervalUnit:   strings.ToUpper(req.IntervalUnit),
+		IntervalCount:  intervalCount,
+		StartAt:        carbon.Time{Carbon: startAt},
+		DueAt:          carbon.Time{Carbon: startAt},
+		NextRunAt:      carbon.Time{Carbon: startAt},
+		NotifyUrl:      req.NotifyUrl,
+		RedirectUrl:    req.RedirectUrl,
+		Status:         mdb.SubscriptionStatusActive,
+	}
+	if err = data.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	log.Sugar.Infof("[订阅] 创建订阅 %s: 客户=%s, 金额=%.2f %s, 周期=%d %s",
+		subscription.SubscriptionNo, subscription.CustomerId, subscription.Amount, subscription.Currency,
+		subscription.IntervalCount, subscription.IntervalUnit)
+	return buildSubscriptionResponse(subscription), nil
+}
+
+// CancelSubscription 取消订阅，不再生成新账单，已生成的账单仍可支付
+func CancelSubscription(subscriptionNo string) error {
+	subscription, err := getSubscription(subscriptionNo)
+	if err != nil {
+		return err
+	}
+	cancelled, err := data.CancelSubscriptionById(subscription.ID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return constant.SubscriptionCancelledErr
+	}
+	subscription.Status = mdb.SubscriptionStatusCancelled
+	sendSubscriptionNotify(subscription, buildSubscriptionNotify(subscription, SubscriptionEventCancelled))
+	notify.SendToBot(fmt.Sprintf("【订阅取消通知】\n\n订阅编号：%s\n客户ID：%s\n原因：商户取消",
+		subscription.SubscriptionNo, subscription.CustomerId))
+	return nil
+}
+
+// GetSubscription 获取订阅详情
+func GetSubscription(subscriptionNo string) (*response.SubscriptionResponse, error) {
+	subscription, err := getSubscription(subscriptionNo)
+	if err != nil {
+		return nil, err
+	}
+	return buildSubscriptionResponse(subscription), nil
+}
+
+// ListSubscriptions 分页获取订阅，customerId 为空时返回所有订阅
+func ListSubscriptions(customerId string, pageNum int, pageSize int) ([]*response.SubscriptionResponse, page.Pagination, error) {
+	if pageNum <= 0 {
+		pageNum = page.DefaultPage
+	}
+	if pageSize <= 0 {
+		pageSize = page.DefaultPageSize
+	}
+	if pageSize > page.MaxPageSize {
+		pageSize = page.MaxPageSize
+	}
+	subscriptions, total, err := data.GetSubscriptions(customerId, pageNum, pageSize)
+	if err != nil {
+		return nil, page.Pagination{}, err
+	}
+	list := make([]*response.SubscriptionResponse, 0, len(subscriptions))
+	for i := range subscriptions {
+		list = append(list, buildSubscriptionResponse(&subscriptions[i]))
+	}
+	return list, page.GetPagination(pageNum, pageSize, total), nil
+}
+
+// RunSubscriptionBilling 处理到期的订阅：生成账单、检查账单支付结果并催缴
+func RunSubscriptionBilling() {
+	subscriptions, err := data.GetDueSubscriptions(subscriptionBatchSize)
+	if err != nil {
+		log.Sugar.Errorf("[订阅] 获取到期订阅失败: %v", err)
+		return
+	}
+	for i := range subscriptions {
+		processSubscription(&subscriptions[i])
+	}
+}
+
+// processSubscription 当前账期未生成账单时生成账单，已生成的按订单状态处理
+func processSubscription(subscription *mdb.Subscription) {
+	if subscription.CurrentTradeId == "" {
+		createSubscriptionInvoice(subscription)
+		return
+	}
+	order, err := data.GetOrderInfoByTradeId(subscription.CurrentTradeId)
+	if err != nil {
+		log.Sugar.Errorf("[订阅] 获取账单订单失败 %s: %v", subscription.CurrentTradeId, err)
+		return
+	}
+	switch {
+	case order.ID > 0 && order.Status == mdb.StatusWaitPay:
+		// 账单等待支付中，推迟下次处理时间，避免占用每轮的处理名额
+		if err = data.UpdateSubscriptionNextRunAt(subscription.ID, carbon.Now().AddMinutes(subscriptionInvoiceRecheckMinutes)); err != nil {
+			log.Sugar.Errorf("[订阅] 更新下次处理时间失败 %s: %v", subscription.SubscriptionNo, err)
+		}
+	case order.ID > 0 && order.Status == mdb.StatusPaySuccess:
+		// 支付成功时已处理，这里兜底处理未进入下一账期的订阅
+		handleSubscriptionInvoicePaid(order)
+	default:
+		handleSubscriptionInvoiceExpired(subscription)
+	}
+}
+
+// createSubscriptionInvoice 为当前账期生成账单订单并发送给客户
+// 商户订单号由订阅、期数和重试次数组成，多实例同时生成时由订单号唯一索引拦截
+func createSubscriptionInvoice(subscription *mdb.Subscription) {
+	req := &request.CreateTransactionRequest{
+		OrderId:        fmt.Sprintf("%s%d-%d-%d", subscriptionNoPrefix, subscription.ID, subscription.PaidPeriods+1, subscription.RetryCount),
+		Amount:         subscription.Amount,
+		RedirectUrl:    subscription.RedirectUrl,
+		ChainType:      subscription.ChainType,
+		Currency:       subscription.Currency,
+		SubscriptionId: subscription.ID,
+	}
+	if subscription.Currency == mdb.AssetUSDT {
+		req.Currency = ""
+		req.AmountCurrency = AmountCurrencyUSDT
+	}
+	resp, err := CreateTransaction(req)
+	if err == constant.OrderAlreadyExists {
+		// 订单已由其他实例生成，补记账单，避免生成后未记录导致订阅停滞
+		order, err := data.GetOrderInfoByOrderId(req.OrderId)
+		if err != nil || order.ID <= 0 {
+			log.Sugar.Errorf("[订阅] 获取已生成的账单订单失败 %s: %v", req.OrderId, err)
+			return
+		}
+		if _, err = data.SetSubscriptionInvoice(subscription.ID, order.TradeId, getSubscriptionInvoiceExpiresAt(order)); err != nil {
+			log.Sugar.Errorf("[订阅] 记录账单订单失败 %s: %v", subscription.SubscriptionNo, err)
+		}
+		return
+	}
+	if err != nil {
+		log.Sugar.Errorf("[订阅] 生成账单失败 %s: %v", subscription.SubscriptionNo, err)
+		notify.SendToBot(fmt.Sprintf("【订阅账单生成失败】\n\n订阅编号：%s\n客户ID：%s\n原因：%s\n\n%d 分钟后重试",
+			subscription.SubscriptionNo, subscription.CustomerId, err.Error(), subscriptionErrorRetryMinutes))
+		if err = data.UpdateSubscriptionNextRunAt(subscription.ID, carbon.Now().AddMinutes(subscriptionErrorRetryMinutes)); err != nil {
+			log.Sugar.Errorf("[订阅] 更新下次处理时间失败 %s: %v", subscription.SubscriptionNo, err)
+		}
+		return
+	}
+	recorded, err := data.SetSubscriptionInvoice(subscription.ID, resp.TradeId, carbon.CreateFromTimestamp(resp.ExpirationTime))
+	if err != nil || !recorded {
+		log.Sugar.Errorf("[订阅] 记录账单订单失败 %s: %v", subscription.SubscriptionNo, err)
+		return
+	}
+	subscription.CurrentTradeId = resp.TradeId
+	log.Sugar.Infof("[订阅] 订阅 %s 生成第 %d 期账单 %s", subscription.SubscriptionNo, subscription.PaidPeriods+1, resp.TradeId)
+	sendSubscriptionInvoice(subscription, resp)
+}
+
+// getSubscriptionInvoiceExpiresAt 按订单创建时间估算账单过期时间，未选择链的开放订单按开放订单过期时间计算
+func getSubscriptionInvoiceExpiresAt(order *mdb.Orders) carbon.Carbon {
+	expirationMinutes := config.GetOrderExpirationTime()
+	if order.ChainType == "" {
+		expirationMinutes = config.GetOpenOrderExpirationTime()
+	}
+	return order.CreatedAt.AddMinutes(expirationMinutes)
+}
+
+// sendSubscriptionInvoice 将账单支付链接发送给客户：有邮箱且配置了邮件 webhook 时调用 webhook，有 Telegram chat ID 时通过机器人发送
+func sendSubscriptionInvoice(subscription *mdb.Subscription, resp *response.CreateTransactionResponse) {
+	notifyResp := buildSubscriptionNotify(subscription, SubscriptionEventInvoice)
+	notifyResp.TradeId = resp.TradeId
+	notifyResp.PaymentUrl = resp.PaymentUrl
+	notifyResp.ChainType = resp.ChainType
+	if webhook := config.GetSubscriptionEmailWebhook(); webhook != "" && subscription.CustomerEmail != "" {
+		task := handle.SubscriptionNotifyTask{Url: webhook, Notify: notifyResp}
+		dao.EnqueueTaskNow(context.Background(), "default", handle.QueueSubscriptionNotify, task, 5)
+	}
+	if subscription.TelegramChatId != 0 {
+		msgTpl := `【账单通知】
+
+%s
+金额：%.2f %s
+账单日：%s
+
+请在 %s 前完成支付：
+%s`
+		msg := fmt.Sprintf(msgTpl,
+			html.EscapeString(subscription.Title),
+			subscription.Amount,
+			subscription.Currency,
+			subscription.DueAt.ToDateString(),
+			carbon.CreateFromTimestamp(resp.ExpirationTime).ToDateTimeString(),
+			resp.PaymentUrl)
+		notify.SendToChat(subscription.TelegramChatId, msg)
+	}
+}
+
+// handleSubscriptionInvoiceExpired 账单过期未支付，未超过最大催缴次数时标记逾期并在间隔后重新生成账单，否则取消订阅
+func handleSubscriptionInvoiceExpired(subscription *mdb.Subscription) {
+	tradeId := subscription.CurrentTradeId
+	retryCount := subscription.RetryCount + 1
+	if retryCount > config.GetSubscriptionMaxRetries() {
+		cancelled, err := data.UpdateSubscriptionByInvoice(subscription.ID, tradeId, map[string]interface{}{
+			"status":           mdb.SubscriptionStatusCancelled,
+			"retry_count":      retryCount,
+			"current_trade_id": "",
+			"cancelled_at":     carbon.Now().ToDateTimeString(),
+		})
+		if err != nil || !cancelled {
+			log.Sugar.Errorf("[订阅] 取消逾期订阅失败 %s: %v", subscription.SubscriptionNo, err)
+			return
+		}
+		subscription.Status = mdb.SubscriptionStatusCancelled
+		subscription.RetryCount = retryCount
+		log.Sugar.Infof("[订阅] 订阅 %s 连续 %d 期账单未支付，已自动取消", subscription.SubscriptionNo, retryCount)
+		notifyResp := buildSubscriptionNotify(subscription, SubscriptionEventCancelled)
+		notifyResp.TradeId = tradeId
+		sendSubscriptionNotify(subscription, notifyResp)
+		notify.SendToBot(fmt.Sprintf("【订阅取消通知】\n\n订阅编号：%s\n客户ID：%s\n原因：连续 %d 次账单未支付",
+			subscription.SubscriptionNo, subscription.CustomerId, retryCount))
+		return
+	}
+
+	nextRunAt := carbon.Now().AddHours(config.GetSubscriptionRetryInterval())
+	updated, err := data.UpdateSubscriptionByInvoice(subscription.ID, tradeId, map[string]interface{}{
+		"status":           mdb.SubscriptionStatusPastDue,
+		"retry_count":      retryCount,
+		"current_trade_id": "",
+		"next_run_at":      nextRunAt.ToDateTimeString(),
+	})
+	if err != nil || !updated {
+		log.Sugar.Errorf("[订阅] 标记订阅逾期失败 %s: %v", subscription.SubscriptionNo, err)
+		return
+	}
+	subscription.Status = mdb.SubscriptionStatusPastDue
+	subscription.RetryCount = retryCount
+	log.Sugar.Infof("[订阅] 订阅 %s 账单 %s 未支付，第 %d 次催缴将于 %s 生成",
+		subscription.SubscriptionNo, tradeId, retryCount, nextRunAt.ToDateTimeString())
+	notifyResp := buildSubscriptionNotify(subscription, SubscriptionEventPastDue)
+	notifyResp.TradeId = tradeId
+	sendSubscriptionNotify(subscription, notifyResp)
+}
+
+// handleSubscriptionInvoicePaid 账单支付成功，订阅进入下一账期
+// 订阅取消后仍支付了已生成的账单时只发送支付通知，不再恢复订阅
+func handleSubscriptionInvoicePaid(order *mdb.Orders) {
+	subscription, err := data.GetSubscriptionById(order.SubscriptionId)
+	if err != nil || subscription.ID <= 0 {
+		log.Sugar.Errorf("[订阅] 获取账单 %s 的订阅失败: %v", order.TradeId, err)
+		return
+	}
+	notifyResp := buildSubscriptionNotify(subscription, SubscriptionEventPaid)
+	notifyResp.TradeId = order.TradeId
+	notifyResp.ActualAmount = order.ActualAmount
+	notifyResp.ChainType = order.ChainType
+	notifyResp.BlockTransactionId = order.BlockTransactionId
+
+	nextDueAt := getSubscriptionDueAt(subscription, subscription.PaidPeriods+1)
+	advanced, err := data.IncrSubscriptionPaidPeriods(subscription.ID, order.TradeId, nextDueAt)
+	if err != nil {
+		log.Sugar.Errorf("[订阅] 更新订阅账期失败 %s: %v", subscription.SubscriptionNo, err)
+		return
+	}
+	if !advanced && subscription.Status != mdb.SubscriptionStatusCancelled {
+		// 账单已由其他实例或定时任务处理
+		return
+	}
+	if advanced {
+		notifyResp.Status = mdb.SubscriptionStatusActive
+		notifyResp.RetryCount = 0
+		notifyResp.PaidPeriods = subscription.PaidPeriods + 1
+	}
+	log.Sugar.Infof("[订阅] 订阅 %s 账单 %s 支付成功，下期账单日 %s",
+		subscription.SubscriptionNo, order.TradeId, nextDueAt.ToDateString())
+	sendSubscriptionNotify(subscription, notifyResp)
+}
+
+// getSubscriptionDueAt 按首期账单日推算第 period 期的账单日，首期为0
+func getSubscriptionDueAt(subscription *mdb.Subscription, period int) carbon.Carbon {
+	start := subscription.StartAt.Carbon
+	n := period * subscription.IntervalCount
+	switch subscription.IntervalUnit {
+	case mdb.SubscriptionIntervalDay:
+		return start.AddDays(n)
+	case mdb.SubscriptionIntervalWeek:
+		return start.AddWeeks(n)
+	case mdb.SubscriptionIntervalYear:
+		return start.AddYearsNoOverflow(n)
+	default:
+		return start.AddMonthsNoOverflow(n)
+	}
+}
+
+// sendSubscriptionNotify 订阅设置了回调地址时发送订阅事件回调
+func sendSubscriptionNotify(subscription *mdb.Subscription, notifyResp response.SubscriptionNotifyResponse) {
+	if subscription.NotifyUrl == "" {
+		return
+	}
+	task := handle.SubscriptionNotifyTask{Url: subscription.NotifyUrl, Notify: notifyResp}
+	dao.EnqueueTaskNow(context.Background(), "default", handle.QueueSubscriptionNotify, task, 5)
+}
+
+func getSubscription(subscriptionNo string) (*mdb.Subscription, error) {
+	subscription, err := data.GetSubscriptionByNo(subscriptionNo)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.ID <= 0 {
+		return nil, constant.SubscriptionNotExists
+	}
+	return subscription, nil
+}
+
+func buildSubscriptionNotify(subscription *mdb.Subscription, event string) response.SubscriptionNotifyResponse {
+	return response.SubscriptionNotifyResponse{
+		Event:          event,
+		SubscriptionNo: subscription.SubscriptionNo,
+		CustomerId:     subscription.CustomerId,
+		CustomerEmail:  subscription.CustomerEmail,
+		Title:          subscription.Title,
+		Amount:         subscription.Amount,
+		Currency:       subscription.Currency,
+		Status:         subscription.Status,
+		DueAt:          subscription.DueAt.Timestamp(),
+		RetryCount:     subscription.RetryCount,
+		PaidPeriods:    subscription.PaidPeriods,
+		ChainType:      subscription.ChainType,
+	}
+}
+
+func buildSubscriptionResponse(subscription *mdb.Subscription) *response.SubscriptionResponse {
+	return &response.SubscriptionResponse{
+		SubscriptionNo: subscription.SubscriptionNo,
+		CustomerId:     subscription.CustomerId,
+		CustomerEmail:  subscription.CustomerEmail,
+		TelegramChatId: subscription.TelegramChatId,
+		Title:          subscription.Title,
+		Amount:         subscription.Amount,
+		Currency:       subscription.Currency,
+		ChainType:      subscription.ChainType,
+		IntervalUnit:   subscription.IntervalUnit,
+		IntervalCount:  subscription.IntervalCount,
+		DueAt:          subscription.DueAt.Timestamp(),
+		NextRunAt:      subscription.NextRunAt.Timestamp(),
+		CurrentTradeId: subscription.CurrentTradeId,
+		RetryCount:     subscription.RetryCount,
+		PaidPeriods:    subscription.PaidPeriods,
+		Status:         subscription.Status,
+		NotifyUrl:      subscription.NotifyUrl,
+		RedirectUrl:    subscription.RedirectUrl,
+		CreatedAt:      subscription.CreatedAt.Timestamp(),
+	}
+}
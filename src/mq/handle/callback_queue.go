@@ -35,9 +35,12 @@ func OrderCallbackHandle(ctx context.Context, payload []byte) error {
 		TradeId:            order.TradeId,
 		OrderId:            order.OrderId,
 		Amount:             order.Amount,
+		Currency:           order.Currency,
+		Rate:               order.Rate,
 		ActualAmount:       order.ActualAmount,
 		Token:              order.Token,
 		ChainType:          order.ChainType,
+		Asset:              order.Asset,
 		BlockTransactionId: order.BlockTransactionId,
 		Status:             mdb.StatusPaySuccess,
 	}
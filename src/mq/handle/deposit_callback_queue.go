@@ -0,0 +1,63 @@
+package handle
+
+import (
+	"context"
+	"errors"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/model/data"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/assimon/luuu/model/response"
+	"github.com/assimon/luuu/util/http_client"
+	"github.com/assimon/luuu/util/json"
+	"github.com/assimon/luuu/util/log"
+	"github.com/assimon/luuu/util/sign"
+)
+
+const QueueDepositCallback = "deposit:callback"
+
+// DepositCallbackHandle 客户充值地址每笔到账后的异步回调
+func DepositCallbackHandle(ctx context.Context, payload []byte) error {
+	var deposit mdb.CustomerDeposit
+	err := json.Cjson.Unmarshal(payload, &deposit)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			log.Sugar.Error(err)
+		}
+	}()
+	defer func() {
+		data.SaveCallBackDepositResp(&deposit)
+	}()
+	client := http_client.GetHttpClient()
+	depositResp := response.DepositNotifyResponse{
+		Event:              "deposit",
+		DepositId:          deposit.ID,
+		CustomerId:         deposit.CustomerId,
+		ChainType:          deposit.ChainType,
+		Address:            deposit.Token,
+		FromAddress:        deposit.FromAddress,
+		Asset:              deposit.Asset,
+		Amount:             deposit.Amount,
+		BlockTransactionId: deposit.BlockTransactionId,
+		BlockTimestamp:     deposit.BlockTimestamp,
+	}
+	signature, err := sign.Get(depositResp, config.GetApiAuthToken())
+	if err != nil {
+		return err
+	}
+	depositResp.Signature = signature
+	resp, err := client.R().SetHeader("powered-by", "Epusdt(https://github.com/assimon/epusdt)").SetBody(depositResp).Post(deposit.NotifyUrl)
+	if err != nil {
+		return err
+	}
+	body := string(resp.Body())
+	if body != "ok" && body != "success" {
+		deposit.CallBackConfirm = mdb.CallBackConfirmNo
+		return errors.New("回调响应不正确")
+	}
+	deposit.CallBackConfirm = mdb.CallBackConfirmOk
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/event"
 	"github.com/assimon/luuu/model/dao"
 	"github.com/assimon/luuu/model/data"
 	"github.com/assimon/luuu/model/mdb"
@@ -13,6 +14,7 @@ import (
 	"github.com/assimon/luuu/util/json"
 	"github.com/assimon/luuu/util/log"
 	"github.com/assimon/luuu/util/sign"
+	"github.com/golang-module/carbon/v2"
 )
 
 const (
@@ -30,12 +32,21 @@ func OrderExpirationHandle(ctx context.Context, payload []byte) error {
 	if orderInfo.ID <= 0 || orderInfo.Status != mdb.StatusWaitPay {
 		return nil
 	}
+	// 开放订单选择链后重新计算过期时间，由选择时投递的任务处理
+	if carbon.Now().Lt(data.GetOrderExpirationTime(orderInfo)) {
+		return nil
+	}
 	err = data.UpdateOrderIsExpirationById(orderInfo.ID)
 	if err != nil {
 		return err
 	}
-	// 使用链类型解锁交易，确保不同链类型的订单能正确解锁
-	err = data.UnLockTransactionWithChainType(orderInfo.Token, orderInfo.ActualAmount, orderInfo.ChainType)
+	event.PublishStage(orderInfo.TradeId, mdb.StatusExpired, event.StageExpired)
+	// 订单已过期，派生地址不再需要监听
+	if err = data.DisableDerivedWalletAddressByTradeId(orderInfo.TradeId); err != nil {
+		log.Sugar.Warnf("[%s] 禁用订单派生地址失败: %v", orderInfo.TradeId, err)
+	}
+	// 释放订单占用的支付金额
+	err = data.UnLockTransactionByTradeId(orderInfo.TradeId)
 	if err != nil {
 		return err
 	}
@@ -76,9 +87,12 @@ func OrderExpirationCallbackHandle(ctx context.Context, payload []byte) error {
 		TradeId:            order.TradeId,
 		OrderId:            order.OrderId,
 		Amount:             order.Amount,
+		Currency:           order.Currency,
+		Rate:               order.Rate,
 		ActualAmount:       order.ActualAmount,
 		Token:              order.Token,
 		ChainType:          order.ChainType,
+		Asset:              order.Asset,
 		BlockTransactionId: order.BlockTransactionId,
 		Status:             mdb.StatusExpired, // 订单过期状态
 	}
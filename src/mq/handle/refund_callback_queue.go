@@ -0,0 +1,67 @@
+package handle
+
+import (
+	"context"
+	"errors"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/model/data"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/assimon/luuu/model/response"
+	"github.com/assimon/luuu/util/http_client"
+	"github.com/assimon/luuu/util/json"
+	"github.com/assimon/luuu/util/log"
+	"github.com/assimon/luuu/util/sign"
+)
+
+const QueueRefundCallback = "refund:callback"
+
+// RefundCallbackHandle 退款交易核验通过后的异步回调
+func RefundCallbackHandle(ctx context.Context, payload []byte) error {
+	var refund mdb.Refund
+	err := json.Cjson.Unmarshal(payload, &refund)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			log.Sugar.Error(err)
+		}
+	}()
+	defer func() {
+		data.SaveCallBackRefundResp(&refund)
+	}()
+	client := http_client.GetHttpClient()
+	refundResp := response.RefundNotifyResponse{
+		Event:       "refund.completed",
+		RefundNo:    refund.RefundNo,
+		TradeId:     refund.TradeId,
+		OrderId:     refund.OrderId,
+		ChainType:   refund.ChainType,
+		Asset:       refund.Asset,
+		Amount:      refund.Amount,
+		ToAddress:   refund.ToAddress,
+		FromAddress: refund.FromAddress,
+		TxHash:      refund.TxHash,
+		Reason:      refund.Reason,
+	}
+	if refund.CompletedAt != nil {
+		refundResp.CompletedAt = refund.CompletedAt.Timestamp()
+	}
+	signature, err := sign.Get(refundResp, config.GetApiAuthToken())
+	if err != nil {
+		return err
+	}
+	refundResp.Signature = signature
+	resp, err := client.R().SetHeader("powered-by", "Epusdt(https://github.com/assimon/epusdt)").SetBody(refundResp).Post(refund.NotifyUrl)
+	if err != nil {
+		return err
+	}
+	body := string(resp.Body())
+	if body != "ok" && body != "success" {
+		refund.CallBackConfirm = mdb.CallBackConfirmNo
+		return errors.New("回调响应不正确")
+	}
+	refund.CallBackConfirm = mdb.CallBackConfirmOk
+	return nil
+}
@@ -0,0 +1,52 @@
+package handle
+
+import (
+	"context"
+	"errors"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/model/response"
+	"github.com/assimon/luuu/util/http_client"
+	"github.com/assimon/luuu/util/json"
+	"github.com/assimon/luuu/util/log"
+	"github.com/assimon/luuu/util/sign"
+)
+
+const QueueSubscriptionNotify = "subscription:notify"
+
+// SubscriptionNotifyTask 订阅事件通知任务，回调商户或调用账单邮件 webhook
+type SubscriptionNotifyTask struct {
+	Url    string                              `json:"url"`
+	Notify response.SubscriptionNotifyResponse `json:"notify"`
+}
+
+// SubscriptionNotifyHandle 发送签名后的订阅事件通知
+func SubscriptionNotifyHandle(ctx context.Context, payload []byte) error {
+	var task SubscriptionNotifyTask
+	err := json.Cjson.Unmarshal(payload, &task)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			log.Sugar.Error(err)
+		}
+	}()
+	client := http_client.GetHttpClient()
+	notifyResp := task.Notify
+	signature, err := sign.Get(notifyResp, config.GetApiAuthToken())
+	if err != nil {
+		return err
+	}
+	notifyResp.Signature = signature
+	resp, err := client.R().SetHeader("powered-by", "Epusdt(https://github.com/assimon/epusdt)").SetBody(notifyResp).Post(task.Url)
+	if err != nil {
+		return err
+	}
+	body := string(resp.Body())
+	if body != "ok" && body != "success" {
+		log.Sugar.Warnf("[订阅] %s 通知响应不正确: 订阅=%s, 响应=%s", notifyResp.Event, notifyResp.SubscriptionNo, body)
+		return errors.New("回调响应不正确")
+	}
+	return nil
+}
@@ -16,6 +16,9 @@ func Start() {
 	dao.RegisterTaskHandler(handle.QueueOrderExpiration, handle.OrderExpirationHandle)
 	dao.RegisterTaskHandler(handle.QueueOrderExpirationCallback, handle.OrderExpirationCallbackHandle)
 	dao.RegisterTaskHandler(handle.QueueOrderCallback, handle.OrderCallbackHandle)
+	dao.RegisterTaskHandler(handle.QueueDepositCallback, handle.DepositCallbackHandle)
+	dao.RegisterTaskHandler(handle.QueueSubscriptionNotify, handle.SubscriptionNotifyHandle)
+	dao.RegisterTaskHandler(handle.QueueRefundCallback, handle.RefundCallbackHandle)
 
 	// 启动队列处理器
 	queueCtx, queueCancel = context.WithCancel(context.Background())
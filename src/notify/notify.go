@@ -32,3 +32,18 @@ func SendToBot(msg string) {
 	}()
 }
 
+// SendToChat 主动发送消息到指定会话，用于向客户发送订阅账单，对方需先与机器人开始过对话
+func SendToChat(chatId int64, msg string) {
+	if bot == nil {
+		return
+	}
+
+	go func() {
+		_, err := bot.Send(&tb.Chat{ID: chatId}, msg, &tb.SendOptions{
+			ParseMode: tb.ModeHTML,
+		})
+		if err != nil {
+			log.Sugar.Errorf("发送消息到会话 %d 失败: %v", chatId, err)
+		}
+	}()
+}
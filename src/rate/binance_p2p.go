@@ -0,0 +1,68 @@
+package rate
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/assimon/luuu/util/http_client"
+	"github.com/assimon/luuu/util/json"
+)
+
+const (
+	BinanceP2PApiUri = "https://p2p.binance.com/bapi/c2c/v2/friendly/c2c/adv/search"
+	P2PSampleSize    = 10 // 取前10个广告价格的中位数
+)
+
+// BinanceP2PProvider 币安 P2P 数据源，取购买 USDT 广告价格的中位数
+type BinanceP2PProvider struct{}
+
+type BinanceP2PResp struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Success bool   `json:"success"`
+	Data    []struct {
+		Adv struct {
+			Price string `json:"price"`
+		} `json:"adv"`
+	} `json:"data"`
+}
+
+func (p *BinanceP2PProvider) GetName() string {
+	return "binance_p2p"
+}
+
+func (p *BinanceP2PProvider) GetUsdtRate(currency string) (float64, error) {
+	resp, err := http_client.GetHttpClient().R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]interface{}{
+			"asset":     "USDT",
+			"fiat":      currency,
+			"tradeType": "BUY",
+			"page":      1,
+			"rows":      P2PSampleSize,
+			"payTypes":  []string{},
+		}).
+		Post(BinanceP2PApiUri)
+	if err != nil {
+		return 0, err
+	}
+	var p2pResp BinanceP2PResp
+	err = json.Cjson.Unmarshal(resp.Body(), &p2pResp)
+	if err != nil {
+		return 0, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if !p2pResp.Success {
+		return 0, fmt.Errorf("响应错误: %s %s", p2pResp.Code, p2pResp.Message)
+	}
+	prices := make([]float64, 0, len(p2pResp.Data))
+	for _, item := range p2pResp.Data {
+		price, err := strconv.ParseFloat(item.Adv.Price, 64)
+		if err == nil && price > 0 {
+			prices = append(prices, price)
+		}
+	}
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("没有%s广告数据", currency)
+	}
+	return Median(prices), nil
+}
@@ -0,0 +1,46 @@
+package rate
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/assimon/luuu/util/http_client"
+	"github.com/assimon/luuu/util/json"
+)
+
+const CoinGeckoApiUri = "https://api.coingecko.com/api/v3/simple/price"
+
+// CoinGeckoProvider CoinGecko 数据源
+type CoinGeckoProvider struct{}
+
+func (p *CoinGeckoProvider) GetName() string {
+	return "coingecko"
+}
+
+func (p *CoinGeckoProvider) GetUsdtRate(currency string) (float64, error) {
+	vsCurrency := strings.ToLower(currency)
+	resp, err := http_client.GetHttpClient().R().
+		SetQueryParams(map[string]string{
+			"ids":           "tether",
+			"vs_currencies": vsCurrency,
+		}).
+		SetHeader("Accept", "application/json").
+		Get(CoinGeckoApiUri)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return 0, fmt.Errorf("返回状态码: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
+	}
+	var geckoResp map[string]map[string]float64
+	err = json.Cjson.Unmarshal(resp.Body(), &geckoResp)
+	if err != nil {
+		return 0, fmt.Errorf("解析响应失败: %w", err)
+	}
+	rate, ok := geckoResp["tether"][vsCurrency]
+	if !ok {
+		return 0, fmt.Errorf("不支持的法币: %s", currency)
+	}
+	return rate, nil
+}
@@ -0,0 +1,71 @@
+package rate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/assimon/luuu/util/http_client"
+	"github.com/assimon/luuu/util/json"
+)
+
+const CoinMarketCapApiUri = "https://api.coinmarketcap.com/data-api/v3/cryptocurrency/detail/chart"
+
+// coinMarketCapConvertIds CoinMarketCap 法币 convertId
+var coinMarketCapConvertIds = map[string]int{
+	"USD": 2781,
+	"CNY": 2787,
+	"EUR": 2790,
+	"GBP": 2791,
+	"HKD": 2792,
+	"JPY": 2797,
+}
+
+// CoinMarketCapProvider CoinMarketCap 数据源
+type CoinMarketCapProvider struct{}
+
+type CoinMarketCapResp struct {
+	Data struct {
+		Points map[string]struct {
+			V []float64 `json:"v"`
+			C []float64 `json:"c"`
+		} `json:"points"`
+	} `json:"data"`
+	Status struct {
+		Timestamp    time.Time `json:"timestamp"`
+		ErrorCode    string    `json:"error_code"`
+		ErrorMessage string    `json:"error_message"`
+	} `json:"status"`
+}
+
+func (p *CoinMarketCapProvider) GetName() string {
+	return "coinmarketcap"
+}
+
+func (p *CoinMarketCapProvider) GetUsdtRate(currency string) (float64, error) {
+	convertId, ok := coinMarketCapConvertIds[currency]
+	if !ok {
+		return 0, fmt.Errorf("不支持的法币: %s", currency)
+	}
+	resp, err := http_client.GetHttpClient().R().
+		SetQueryString(fmt.Sprintf("id=825&range=1H&convertId=%d", convertId)).
+		SetHeader("Accept", "application/json").
+		Get(CoinMarketCapApiUri)
+	if err != nil {
+		return 0, err
+	}
+	var cmcResp CoinMarketCapResp
+	err = json.Cjson.Unmarshal(resp.Body(), &cmcResp)
+	if err != nil {
+		return 0, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if cmcResp.Status.ErrorCode != "0" {
+		return 0, fmt.Errorf("响应错误: %s", cmcResp.Status.ErrorMessage)
+	}
+	for _, points := range cmcResp.Data.Points {
+		if len(points.C) > 0 && points.C[0] > 0 {
+			return points.C[0], nil
+		}
+	}
+	return 0, errors.New("响应中没有汇率数据")
+}
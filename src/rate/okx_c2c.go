@@ -0,0 +1,67 @@
+package rate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/assimon/luuu/util/http_client"
+	"github.com/assimon/luuu/util/json"
+)
+
+const OkxC2CApiUri = "https://www.okx.com/v3/c2c/tradingOrders/books"
+
+// OkxC2CProvider 欧易 C2C 数据源，取出售 USDT 挂单价格的中位数
+type OkxC2CProvider struct{}
+
+type OkxC2CResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Sell []struct {
+			Price string `json:"price"`
+		} `json:"sell"`
+	} `json:"data"`
+}
+
+func (p *OkxC2CProvider) GetName() string {
+	return "okx_c2c"
+}
+
+func (p *OkxC2CProvider) GetUsdtRate(currency string) (float64, error) {
+	resp, err := http_client.GetHttpClient().R().
+		SetQueryParams(map[string]string{
+			"quoteCurrency": strings.ToLower(currency),
+			"baseCurrency":  "usdt",
+			"side":          "sell",
+			"paymentMethod": "all",
+			"userType":      "all",
+		}).
+		SetHeader("Accept", "application/json").
+		Get(OkxC2CApiUri)
+	if err != nil {
+		return 0, err
+	}
+	var c2cResp OkxC2CResp
+	err = json.Cjson.Unmarshal(resp.Body(), &c2cResp)
+	if err != nil {
+		return 0, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if c2cResp.Code != 0 {
+		return 0, fmt.Errorf("响应错误: %d %s", c2cResp.Code, c2cResp.Msg)
+	}
+	prices := make([]float64, 0, P2PSampleSize)
+	for _, order := range c2cResp.Data.Sell {
+		if len(prices) >= P2PSampleSize {
+			break
+		}
+		price, err := strconv.ParseFloat(order.Price, 64)
+		if err == nil && price > 0 {
+			prices = append(prices, price)
+		}
+	}
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("没有%s挂单数据", currency)
+	}
+	return Median(prices), nil
+}
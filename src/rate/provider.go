@@ -0,0 +1,112 @@
+package rate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/util/log"
+)
+
+// RateProvider 汇率数据源接口
+type RateProvider interface {
+	// GetName 获取数据源名称，与 rate_providers 配置对应
+	GetName() string
+
+	// GetUsdtRate 获取 1 USDT 折合的法币数量
+	GetUsdtRate(currency string) (float64, error)
+}
+
+var providers = make(map[string]RateProvider)
+
+// RegisterProvider 注册汇率数据源
+func RegisterProvider(provider RateProvider) {
+	providers[provider.GetName()] = provider
+}
+
+// GetProvider 获取汇率数据源
+func GetProvider(name string) RateProvider {
+	return providers[strings.ToLower(name)]
+}
+
+func init() {
+	RegisterProvider(&CoinMarketCapProvider{})
+	RegisterProvider(&CoinGeckoProvider{})
+	RegisterProvider(&BinanceP2PProvider{})
+	RegisterProvider(&OkxC2CProvider{})
+	RegisterProvider(&StaticProvider{})
+}
+
+// Fetch 按配置的数据源和聚合方式获取 1 USDT 折合的法币数量
+// fallback 模式依次请求数据源，返回第一个成功的汇率
+// median 模式请求所有数据源，剔除偏离中位数超过 rate_max_deviation 的汇率后取中位数
+// 同时返回汇率来源，供记录汇率历史
+func Fetch(currency string) (float64, string, error) {
+	median := config.GetRateAggregation() == "median"
+	rates := make([]float64, 0)
+	names := make([]string, 0)
+	for _, name := range config.GetRateProviders() {
+		provider := GetProvider(name)
+		if provider == nil {
+			log.Sugar.Warnf("[汇率] 未知的数据源: %s", name)
+			continue
+		}
+		rate, err := provider.GetUsdtRate(currency)
+		if err != nil {
+			log.Sugar.Warnf("[汇率] %s 获取USDT/%s汇率失败: %v", name, currency, err)
+			continue
+		}
+		if rate <= 0 {
+			log.Sugar.Warnf("[汇率] %s 返回的USDT/%s汇率无效: %v", name, currency, rate)
+			continue
+		}
+		if !median {
+			return rate, provider.GetName(), nil
+		}
+		rates = append(rates, rate)
+		names = append(names, provider.GetName())
+	}
+	if len(rates) == 0 {
+		return 0, "", errors.New("所有数据源均获取失败")
+	}
+
+	// 剔除异常数据源后重新取中位数
+	mid := Median(rates)
+	filtered := make([]float64, 0, len(rates))
+	sources := make([]string, 0, len(rates))
+	for i, rate := range rates {
+		if Deviation(rate, mid) <= config.GetRateMaxDeviation() {
+			filtered = append(filtered, rate)
+			sources = append(sources, names[i])
+		}
+	}
+	if len(filtered) == 0 {
+		return 0, "", fmt.Errorf("数据源汇率偏差过大: %v", rates)
+	}
+	return Median(filtered), "median:" + strings.Join(sources, ","), nil
+}
+
+// Median 计算中位数
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// Deviation 计算 value 相对 base 的偏差百分比
+func Deviation(value float64, base float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return math.Abs(value-base) / base * 100
+}
@@ -0,0 +1,22 @@
+package rate
+
+import (
+	"fmt"
+
+	"github.com/assimon/luuu/config"
+)
+
+// StaticProvider 固定汇率数据源，读取 static_rates 配置，通常作为最后的回退
+type StaticProvider struct{}
+
+func (p *StaticProvider) GetName() string {
+	return "static"
+}
+
+func (p *StaticProvider) GetUsdtRate(currency string) (float64, error) {
+	rate, ok := config.GetStaticRates()[currency]
+	if !ok {
+		return 0, fmt.Errorf("未配置%s固定汇率", currency)
+	}
+	return rate, nil
+}
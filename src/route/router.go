@@ -14,15 +14,52 @@ func RegisterRoute(e *echo.Echo) {
 		return c.String(http.StatusOK, "hello epusdt, https://github.com/assimon/epusdt")
 	})
 	// 支付相关
-	payRoute := e.Group("/pay")
+	payRoute := e.Group("/pay", middleware.PayCors())
 	// 收银台
 	payRoute.GET("/checkout-counter/:trade_id", comm.Ctrl.CheckoutCounter)
 	// 状态检测
 	payRoute.GET("/check-status/:trade_id", comm.Ctrl.CheckStatus)
+	// 状态实时推送（SSE）
+	payRoute.GET("/status-stream/:trade_id", comm.Ctrl.StatusStream)
+	// 支付二维码图片（:trade_id.png 或 :trade_id.svg）
+	payRoute.GET("/qrcode/:trade_id", comm.Ctrl.QrCode)
+	// 开放订单选择支付网络
+	payRoute.POST("/select-chain/:trade_id", comm.Ctrl.SelectChain)
+	// 收银台 JSON 接口，供自建前端使用
+	payRoute.GET("/api/checkout/:trade_id", comm.Ctrl.CheckoutApi)
+	// 支付链接页面及付款人下单
+	payRoute.GET("/link/:slug", comm.Ctrl.PaymentLinkPage)
+	payRoute.POST("/link/:slug", comm.Ctrl.PayPaymentLink)
 
 	apiV1Route := e.Group("/api/v1")
 	// 订单相关
 	orderRoute := apiV1Route.Group("/order", middleware.CheckApiSign())
 	// 创建订单
 	orderRoute.POST("/create-transaction", comm.Ctrl.CreateTransaction)
+	// 支付链接管理
+	paymentLinkRoute := apiV1Route.Group("/payment-link", middleware.CheckApiSign())
+	paymentLinkRoute.POST("/create", comm.Ctrl.CreatePaymentLink)
+	paymentLinkRoute.POST("/update", comm.Ctrl.UpdatePaymentLink)
+	paymentLinkRoute.POST("/delete", comm.Ctrl.DeletePaymentLink)
+	paymentLinkRoute.POST("/detail", comm.Ctrl.PaymentLinkDetail)
+	paymentLinkRoute.POST("/list", comm.Ctrl.PaymentLinkList)
+
+	customerAddressRoute := apiV1Route.Group("/customer-address", middleware.CheckApiSign())
+	customerAddressRoute.POST("/assign", comm.Ctrl.AssignCustomerAddress)
+	customerAddressRoute.POST("/detail", comm.Ctrl.CustomerAddressDetail)
+	customerAddressRoute.POST("/import", comm.Ctrl.ImportCustomerAddress)
+	customerAddressRoute.POST("/deposits", comm.Ctrl.CustomerDepositList)
+
+	subscriptionRoute := apiV1Route.Group("/subscription", middleware.CheckApiSign())
+	subscriptionRoute.POST("/create", comm.Ctrl.CreateSubscription)
+	subscriptionRoute.POST("/cancel", comm.Ctrl.CancelSubscription)
+	subscriptionRoute.POST("/detail", comm.Ctrl.SubscriptionDetail)
+	subscriptionRoute.POST("/list", comm.Ctrl.SubscriptionList)
+
+	refundRoute := apiV1Route.Group("/refund", middleware.CheckApiSign())
+	refundRoute.POST("/create", comm.Ctrl.CreateRefund)
+	refundRoute.POST("/submit-tx", comm.Ctrl.SubmitRefundTx)
+	refundRoute.POST("/cancel", comm.Ctrl.CancelRefund)
+	refundRoute.POST("/detail", comm.Ctrl.RefundDetail)
+	refundRoute.POST("/list", comm.Ctrl.RefundList)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/assimon/luuu/model/dao"
+	"github.com/assimon/luuu/model/data"
 	"github.com/assimon/luuu/util/log"
 )
 
@@ -19,6 +20,13 @@ func (j CleanCacheJob) Run() {
 	} else if count > 0 {
 		log.Sugar.Infof("[缓存清理] 清理了 %d 条过期缓存记录", count)
 	}
+
+	lockCount, err := data.CleanExpiredAmountLocks()
+	if err != nil {
+		log.Sugar.Errorf("[缓存清理] 清理过期金额锁失败: %v", err)
+	} else if lockCount > 0 {
+		log.Sugar.Infof("[缓存清理] 清理了 %d 条过期金额锁", lockCount)
+	}
 }
 
 // CleanQueueJob 清理已完成的队列任务（低频）
@@ -36,6 +44,22 @@ func (j CleanQueueJob) Run() {
 	}
 }
 
+// RateHistoryRetentionDays 汇率历史保留天数
+const RateHistoryRetentionDays = 90
+
+// CleanRateHistoryJob 清理过期的汇率历史（低频）
+type CleanRateHistoryJob struct{}
+
+// Run 执行汇率历史清理
+func (j CleanRateHistoryJob) Run() {
+	count, err := data.CleanRateHistory(RateHistoryRetentionDays)
+	if err != nil {
+		log.Sugar.Errorf("[汇率历史清理] 清理汇率历史失败: %v", err)
+	} else if count > 0 {
+		log.Sugar.Infof("[汇率历史清理] 清理了 %d 条汇率历史记录", count)
+	}
+}
+
 // CleanJob 综合清理任务
 type CleanJob struct{}
 
@@ -51,6 +75,14 @@ func (j CleanJob) Run() {
 		log.Sugar.Infof("[数据清理] 清理了 %d 条过期缓存记录", cacheCount)
 	}
 
+	// 清理过期金额锁
+	lockCount, err := data.CleanExpiredAmountLocks()
+	if err != nil {
+		log.Sugar.Errorf("[数据清理] 清理过期金额锁失败: %v", err)
+	} else {
+		log.Sugar.Infof("[数据清理] 清理了 %d 条过期金额锁", lockCount)
+	}
+
 	// 清理已完成的队列任务（保留最近7天的记录）
 	queueCount, err := dao.CleanCompletedJobs(ctx, 7)
 	if err != nil {
@@ -58,4 +90,12 @@ func (j CleanJob) Run() {
 	} else {
 		log.Sugar.Infof("[数据清理] 清理了 %d 条队列任务记录", queueCount)
 	}
+
+	// 清理过期的汇率历史
+	rateCount, err := data.CleanRateHistory(RateHistoryRetentionDays)
+	if err != nil {
+		log.Sugar.Errorf("[数据清理] 清理汇率历史失败: %v", err)
+	} else {
+		log.Sugar.Infof("[数据清理] 清理了 %d 条汇率历史记录", rateCount)
+	}
 }
@@ -19,10 +19,16 @@ func Start() {
 	listenInterval := config.GetBlockchainListenInterval()
 	cronExpr := fmt.Sprintf("@every %ds", listenInterval)
 
-	// 汇率监听
+	// 汇率监听，启动时立即获取一次，避免使用默认汇率
 	c.AddJob("@every 60s", UsdtRateJob{})
+	go UsdtRateJob{}.Run()
 	log.Sugar.Info("USDT汇率监控已启动，每60秒执行")
 
+	// 原生币价格监听，价格不持久化，启动时立即获取一次，避免原生币订单因无价格下单失败
+	c.AddJob("@every 60s", NativeRateJob{})
+	go NativeRateJob{}.Run()
+	log.Sugar.Info("原生币价格监控已启动，每60秒执行")
+
 	// TRC20，波场钱包监听
 	c.AddJob(cronExpr, NewListenBlockchainJob(mdb.ChainTypeTRC20))
 	log.Sugar.Infof("TRC20监控已启动，每%d秒执行", listenInterval)
@@ -31,27 +37,51 @@ func Start() {
 	// ERC20，以太坊钱包监听
 	c.AddJob(cronExpr, NewListenBlockchainJob(mdb.ChainTypeERC20))
 	log.Sugar.Infof("ERC20监控已启动，每%d秒执行", listenInterval)
+	if IsEvmBlockListenEnabled(mdb.ChainTypeERC20) {
+		c.AddJob(cronExpr, NewListenEvmBlockJob(mdb.ChainTypeERC20))
+		log.Sugar.Infof("ERC20区块扫描已启动，每%d秒执行", listenInterval)
+	}
 	time.Sleep(1 * time.Second)
 
 	// BEP20，币安智能链钱包监听
 	c.AddJob(cronExpr, NewListenBlockchainJob(mdb.ChainTypeBEP20))
 	log.Sugar.Infof("BEP20监控已启动，每%d秒执行", listenInterval)
+	if IsEvmBlockListenEnabled(mdb.ChainTypeBEP20) {
+		c.AddJob(cronExpr, NewListenEvmBlockJob(mdb.ChainTypeBEP20))
+		log.Sugar.Infof("BEP20区块扫描已启动，每%d秒执行", listenInterval)
+	}
 	time.Sleep(1 * time.Second)
 
 	// Polygon钱包监听
 	c.AddJob(cronExpr, NewListenBlockchainJob(mdb.ChainTypePOLYGON))
 	log.Sugar.Infof("Polygon监控已启动，每%d秒执行", listenInterval)
+	if IsEvmBlockListenEnabled(mdb.ChainTypePOLYGON) {
+		c.AddJob(cronExpr, NewListenEvmBlockJob(mdb.ChainTypePOLYGON))
+		log.Sugar.Infof("Polygon区块扫描已启动，每%d秒执行", listenInterval)
+	}
 	time.Sleep(1 * time.Second)
 
 	// ARB钱包监听
 	c.AddJob(cronExpr, NewListenBlockchainJob(mdb.ChainTypeARB))
 	log.Sugar.Infof("ARB监控已启动，每%d秒执行", listenInterval)
+	if IsEvmBlockListenEnabled(mdb.ChainTypeARB) {
+		c.AddJob(cronExpr, NewListenEvmBlockJob(mdb.ChainTypeARB))
+		log.Sugar.Infof("ARB区块扫描已启动，每%d秒执行", listenInterval)
+	}
 	time.Sleep(1 * time.Second)
 
 	// Solana钱包监听
 	c.AddJob(cronExpr, NewListenBlockchainJob(mdb.ChainTypeSOLANA))
 	log.Sugar.Infof("Solana监控已启动，每%d秒执行", listenInterval)
 
+	// 订阅账单生成及逾期催缴
+	c.AddJob("@every 60s", &SubscriptionJob{})
+	log.Sugar.Info("订阅扣款任务已启动，每60秒执行")
+
+	// 退款交易链上核验
+	c.AddJob("@every 30s", &RefundVerifyJob{})
+	log.Sugar.Info("退款核验任务已启动，每30秒执行")
+
 	// 定时清理过期缓存（每5分钟执行一次）
 	c.AddJob("@every 5m", CleanCacheJob{})
 	log.Sugar.Info("缓存清理任务已启动，每5分钟执行")
@@ -60,6 +90,10 @@ func Start() {
 	c.AddJob("@every 6h", CleanQueueJob{})
 	log.Sugar.Info("队列清理任务已启动，每6小时执行")
 
+	// 定时清理汇率历史（每天执行一次，保留90天数据）
+	c.AddJob("@every 24h", CleanRateHistoryJob{})
+	log.Sugar.Info("汇率历史清理任务已启动，每天执行")
+
 	// 启动时立即执行一次全面清理
 	go func() {
 		log.Sugar.Info("执行启动时数据清理...")
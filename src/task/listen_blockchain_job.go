@@ -4,6 +4,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/assimon/luuu/blockchain"
 	"github.com/assimon/luuu/model/data"
 	"github.com/assimon/luuu/model/service"
 	"github.com/assimon/luuu/util/log"
@@ -28,16 +29,47 @@ func (r *ListenBlockchainJob) Run() {
 		return
 	}
 
-	if len(walletAddressList) <= 0 {
+	// 原生币订单使用独立的链标识
+	nativeChainKey := ""
+	if chainService := blockchain.GetChainService(r.ChainType); chainService != nil {
+		nativeChainKey = data.GetLockChainKey(r.ChainType, chainService.GetNativeSymbol())
+	}
+
+	// 区块扫描模式下稳定币由 ListenEvmBlockJob 处理，这里只轮询原生币订单
+	nativeOnly := IsEvmBlockListenEnabled(r.ChainType)
+	if nativeOnly && nativeChainKey == "" {
 		return
 	}
 
-	log.Sugar.Debugf("[%s] 开始监控%d个钱包地址", r.ChainType, len(walletAddressList))
+	// 客户充值地址不论是否有订单都持续监听，只接受稳定币充值，区块扫描模式下同样由 ListenEvmBlockJob 处理
+	var customerAddresses []string
+	if !nativeOnly {
+		customerAddressList, err := data.GetMonitoredCustomerAddresses(r.ChainType)
+		if err != nil {
+			log.Sugar.Errorf("获取%s客户充值地址失败: %v", r.ChainType, err)
+		}
+		for _, address := range customerAddressList {
+			customerAddresses = append(customerAddresses, address.Token)
+		}
+	}
+
+	if len(walletAddressList) <= 0 && len(customerAddresses) <= 0 {
+		return
+	}
+
+	log.Sugar.Debugf("[%s] 开始监控%d个钱包地址，%d个客户充值地址", r.ChainType, len(walletAddressList), len(customerAddresses))
 
 	// 筛选出有待支付订单的地址
 	var activeAddresses []string
 	for _, address := range walletAddressList {
-		hasPendingOrder, err := data.HasPendingOrderByAddress(address.Token, r.ChainType)
+		hasPendingOrder := false
+		var err error
+		if !nativeOnly {
+			hasPendingOrder, err = data.HasPendingOrderByAddress(address.Token, r.ChainType)
+		}
+		if err == nil && !hasPendingOrder && nativeChainKey != "" {
+			hasPendingOrder, err = data.HasPendingOrderByAddress(address.Token, nativeChainKey)
+		}
 		if err != nil {
 			log.Sugar.Warnf("[%s] 检查地址订单状态失败: %s, err=%v", r.ChainType, address.Token, err)
 			// 出错时也加入监控列表，保证不遗漏
@@ -50,17 +82,22 @@ func (r *ListenBlockchainJob) Run() {
 		}
 	}
 
-	if len(activeAddresses) == 0 {
+	if len(activeAddresses) == 0 && len(customerAddresses) == 0 {
 		log.Sugar.Debugf("[%s] 当前无待支付订单需要监控", r.ChainType)
 		return
 	}
 
 	log.Sugar.Infof("[%s] 筛选后需要监控%d个有订单的地址（共%d个地址）", r.ChainType, len(activeAddresses), len(walletAddressList))
+	activeAddresses = append(activeAddresses, customerAddresses...)
 
 	var wg sync.WaitGroup
 	for _, address := range activeAddresses {
 		wg.Add(1)
-		go service.ChainCallBack(address, r.ChainType, &wg)
+		if nativeOnly {
+			go service.NativeChainCallBack(address, r.ChainType, &wg)
+		} else {
+			go service.ChainCallBack(address, r.ChainType, &wg)
+		}
 	}
 	time.Sleep(1 * time.Second)
 	wg.Wait()
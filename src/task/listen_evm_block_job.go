@@ -0,0 +1,109 @@
+package task
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/assimon/luuu/blockchain"
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/model/data"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/assimon/luuu/model/service"
+	"github.com/assimon/luuu/util/log"
+	"github.com/golang-module/carbon/v2"
+)
+
+// ListenEvmBlockJob EVM 链区块扫描监听任务
+// 每个新区块只扫描一次 USDT/USDC 合约的 Transfer 事件，在内存中匹配所有钱包地址，请求数与钱包数量无关
+type ListenEvmBlockJob struct {
+	ChainType string
+	mu        sync.Mutex
+}
+
+func NewListenEvmBlockJob(chainType string) *ListenEvmBlockJob {
+	return &ListenEvmBlockJob{
+		ChainType: chainType,
+	}
+}
+
+// IsEvmBlockListenEnabled 判断链是否使用区块扫描模式监听稳定币
+func IsEvmBlockListenEnabled(chainType string) bool {
+	if config.GetEvmListenMode() != "block" {
+		return false
+	}
+	_, ok := blockchain.GetChainService(chainType).(blockchain.BlockScanner)
+	return ok
+}
+
+func (r *ListenEvmBlockJob) Run() {
+	// 上一轮扫描未结束时跳过，避免重复扫描同一区间
+	if !r.mu.TryLock() {
+		log.Sugar.Debugf("[%s] 上一轮区块扫描未结束，跳过", r.ChainType)
+		return
+	}
+	defer r.mu.Unlock()
+
+	scanner, ok := blockchain.GetChainService(r.ChainType).(blockchain.BlockScanner)
+	if !ok {
+		log.Sugar.Errorf("[%s] 链服务不支持区块扫描", r.ChainType)
+		return
+	}
+
+	walletAddressList, err := data.GetAvailableWalletAddressByChainType(r.ChainType)
+	if err != nil {
+		log.Sugar.Errorf("获取%s钱包地址失败: %v", r.ChainType, err)
+		return
+	}
+
+	// 客户充值地址与钱包地址一起匹配，持续监听
+	customerAddressList, err := data.GetMonitoredCustomerAddresses(r.ChainType)
+	if err != nil {
+		log.Sugar.Errorf("获取%s客户充值地址失败: %v", r.ChainType, err)
+		return
+	}
+
+	if len(walletAddressList) <= 0 && len(customerAddressList) <= 0 {
+		return
+	}
+
+	// EVM 地址不区分大小写，统一按小写匹配，处理时还原为钱包表中的地址
+	wallets := make(map[string]string, len(walletAddressList)+len(customerAddressList))
+	for _, address := range walletAddressList {
+		wallets[strings.ToLower(address.Token)] = address.Token
+	}
+	for _, address := range customerAddressList {
+		wallets[strings.ToLower(address.Token)] = address.Token
+	}
+
+	cursor, err := data.GetScanCursor(r.ChainType, "", mdb.ScanScopeToken)
+	if err != nil {
+		log.Sugar.Errorf("[%s] 获取区块扫描游标失败: %v", r.ChainType, err)
+		return
+	}
+
+	endTime := carbon.Now().TimestampWithMillisecond()
+	catchupStart := carbon.Now().AddHours(-config.GetBlockchainCatchupHours()).TimestampWithMillisecond()
+	transactions, newCursor, err := scanner.ScanTransfers(cursor.Cursor, catchupStart, endTime, func(to string) bool {
+		_, ok := wallets[to]
+		return ok
+	})
+	if err != nil {
+		log.Sugar.Warnf("[%s] 区块扫描失败: %v (将在下次周期重试)", r.ChainType, err)
+		return
+	}
+
+	if len(transactions) > 0 {
+		log.Sugar.Infof("[%s] 区块扫描至 %s，找到 %d 笔转入钱包的交易", r.ChainType, newCursor, len(transactions))
+		for i := range transactions {
+			transactions[i].To = wallets[transactions[i].To]
+		}
+		// 处理失败时不推进游标，下个周期重新扫描
+		if !service.ProcessBlockTransactions(r.ChainType, transactions) {
+			return
+		}
+	}
+
+	if err := data.SaveScanCursor(r.ChainType, "", mdb.ScanScopeToken, newCursor, endTime); err != nil {
+		log.Sugar.Warnf("[%s] 保存区块扫描游标失败: %v", r.ChainType, err)
+	}
+}
@@ -0,0 +1,56 @@
+package task
+
+import (
+	"strconv"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/model/mdb"
+	"github.com/assimon/luuu/util/http_client"
+	"github.com/assimon/luuu/util/json"
+	"github.com/assimon/luuu/util/log"
+	"github.com/go-resty/resty/v2"
+)
+
+const NativeRateApiUri = "https://api.binance.com/api/v3/ticker/price"
+
+// NativeRateJob 原生币USDT价格监听
+type NativeRateJob struct {
+}
+
+type NativeRateResp struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+}
+
+// nativeRateSymbols 需要获取价格的原生币
+var nativeRateSymbols = []string{mdb.AssetTRX, mdb.AssetETH, mdb.AssetBNB, mdb.AssetSOL, mdb.AssetPOL}
+
+func (r NativeRateJob) Run() {
+	client := http_client.GetHttpClient()
+	for _, symbol := range nativeRateSymbols {
+		r.refresh(client, symbol)
+		// 与法币汇率共用过期告警，价格长时间未更新时按 rate_stale_block 暂停该币种下单
+		alertStale(symbol, symbol+"/USDT 价格", "币种", config.IsNativeRateStale(symbol), config.GetNativeRate(symbol))
+	}
+}
+
+// refresh 获取原生币最新USDT价格
+func (r NativeRateJob) refresh(client *resty.Client, symbol string) {
+	resp, err := client.R().SetQueryParam("symbol", symbol+"USDT").SetHeader("Accept", "application/json").Get(NativeRateApiUri)
+	if err != nil {
+		log.Sugar.Errorf("获取%s价格失败: %v", symbol, err)
+		return
+	}
+	var rateResp NativeRateResp
+	err = json.Cjson.Unmarshal(resp.Body(), &rateResp)
+	if err != nil {
+		log.Sugar.Errorf("解析%s价格响应失败: %v", symbol, err)
+		return
+	}
+	price, err := strconv.ParseFloat(rateResp.Price, 64)
+	if err != nil || price <= 0 {
+		log.Sugar.Errorf("%s价格响应错误: %s", symbol, string(resp.Body()))
+		return
+	}
+	config.SetNativeRate(symbol, price)
+}
@@ -0,0 +1,23 @@
+package task
+
+import (
+	"sync"
+
+	"github.com/assimon/luuu/model/service"
+	"github.com/assimon/luuu/util/log"
+)
+
+// RefundVerifyJob 退款核验任务，在链上核验已提交的退款交易
+type RefundVerifyJob struct {
+	mu sync.Mutex
+}
+
+func (j *RefundVerifyJob) Run() {
+	// 上一轮未结束时跳过，避免重复核验
+	if !j.mu.TryLock() {
+		log.Sugar.Debug("[退款] 上一轮退款核验任务未结束，跳过")
+		return
+	}
+	defer j.mu.Unlock()
+	service.RunRefundVerification()
+}
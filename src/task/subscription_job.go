@@ -0,0 +1,23 @@
+package task
+
+import (
+	"sync"
+
+	"github.com/assimon/luuu/model/service"
+	"github.com/assimon/luuu/util/log"
+)
+
+// SubscriptionJob 订阅扣款任务，按周期生成账单并处理逾期催缴
+type SubscriptionJob struct {
+	mu sync.Mutex
+}
+
+func (j *SubscriptionJob) Run() {
+	// 上一轮未结束时跳过，避免重复生成账单
+	if !j.mu.TryLock() {
+		log.Sugar.Debug("[订阅] 上一轮订阅任务未结束，跳过")
+		return
+	}
+	defer j.mu.Unlock()
+	service.RunSubscriptionBilling()
+}
@@ -1,63 +1,144 @@
 package task
 
 import (
-	"time"
+	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/assimon/luuu/config"
-	"github.com/assimon/luuu/util/http_client"
-	"github.com/assimon/luuu/util/json"
+	"github.com/assimon/luuu/model/data"
+	"github.com/assimon/luuu/notify"
+	"github.com/assimon/luuu/rate"
 	"github.com/assimon/luuu/util/log"
 	"github.com/assimon/luuu/util/math"
 )
 
-const UsdtRateApiUri = "https://api.coinmarketcap.com/data-api/v3/cryptocurrency/detail/chart"
-
+// UsdtRateJob 法币汇率监听，按 rate_providers 配置的数据源刷新汇率
 type UsdtRateJob struct {
 }
 
-type UsdtRateResp struct {
-	Data   Data   `json:"data"`
-	Status Status `json:"status"`
+var (
+	staleAlerted     = make(map[string]bool) // 已发送过期告警的法币或原生币，恢复后重置
+	staleAlertedLock sync.Mutex
+	pendingRates     = make(map[string]*pendingRate) // 偏差过大、等待连续确认的汇率
+	pendingRatesLock sync.Mutex
+)
+
+// pendingRate 与上次汇率偏差过大的候选汇率及连续获取到一致汇率的次数
+type pendingRate struct {
+	rate  float64
+	count int
 }
 
-type Status struct {
-	Timestamp    time.Time `json:"timestamp"`
-	ErrorCode    string    `json:"error_code"`
-	ErrorMessage string    `json:"error_message"`
-	Elapsed      string    `json:"elapsed"`
-	CreditCount  int       `json:"credit_count"`
+func (r UsdtRateJob) Run() {
+	for _, currency := range config.GetFiatCurrencies() {
+		r.refresh(currency)
+		r.checkStale(currency)
+	}
 }
 
-type Data struct {
-	Points map[string]Points `json:"points"`
+// refresh 获取最新汇率，与上次汇率偏差超过 rate_max_deviation 时暂不采用并告警
+// 连续 rate_confirm_count 次获取到彼此一致的汇率时认为行情确实变化，采用新汇率，避免参考汇率长期冻结
+func (r UsdtRateJob) refresh(currency string) {
+	newRate, source, err := rate.Fetch(currency)
+	if err != nil {
+		log.Sugar.Errorf("获取USDT/%s汇率失败: %v", currency, err)
+		return
+	}
+	newRate = math.MustParsePrecFloat64(newRate, 4)
+
+	if previous := config.GetFetchedFiatRate(currency); previous != nil {
+		deviation := rate.Deviation(newRate, previous.Rate)
+		if deviation > config.GetRateMaxDeviation() {
+			count := confirmPendingRate(currency, newRate)
+			confirmCount := config.GetRateConfirmCount()
+			if count < confirmCount {
+				log.Sugar.Errorf("USDT/%s汇率波动异常，暂不采用(%d/%d): %v -> %v (%.2f%%)", currency, count, confirmCount, previous.Rate, newRate, deviation)
+				// 每轮异常只在首次告警，避免每分钟重复发送
+				if count == 1 {
+					notify.SendToBot(fmt.Sprintf("⚠️ USDT/%s 汇率波动异常，暂不采用\n上次汇率：%v\n最新汇率：%v\n偏差：%.2f%%\n连续%d次获取一致后将自动采用新汇率，如需立即生效请配置 forced_%s_rate",
+						currency, previous.Rate, newRate, deviation, confirmCount, strings.ToLower(currency)))
+				}
+				return
+			}
+			log.Sugar.Warnf("USDT/%s汇率连续%d次获取一致，采用新汇率: %v -> %v", currency, count, previous.Rate, newRate)
+			notify.SendToBot(fmt.Sprintf("✅ USDT/%s 汇率连续%d次获取一致，已采用新汇率\n上次汇率：%v\n最新汇率：%v",
+				currency, count, previous.Rate, newRate))
+		}
+	}
+	clearPendingRate(currency)
+	config.SetFiatRate(currency, newRate)
+	if err = data.AddRateHistory(currency, newRate, source); err != nil {
+		log.Sugar.Errorf("保存USDT/%s汇率历史失败: %v", currency, err)
+	}
 }
 
-type Points struct {
-	V []float64 `json:"v"`
-	C []float64 `json:"c"`
+// confirmPendingRate 记录偏差过大的汇率，与上一次候选汇率一致时累加次数，否则重新计数，返回连续一致的次数
+func confirmPendingRate(currency string, newRate float64) int {
+	pendingRatesLock.Lock()
+	defer pendingRatesLock.Unlock()
+
+	pending := pendingRates[currency]
+	if pending != nil && rate.Deviation(newRate, pending.rate) <= config.GetRateMaxDeviation() {
+		pending.rate = newRate
+		pending.count++
+		return pending.count
+	}
+	pendingRates[currency] = &pendingRate{rate: newRate, count: 1}
+	return 1
 }
 
-func (r UsdtRateJob) Run() {
-	client := http_client.GetHttpClient()
-	resp, err := client.R().SetQueryString("id=825&range=1H&convertId=2787").SetHeader("Accept", "application/json").Get(UsdtRateApiUri)
-	if err != nil {
-		log.Sugar.Error("获取USDT汇率失败:", err.Error())
-		return
+// clearPendingRate 汇率恢复正常或已采用新汇率时清除候选汇率
+func clearPendingRate(currency string) {
+	pendingRatesLock.Lock()
+	defer pendingRatesLock.Unlock()
+	delete(pendingRates, currency)
+}
+
+// LoadLatestRates 启动时从汇率历史恢复各法币最近一次的汇率，避免重启后使用默认汇率
+func LoadLatestRates() {
+	for _, currency := range config.GetFiatCurrencies() {
+		history, err := data.GetLatestRateHistory(currency)
+		if err != nil {
+			log.Sugar.Errorf("加载USDT/%s汇率历史失败: %v", currency, err)
+			continue
+		}
+		if history.ID <= 0 {
+			continue
+		}
+		config.RestoreFiatRate(currency, history.Rate, history.CreatedAt)
+		log.Sugar.Infof("已恢复USDT/%s汇率: %v (%s, %s)", currency, history.Rate, history.Source, history.CreatedAt.Format("2006-01-02 15:04:05"))
 	}
-	var usdtResp UsdtRateResp
-	err = json.Cjson.Unmarshal(resp.Body(), &usdtResp)
-	if err != nil {
-		log.Sugar.Error("解析USDT响应失败:", err.Error())
+}
+
+// checkStale 汇率超过 rate_stale_seconds 未更新时告警，每次过期只告警一次
+func (r UsdtRateJob) checkStale(currency string) {
+	alertStale(currency, "USDT/"+currency+" 汇率", "法币", config.IsFiatRateStale(currency), config.GetFiatRate(currency))
+}
+
+// alertStale 汇率或原生币价格过期时告警并说明是否暂停下单，恢复更新后发送恢复通知，每次过期只告警一次
+// key 用于记录告警状态，法币与原生币符号不会重复
+func alertStale(key string, name string, kind string, stale bool, current float64) {
+	staleAlertedLock.Lock()
+	defer staleAlertedLock.Unlock()
+
+	if !stale {
+		if staleAlerted[key] {
+			staleAlerted[key] = false
+			notify.SendToBot(fmt.Sprintf("✅ %s已恢复更新：%v", name, current))
+		}
 		return
 	}
-	if usdtResp.Status.ErrorCode != "0" {
-		log.Sugar.Error("USDT响应错误:", usdtResp.Status.ErrorMessage)
+	if staleAlerted[key] {
 		return
 	}
-	for _, points := range usdtResp.Data.Points {
-		if len(points.C) > 0 && points.C[0] > 0 {
-			config.UsdtRate = math.MustParsePrecFloat64(points.C[0], 2)
-			return
-		}
+	staleAlerted[key] = true
+
+	action := "继续使用旧汇率下单"
+	if config.GetRateStaleBlock() {
+		action = "已暂停该" + kind + "下单"
 	}
+	log.Sugar.Errorf("%s已超过%d秒未更新，%s", name, config.GetRateStaleSeconds(), action)
+	notify.SendToBot(fmt.Sprintf("⚠️ %s已超过%d秒未更新，%s\n当前汇率：%v",
+		name, config.GetRateStaleSeconds(), action, current))
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/assimon/luuu/blockchain"
+	"github.com/assimon/luuu/config"
 	"github.com/assimon/luuu/model/data"
 	"github.com/assimon/luuu/model/mdb"
 	"github.com/assimon/luuu/model/request"
@@ -39,6 +40,15 @@ type walletInfo struct {
 
 var userWalletCache sync.Map
 
+// 临时存储用户正在提交交易哈希的退款编号
+var userRefundCache sync.Map
+
+// 机器人支付链接列表显示数量
+const paymentLinkListSize = 10
+
+// 机器人退款列表显示数量
+const refundListSize = 10
+
 // OnCallbackHandle 统一的回调处理器
 func OnCallbackHandle(c tb.Context) error {
 	callback := c.Callback()
@@ -110,6 +120,60 @@ func OnCallbackHandle(c tb.Context) error {
 	case "back_to_list":
 		return ShowWalletList(c)
 
+	case "list_links":
+		return ShowPaymentLinkList(c)
+
+	case "create_link":
+		return RequestPaymentLinkInfo(c)
+
+	case "view_link":
+		if len(parts) < 2 {
+			return c.Send("操作失败：缺少链接标识")
+		}
+		return ShowPaymentLinkDetail(c, parts[1])
+
+	case "enable_link":
+		if len(parts) < 2 {
+			return c.Send("操作失败：缺少链接标识")
+		}
+		return ChangePaymentLinkStatus(c, parts[1], mdb.PaymentLinkStatusEnable)
+
+	case "disable_link":
+		if len(parts) < 2 {
+			return c.Send("操作失败：缺少链接标识")
+		}
+		return ChangePaymentLinkStatus(c, parts[1], mdb.PaymentLinkStatusDisable)
+
+	case "delete_link":
+		if len(parts) < 2 {
+			return c.Send("操作失败：缺少链接标识")
+		}
+		return DeletePaymentLink(c, parts[1])
+
+	case "list_refunds":
+		return ShowRefundList(c)
+
+	case "create_refund":
+		return RequestRefundInfo(c)
+
+	case "view_refund":
+		if len(parts) < 2 {
+			return c.Send("操作失败：缺少退款编号")
+		}
+		return ShowRefundDetail(c, parts[1])
+
+	case "submit_refund_tx":
+		if len(parts) < 2 {
+			return c.Send("操作失败：缺少退款编号")
+		}
+		return RequestRefundTxHash(c, parts[1])
+
+	case "cancel_refund":
+		if len(parts) < 2 {
+			return c.Send("操作失败：缺少退款编号")
+		}
+		return CancelRefund(c, parts[1])
+
 	case "query_balance":
 		if len(parts) < 2 {
 			return c.Send("操作失败：缺少钱包ID")
@@ -213,6 +277,47 @@ func OnTextMessageHandle(c tb.Context) error {
 		return CreatePaymentLink(c, wallet, amount)
 	}
 
+	// 处理创建支付链接（标题和金额）
+	if strings.Contains(c.Message().ReplyTo.Text, "请输入支付链接标题和金额") {
+		fields := strings.Fields(c.Message().Text)
+		if len(fields) < 2 {
+			return c.Send("格式不正确，请输入标题和金额，以空格分隔\n例如：会员充值 100")
+		}
+		amount, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+		if err != nil || amount < 0 {
+			return c.Send("金额格式不正确，请输入数字，0 表示由付款人填写")
+		}
+		return CreateReusablePaymentLink(c, strings.Join(fields[:len(fields)-1], " "), amount)
+	}
+
+	// 处理创建退款（订单号、金额、地址和原因）
+	if strings.Contains(c.Message().ReplyTo.Text, "请输入订单号、退款金额、退款地址") {
+		fields := strings.Fields(c.Message().Text)
+		if len(fields) < 3 {
+			return c.Send("格式不正确，请输入订单号、退款金额、退款地址和退款原因，以空格分隔\n例如：202610181234 10.5 TQWh7yxxvJkxPVrXkhaQDqvVsrw4uG1FVJ 重复支付")
+		}
+		amount, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || amount <= 0 {
+			return c.Send("退款金额格式不正确，请输入大于0的数字")
+		}
+		return CreateRefund(c, &request.CreateRefundRequest{
+			TradeId:   fields[0],
+			Amount:    amount,
+			ToAddress: fields[2],
+			Reason:    strings.Join(fields[3:], " "),
+		})
+	}
+
+	// 处理提交退款交易哈希
+	if strings.Contains(c.Message().ReplyTo.Text, "请发送退款交易哈希") {
+		refundNoVal, ok := userRefundCache.Load(c.Sender().ID)
+		if !ok {
+			return c.Send("退款信息丢失，请重新操作")
+		}
+		userRefundCache.Delete(c.Sender().ID)
+		return SubmitRefundTx(c, refundNoVal.(string), strings.TrimSpace(c.Message().Text))
+	}
+
 	return nil
 }
 
@@ -283,7 +388,15 @@ func ShowWalletList(c tb.Context) error {
 		Text: "添加钱包地址",
 		Data: "add_wallet",
 	}
-	buttons = append(buttons, []tb.InlineButton{addBtn})
+	linkBtn := tb.InlineButton{
+		Text: "支付链接",
+		Data: "list_links",
+	}
+	refundBtn := tb.InlineButton{
+		Text: "退款",
+		Data: "list_refunds",
+	}
+	buttons = append(buttons, []tb.InlineButton{addBtn, linkBtn, refundBtn})
 
 	return c.Send(message, &tb.SendOptions{
 		ReplyMarkup: &tb.ReplyMarkup{
@@ -482,7 +595,7 @@ func RequestPaymentAmount(c tb.Context, walletID uint64) error {
 	}
 
 	message += fmt.Sprintf("收款地址：\n%s\n\n", tokenInfo.Token)
-	message += "请输入支付金额（单位：人民币）\n例如：100"
+	message += fmt.Sprintf("请输入支付金额（单位：%s）\n例如：100", config.GetDefaultCurrency())
 
 	return c.Send(message, &tb.SendOptions{
 		ReplyMarkup: &tb.ReplyMarkup{
@@ -491,55 +604,334 @@ func RequestPaymentAmount(c tb.Context, walletID uint64) error {
 	})
 }
 
-// CreatePaymentLink 创建支付链接
+// CreatePaymentLink 为钱包所在链创建一次性支付链接
 func CreatePaymentLink(c tb.Context, wallet *walletInfo, amount float64) error {
-	// 生成唯一订单号
-	orderID := fmt.Sprintf("TG%d%d", c.Sender().ID, time.Now().Unix())
-
-	// 创建订单请求
-	createReq := &request.CreateTransactionRequest{
-		OrderId:     orderID,
-		Amount:      amount,
-		NotifyUrl:   "https://example.com/notify", // 手动创建的订单使用占位URL
-		RedirectUrl: "",
-		ChainType:   wallet.ChainType,
+	title := "Telegram 收款"
+	if wallet.Remark != "" {
+		title = fmt.Sprintf("Telegram 收款 - %s", wallet.Remark)
 	}
+	resp, err := service.CreatePaymentLink(&request.CreatePaymentLinkRequest{
+		Title:      title,
+		Amount:     amount,
+		ChainTypes: wallet.ChainType,
+		MaxUses:    1,
+	})
 
-	// 调用订单服务创建订单
-	resp, err := service.CreateTransaction(createReq)
+	// 清除缓存
+	userWalletCache.Delete(c.Sender().ID)
 	if err != nil {
-		userWalletCache.Delete(c.Sender().ID)
 		return c.Send(fmt.Sprintf("创建失败：%s", err.Error()))
 	}
 
-	// 清除缓存
-	userWalletCache.Delete(c.Sender().ID)
-
 	// 返回支付信息
 	message := "【支付链接创建成功】\n\n"
-	message += fmt.Sprintf("订单号：%s\n", resp.TradeId)
-	message += fmt.Sprintf("链类型：%s\n", resp.ChainType)
+	message += fmt.Sprintf("链类型：%s\n", wallet.ChainType)
 
 	// 显示备注（如果有）
 	if wallet.Remark != "" {
 		message += fmt.Sprintf("备注：%s\n", wallet.Remark)
 	}
 
-	// TRC20 只显示 USDT，其他链显示 USDT/USDC
-	paymentLabel := "USDT/USDC"
-	if resp.ChainType == mdb.ChainTypeTRC20 {
-		paymentLabel = "USDT"
+	message += fmt.Sprintf("支付金额：%.2f %s\n", resp.Amount, resp.Currency)
+	message += "可支付次数：1\n\n"
+	message += fmt.Sprintf("支付链接：\n%s", resp.Url)
+
+	return c.Send(message)
+}
+
+// ShowPaymentLinkList 显示最近创建的支付链接
+func ShowPaymentLinkList(c tb.Context) error {
+	links, total, err := data.GetPaymentLinks(1, paymentLinkListSize)
+	if err != nil {
+		return c.Send(fmt.Sprintf("获取支付链接失败：%s", err.Error()))
+	}
+
+	message := "【支付链接】\n\n"
+	if total == 0 {
+		message += "暂无支付链接\n"
+	} else if total > int64(len(links)) {
+		message += fmt.Sprintf("共 %d 个，显示最近创建的 %d 个\n", total, len(links))
+	}
+
+	var buttons [][]tb.InlineButton
+	for _, link := range links {
+		status := "启用"
+		if link.Status == mdb.PaymentLinkStatusDisable {
+			status = "禁用"
+		}
+		amount := "自定义金额"
+		if link.Amount > 0 {
+			amount = fmt.Sprintf("%.2f %s", link.Amount, link.Currency)
+		}
+		buttons = append(buttons, []tb.InlineButton{{
+			Text: fmt.Sprintf("%s - %s (%s)", link.Title, amount, status),
+			Data: fmt.Sprintf("view_link:%s", link.Slug),
+		}})
 	}
-	message += fmt.Sprintf("支付金额：%.4f %s\n\n", resp.ActualAmount, paymentLabel)
-	message += fmt.Sprintf("收款地址：\n`%s`\n\n", resp.Token)
-	message += fmt.Sprintf("收银台：\n%s\n\n", resp.PaymentUrl)
-	message += fmt.Sprintf("过期时间：%s", time.Unix(resp.ExpirationTime, 0).Format("2006-01-02 15:04:05"))
+	buttons = append(buttons,
+		[]tb.InlineButton{{Text: "创建支付链接", Data: "create_link"}},
+		[]tb.InlineButton{{Text: "返回", Data: "back_to_list"}},
+	)
 
 	return c.Send(message, &tb.SendOptions{
-		ParseMode: "Markdown",
+		ReplyMarkup: &tb.ReplyMarkup{
+			InlineKeyboard: buttons,
+		},
 	})
 }
 
+// RequestPaymentLinkInfo 请求用户输入支付链接标题和金额
+func RequestPaymentLinkInfo(c tb.Context) error {
+	message := "【创建支付链接】\n\n"
+	message += "请输入支付链接标题和金额，以空格分隔，金额为 0 表示由付款人填写\n"
+	message += "例如：会员充值 100"
+
+	return c.Send(message, &tb.SendOptions{
+		ReplyMarkup: &tb.ReplyMarkup{
+			ForceReply: true,
+		},
+	})
+}
+
+// CreateReusablePaymentLink 创建可重复支付的链接，允许所有可用的链
+func CreateReusablePaymentLink(c tb.Context, title string, amount float64) error {
+	resp, err := service.CreatePaymentLink(&request.CreatePaymentLinkRequest{
+		Title:  title,
+		Amount: amount,
+	})
+	if err != nil {
+		return c.Send(fmt.Sprintf("创建失败：%s", err.Error()))
+	}
+	c.Send(fmt.Sprintf("【支付链接创建成功】\n\n%s", resp.Url))
+	return ShowPaymentLinkDetail(c, resp.Slug)
+}
+
+// ShowPaymentLinkDetail 显示支付链接详情
+func ShowPaymentLinkDetail(c tb.Context, slug string) error {
+	link, err := service.GetPaymentLink(slug)
+	if err != nil {
+		return c.Send(fmt.Sprintf("获取支付链接失败：%s", err.Error()))
+	}
+
+	status := "已启用"
+	if link.Status == mdb.PaymentLinkStatusDisable {
+		status = "已禁用"
+	}
+
+	message := "【支付链接详情】\n\n"
+	message += fmt.Sprintf("标题：%s\n", link.Title)
+	if link.Amount > 0 {
+		message += fmt.Sprintf("金额：%.2f %s\n", link.Amount, link.Currency)
+	} else {
+		message += fmt.Sprintf("金额：付款人填写（%s）\n", link.Currency)
+	}
+	if len(link.ChainTypes) > 0 {
+		message += fmt.Sprintf("链类型：%s\n", strings.Join(link.ChainTypes, ", "))
+	} else {
+		message += "链类型：全部\n"
+	}
+	if link.MaxUses > 0 {
+		message += fmt.Sprintf("已支付：%d / %d\n", link.UseCount, link.MaxUses)
+	} else {
+		message += fmt.Sprintf("已支付：%d\n", link.UseCount)
+	}
+	if link.ExpiresAt > 0 {
+		message += fmt.Sprintf("过期时间：%s\n", time.Unix(link.ExpiresAt, 0).Format("2006-01-02 15:04:05"))
+	}
+	message += fmt.Sprintf("状态：%s\n", status)
+	message += fmt.Sprintf("\n链接：\n%s", link.Url)
+
+	buttons := [][]tb.InlineButton{
+		{
+			{Text: "启用", Data: fmt.Sprintf("enable_link:%s", slug)},
+			{Text: "禁用", Data: fmt.Sprintf("disable_link:%s", slug)},
+			{Text: "删除", Data: fmt.Sprintf("delete_link:%s", slug)},
+		},
+		{{Text: "返回", Data: "list_links"}},
+	}
+
+	return c.Send(message, &tb.SendOptions{
+		ReplyMarkup: &tb.ReplyMarkup{
+			InlineKeyboard: buttons,
+		},
+	})
+}
+
+// ChangePaymentLinkStatus 启用或禁用支付链接
+func ChangePaymentLinkStatus(c tb.Context, slug string, status int) error {
+	if err := service.ChangePaymentLinkStatus(slug, status); err != nil {
+		return c.Send(fmt.Sprintf("操作失败：%s", err.Error()))
+	}
+
+	if status == mdb.PaymentLinkStatusEnable {
+		c.Send("操作成功：支付链接已启用")
+	} else {
+		c.Send("操作成功：支付链接已禁用")
+	}
+	return ShowPaymentLinkDetail(c, slug)
+}
+
+// DeletePaymentLink 删除支付链接
+func DeletePaymentLink(c tb.Context, slug string) error {
+	if err := service.DeletePaymentLink(slug); err != nil {
+		return c.Send(fmt.Sprintf("删除失败：%s", err.Error()))
+	}
+
+	c.Send("操作成功：支付链接已删除")
+	return ShowPaymentLinkList(c)
+}
+
+// ShowRefundList 显示最近创建的退款
+func ShowRefundList(c tb.Context) error {
+	refunds, pagination, err := service.ListRefunds("", 0, 1, refundListSize)
+	if err != nil {
+		return c.Send(fmt.Sprintf("获取退款列表失败：%s", err.Error()))
+	}
+
+	message := "【退款】\n\n"
+	if pagination.Total == 0 {
+		message += "暂无退款\n"
+	} else if pagination.Total > int64(len(refunds)) {
+		message += fmt.Sprintf("共 %d 笔，显示最近创建的 %d 笔\n", pagination.Total, len(refunds))
+	}
+
+	var buttons [][]tb.InlineButton
+	for _, refund := range refunds {
+		buttons = append(buttons, []tb.InlineButton{{
+			Text: fmt.Sprintf("%s - %.4f %s (%s)", refund.TradeId, refund.Amount, refund.Asset, refundStatusText(refund.Status)),
+			Data: fmt.Sprintf("view_refund:%s", refund.RefundNo),
+		}})
+	}
+	buttons = append(buttons,
+		[]tb.InlineButton{{Text: "创建退款", Data: "create_refund"}},
+		[]tb.InlineButton{{Text: "返回", Data: "back_to_list"}},
+	)
+
+	return c.Send(message, &tb.SendOptions{
+		ReplyMarkup: &tb.ReplyMarkup{
+			InlineKeyboard: buttons,
+		},
+	})
+}
+
+// RequestRefundInfo 请求用户输入退款信息
+func RequestRefundInfo(c tb.Context) error {
+	message := "【创建退款】\n\n"
+	message += "请输入订单号、退款金额、退款地址和退款原因，以空格分隔，退款原因可省略\n"
+	message += "退款金额按订单支付币种计算，退款交易需自行在钱包中发出，创建后提交交易哈希核验\n"
+	message += "例如：202610181234 10.5 TQWh7yxxvJkxPVrXkhaQDqvVsrw4uG1FVJ 重复支付"
+
+	return c.Send(message, &tb.SendOptions{
+		ReplyMarkup: &tb.ReplyMarkup{
+			ForceReply: true,
+		},
+	})
+}
+
+// CreateRefund 创建退款记录
+func CreateRefund(c tb.Context, req *request.CreateRefundRequest) error {
+	resp, err := service.CreateRefund(req)
+	if err != nil {
+		return c.Send(fmt.Sprintf("创建失败：%s", err.Error()))
+	}
+	c.Send("【退款创建成功】\n\n请在钱包中发出退款交易后提交交易哈希")
+	return ShowRefundDetail(c, resp.RefundNo)
+}
+
+// ShowRefundDetail 显示退款详情
+func ShowRefundDetail(c tb.Context, refundNo string) error {
+	refund, err := service.GetRefund(refundNo)
+	if err != nil {
+		return c.Send(fmt.Sprintf("获取退款失败：%s", err.Error()))
+	}
+
+	message := "【退款详情】\n\n"
+	message += fmt.Sprintf("退款编号：%s\n", refund.RefundNo)
+	message += fmt.Sprintf("订单号：%s\n", refund.TradeId)
+	message += fmt.Sprintf("链类型：%s\n", refund.ChainType)
+	message += fmt.Sprintf("退款金额：%.4f %s\n", refund.Amount, refund.Asset)
+	if refund.Reason != "" {
+		message += fmt.Sprintf("退款原因：%s\n", refund.Reason)
+	}
+	message += fmt.Sprintf("状态：%s\n", refundStatusText(refund.Status))
+	if refund.FailReason != "" {
+		message += fmt.Sprintf("失败原因：%s\n", refund.FailReason)
+	}
+	message += fmt.Sprintf("\n退款地址：\n%s", refund.ToAddress)
+	if refund.TxHash != "" {
+		message += fmt.Sprintf("\n\n交易哈希：\n%s", refund.TxHash)
+	}
+
+	var buttons [][]tb.InlineButton
+	if refund.Status == mdb.RefundStatusPending || refund.Status == mdb.RefundStatusFailed {
+		buttons = append(buttons, []tb.InlineButton{
+			{Text: "提交交易哈希", Data: fmt.Sprintf("submit_refund_tx:%s", refundNo)},
+			{Text: "取消退款", Data: fmt.Sprintf("cancel_refund:%s", refundNo)},
+		})
+	}
+	buttons = append(buttons, []tb.InlineButton{{Text: "返回", Data: "list_refunds"}})
+
+	return c.Send(message, &tb.SendOptions{
+		ReplyMarkup: &tb.ReplyMarkup{
+			InlineKeyboard: buttons,
+		},
+	})
+}
+
+// RequestRefundTxHash 请求用户输入退款交易哈希
+func RequestRefundTxHash(c tb.Context, refundNo string) error {
+	userRefundCache.Store(c.Sender().ID, refundNo)
+
+	message := "【提交退款交易】\n\n"
+	message += fmt.Sprintf("退款编号：%s\n\n", refundNo)
+	message += "请发送退款交易哈希："
+
+	return c.Send(message, &tb.SendOptions{
+		ReplyMarkup: &tb.ReplyMarkup{
+			ForceReply: true,
+		},
+	})
+}
+
+// SubmitRefundTx 提交退款交易哈希并核验
+func SubmitRefundTx(c tb.Context, refundNo string, txHash string) error {
+	refund, err := service.SubmitRefundTx(refundNo, txHash)
+	if err != nil {
+		return c.Send(fmt.Sprintf("提交失败：%s", err.Error()))
+	}
+	if refund.Status == mdb.RefundStatusVerifying {
+		c.Send("交易哈希已提交，交易确认后自动完成核验")
+	}
+	return ShowRefundDetail(c, refundNo)
+}
+
+// CancelRefund 取消退款
+func CancelRefund(c tb.Context, refundNo string) error {
+	if err := service.CancelRefund(refundNo); err != nil {
+		return c.Send(fmt.Sprintf("取消失败：%s", err.Error()))
+	}
+
+	c.Send("操作成功：退款已取消")
+	return ShowRefundList(c)
+}
+
+// refundStatusText 退款状态显示文字
+func refundStatusText(status int) string {
+	switch status {
+	case mdb.RefundStatusPending:
+		return "待退款"
+	case mdb.RefundStatusVerifying:
+		return "核验中"
+	case mdb.RefundStatusCompleted:
+		return "已完成"
+	case mdb.RefundStatusFailed:
+		return "核验失败"
+	case mdb.RefundStatusCancelled:
+		return "已取消"
+	}
+	return "未知"
+}
+
 // QueryBalance 查询钱包余额
 func QueryBalance(c tb.Context, id uint64) error {
 	if id <= 0 {
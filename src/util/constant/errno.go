@@ -12,6 +12,28 @@ var Errno = map[int]string{
 	10007: "订单区块已处理",
 	10008: "订单不存在",
 	10009: "无法解析请求参数",
+	10010: "不支持的支付币种",
+	10011: "不支持的法币币种",
+	10012: "汇率或价格长时间未更新，暂停下单",
+	10013: "支付网络不可用",
+	10014: "订单已选择支付网络",
+	10015: "订单已支付或已过期",
+	10016: "订单尚未选择支付网络",
+	10017: "支付链接不存在",
+	10018: "支付链接已停用或已过期",
+	10019: "支付链接已达到最大支付次数",
+	10020: "支付链接参数有误",
+	10021: "该链未配置地址派生，不支持开放金额订单",
+	10022: "无可分配的客户充值地址",
+	10023: "客户充值地址不存在",
+	10024: "钱包地址格式错误",
+	10025: "订阅不存在",
+	10026: "订阅已取消",
+	10027: "退款记录不存在",
+	10028: "订单未支付，无法退款",
+	10029: "退款金额超过订单可退金额",
+	10030: "当前退款状态不允许该操作",
+	10031: "该交易哈希已用于其他退款",
 }
 
 var (
@@ -26,6 +48,28 @@ var (
 	OrderBlockAlreadyProcess   = Err(10007)
 	OrderNotExists             = Err(10008)
 	ParamsMarshalErr           = Err(10009)
+	AssetNotSupportedErr       = Err(10010)
+	CurrencyNotSupportedErr    = Err(10011)
+	RateStaleErr               = Err(10012)
+	ChainNotAvailableErr       = Err(10013)
+	OrderChainSelectedErr      = Err(10014)
+	OrderNotWaitPayErr         = Err(10015)
+	OrderChainNotSelectedErr   = Err(10016)
+	PaymentLinkNotExists       = Err(10017)
+	PaymentLinkUnavailableErr  = Err(10018)
+	PaymentLinkExhaustedErr    = Err(10019)
+	PaymentLinkParamsErr       = Err(10020)
+	OpenAmountNotAvailableErr  = Err(10021)
+	CustomerAddressUnavailable = Err(10022)
+	CustomerAddressNotExists   = Err(10023)
+	InvalidWalletAddressErr    = Err(10024)
+	SubscriptionNotExists      = Err(10025)
+	SubscriptionCancelledErr   = Err(10026)
+	RefundNotExists            = Err(10027)
+	OrderNotPaidErr            = Err(10028)
+	RefundAmountExceededErr    = Err(10029)
+	RefundStatusErr            = Err(10030)
+	RefundTxHashUsedErr        = Err(10031)
 )
 
 type RspError struct {
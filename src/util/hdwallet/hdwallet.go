@@ -0,0 +1,166 @@
+package hdwallet
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/sha3"
+)
+
+// HardenedOffset 硬化推导起始索引，扩展公钥只能推导非硬化子节点
+const HardenedOffset = 0x80000000
+
+// ExtendedPublicKey BIP32 扩展公钥，只能推导子公钥和地址，无法得到私钥
+type ExtendedPublicKey struct {
+	key       []byte // 33字节压缩公钥
+	chainCode []byte // 32字节链码
+	point     *point
+}
+
+// ParseXpub 解析 xpub（BIP32 序列化格式，base58check 编码）
+func ParseXpub(xpub string) (*ExtendedPublicKey, error) {
+	raw, err := base58.Decode(strings.TrimSpace(xpub))
+	if err != nil {
+		return nil, fmt.Errorf("xpub 解码失败: %w", err)
+	}
+	// 版本4 + 深度1 + 父指纹4 + 子索引4 + 链码32 + 公钥33 + 校验和4
+	if len(raw) != 82 {
+		return nil, errors.New("xpub 长度错误")
+	}
+	payload, checksum := raw[:78], raw[78:]
+	if !bytes.Equal(doubleSha256(payload)[:4], checksum) {
+		return nil, errors.New("xpub 校验和错误")
+	}
+	key := payload[45:78]
+	if key[0] != 0x02 && key[0] != 0x03 {
+		return nil, errors.New("请配置扩展公钥（xpub），不支持扩展私钥")
+	}
+	p, err := decompress(key)
+	if err != nil {
+		return nil, err
+	}
+	return &ExtendedPublicKey{
+		key:       append([]byte{}, key...),
+		chainCode: append([]byte{}, payload[13:45]...),
+		point:     p,
+	}, nil
+}
+
+// Child 推导非硬化子公钥
+func (k *ExtendedPublicKey) Child(index uint32) (*ExtendedPublicKey, error) {
+	if index >= HardenedOffset {
+		return nil, errors.New("扩展公钥无法推导硬化子节点")
+	}
+	data := make([]byte, 37)
+	copy(data, k.key)
+	binary.BigEndian.PutUint32(data[33:], index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(curveN) >= 0 {
+		return nil, fmt.Errorf("子节点 %d 无效，请使用下一个索引", index)
+	}
+	child := scalarBaseMult(il).add(k.point)
+	if child == nil {
+		return nil, fmt.Errorf("子节点 %d 无效，请使用下一个索引", index)
+	}
+	return &ExtendedPublicKey{
+		key:       child.compress(),
+		chainCode: sum[32:],
+		point:     child,
+	}, nil
+}
+
+// DerivePath 按索引依次推导子公钥，如 DerivePath(0, 5) 对应 xpub/0/5
+func (k *ExtendedPublicKey) DerivePath(indexes ...uint32) (*ExtendedPublicKey, error) {
+	current := k
+	for _, index := range indexes {
+		child, err := current.Child(index)
+		if err != nil {
+			return nil, err
+		}
+		current = child
+	}
+	return current, nil
+}
+
+// EvmAddress 生成 EIP-55 校验格式的 EVM 地址
+func (k *ExtendedPublicKey) EvmAddress() string {
+	addr := hex.EncodeToString(k.addressBytes())
+	hash := hex.EncodeToString(keccak256([]byte(addr)))
+	checksummed := make([]byte, len(addr))
+	for i := 0; i < len(addr); i++ {
+		c := addr[i]
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			c -= 'a' - 'A'
+		}
+		checksummed[i] = c
+	}
+	return "0x" + string(checksummed)
+}
+
+// TronAddress 生成 Base58Check 格式的 TRON 地址
+func (k *ExtendedPublicKey) TronAddress() string {
+	return encodeTronAddress(k.addressBytes())
+}
+
+// TronAddressFromHex 将20字节地址的十六进制（可带 41 或 0x 前缀）转换为 Base58Check 格式的 TRON 地址
+func TronAddressFromHex(hexAddress string) (string, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(hexAddress, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("无效的 TRON 十六进制地址: %w", err)
+	}
+	if len(raw) == 21 && raw[0] == 0x41 {
+		raw = raw[1:]
+	}
+	if len(raw) != 20 {
+		return "", errors.New("无效的 TRON 十六进制地址长度")
+	}
+	return encodeTronAddress(raw), nil
+}
+
+// TronAddressToHex 将 Base58Check 格式的 TRON 地址转换为20字节地址的十六进制（不含 41 前缀）
+func TronAddressToHex(address string) (string, error) {
+	raw, err := base58.Decode(address)
+	if err != nil {
+		return "", fmt.Errorf("无效的 TRON 地址: %w", err)
+	}
+	if len(raw) != 25 || raw[0] != 0x41 || !bytes.Equal(doubleSha256(raw[:21])[:4], raw[21:]) {
+		return "", errors.New("无效的 TRON 地址")
+	}
+	return hex.EncodeToString(raw[1:21]), nil
+}
+
+func encodeTronAddress(addressBytes []byte) string {
+	payload := append([]byte{0x41}, addressBytes...)
+	return base58.Encode(append(payload, doubleSha256(payload)[:4]...))
+}
+
+// addressBytes 公钥 Keccak256 哈希的后20字节，EVM 与 TRON 共用
+func (k *ExtendedPublicKey) addressBytes() []byte {
+	return keccak256(k.point.uncompressed())[12:]
+}
+
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func doubleSha256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
@@ -0,0 +1,143 @@
+package hdwallet
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/mr-tron/base58"
+)
+
+// BIP32 官方测试向量中可由扩展公钥推导的路径，from 为父节点 xpub，index 为非硬化子索引
+var bip32PublicVectors = []struct {
+	name  string
+	from  string
+	index uint32
+	want  string
+}{
+	// 测试向量1
+	{
+		name:  "TV1 m/0H/1",
+		from:  "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+		index: 1,
+		want:  "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+	},
+	{
+		name:  "TV1 m/0H/1/2H/2",
+		from:  "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+		index: 2,
+		want:  "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+	},
+	{
+		name:  "TV1 m/0H/1/2H/2/1000000000",
+		from:  "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+		index: 1000000000,
+		want:  "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+	},
+	// 测试向量2
+	{
+		name:  "TV2 m/0",
+		from:  "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB",
+		index: 0,
+		want:  "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH",
+	},
+	{
+		name:  "TV2 m/0/2147483647H/1",
+		from:  "xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a",
+		index: 1,
+		want:  "xpub6DF8uhdarytz3FWdA8TvFSvvAh8dP3283MY7p2V4SeE2wyWmG5mg5EwVvmdMVCQcoNJxGoWaU9DCWh89LojfZ537wTfunKau47EL2dhHKon",
+	},
+	{
+		name:  "TV2 m/0/2147483647H/1/2147483646H/2",
+		from:  "xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL",
+		index: 2,
+		want:  "xpub6FnCn6nSzZAw5Tw7cgR9bi15UV96gLZhjDstkXXxvCLsUXBGXPdSnLFbdpq8p9HmGsApME5hQTZ3emM2rnY5agb9rXpVGyy3bdW6EEgAtqt",
+	},
+}
+
+func TestChildMatchesBIP32Vectors(t *testing.T) {
+	for _, tc := range bip32PublicVectors {
+		parent, err := ParseXpub(tc.from)
+		if err != nil {
+			t.Fatalf("%s: 解析父节点失败: %v", tc.name, err)
+		}
+		want, err := ParseXpub(tc.want)
+		if err != nil {
+			t.Fatalf("%s: 解析期望节点失败: %v", tc.name, err)
+		}
+		child, err := parent.Child(tc.index)
+		if err != nil {
+			t.Fatalf("%s: 推导失败: %v", tc.name, err)
+		}
+		if !bytes.Equal(child.key, want.key) {
+			t.Errorf("%s: 公钥不一致: got %x, want %x", tc.name, child.key, want.key)
+		}
+		if !bytes.Equal(child.chainCode, want.chainCode) {
+			t.Errorf("%s: 链码不一致: got %x, want %x", tc.name, child.chainCode, want.chainCode)
+		}
+	}
+}
+
+func TestChildRejectsHardenedIndex(t *testing.T) {
+	parent, err := ParseXpub(bip32PublicVectors[0].from)
+	if err != nil {
+		t.Fatalf("解析 xpub 失败: %v", err)
+	}
+	if _, err := parent.Child(HardenedOffset); err == nil {
+		t.Fatal("扩展公钥推导硬化子节点应返回错误")
+	}
+}
+
+// serializeXpub 按 BIP32 格式序列化主网 xpub，用于构造已知公钥的测试数据
+func serializeXpub(key []byte, chainCode []byte) string {
+	payload := make([]byte, 0, 82)
+	payload = append(payload, 0x04, 0x88, 0xb2, 0x1e) // xpub 版本
+	payload = append(payload, make([]byte, 9)...)     // 深度、父指纹、子索引
+	payload = append(payload, chainCode...)
+	payload = append(payload, key...)
+	payload = append(payload, doubleSha256(payload)[:4]...)
+	return base58.Encode(payload)
+}
+
+func TestAddressFromXpub(t *testing.T) {
+	chainCode := bytes.Repeat([]byte{0x01}, 32)
+	for _, tc := range []struct {
+		name    string
+		private int64
+		evm     string
+		tronHex string
+	}{
+		// 私钥为 1、2 时公钥为 G、2G，对应的以太坊地址为公开已知值
+		{"privkey 1", 1, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", "417e5f4552091a69125d5dfcb7b8c2659029395bdf"},
+		{"privkey 2", 2, "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF", "412b5ad5c4795c026514f8317c7a215e218dccd6cf"},
+	} {
+		pub := scalarBaseMult(big.NewInt(tc.private)).compress()
+		k, err := ParseXpub(serializeXpub(pub, chainCode))
+		if err != nil {
+			t.Fatalf("%s: 解析 xpub 失败: %v", tc.name, err)
+		}
+		if got := k.EvmAddress(); got != tc.evm {
+			t.Errorf("%s: EVM 地址不一致: got %s, want %s", tc.name, got, tc.evm)
+		}
+		tron, err := TronAddressFromHex(tc.tronHex)
+		if err != nil {
+			t.Fatalf("%s: 转换 TRON 地址失败: %v", tc.name, err)
+		}
+		if got := k.TronAddress(); got != tron {
+			t.Errorf("%s: TRON 地址不一致: got %s, want %s", tc.name, got, tron)
+		}
+	}
+}
+
+func TestTronAddressHexRoundTrip(t *testing.T) {
+	// USDT TRC20 合约地址
+	const address, hexAddress = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "41a614f803b6fd780986a42c78ec9c7f77e6ded13c"
+	got, err := TronAddressFromHex(hexAddress)
+	if err != nil || got != address {
+		t.Fatalf("TronAddressFromHex = %s, %v; want %s", got, err, address)
+	}
+	back, err := TronAddressToHex(address)
+	if err != nil || "41"+back != hexAddress {
+		t.Fatalf("TronAddressToHex = %s, %v; want %s", back, err, hexAddress)
+	}
+}
@@ -0,0 +1,121 @@
+package hdwallet
+
+import (
+	"errors"
+	"math/big"
+)
+
+// secp256k1 曲线参数，仅用于公钥推导，不涉及私钥运算
+var (
+	curveP, _  = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F", 16)
+	curveN, _  = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+	curveGx, _ = new(big.Int).SetString("79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798", 16)
+	curveGy, _ = new(big.Int).SetString("483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8", 16)
+	curveB     = big.NewInt(7)
+)
+
+// point 曲线上的点，nil 表示无穷远点
+type point struct {
+	x, y *big.Int
+}
+
+// add 点加法
+func (p *point) add(q *point) *point {
+	if p == nil {
+		return q
+	}
+	if q == nil {
+		return p
+	}
+	if p.x.Cmp(q.x) == 0 {
+		if p.y.Cmp(q.y) == 0 {
+			return p.double()
+		}
+		return nil
+	}
+	// lambda = (y2 - y1) / (x2 - x1)
+	num := new(big.Int).Sub(q.y, p.y)
+	den := new(big.Int).Sub(q.x, p.x)
+	den.Mod(den, curveP).ModInverse(den, curveP)
+	lambda := num.Mul(num, den)
+	lambda.Mod(lambda, curveP)
+	return p.fromLambda(q, lambda)
+}
+
+// double 倍点
+func (p *point) double() *point {
+	if p == nil || p.y.Sign() == 0 {
+		return nil
+	}
+	// lambda = 3 * x^2 / (2 * y)
+	num := new(big.Int).Mul(p.x, p.x)
+	num.Mul(num, big.NewInt(3))
+	den := new(big.Int).Lsh(p.y, 1)
+	den.Mod(den, curveP).ModInverse(den, curveP)
+	lambda := num.Mul(num, den)
+	lambda.Mod(lambda, curveP)
+	return p.fromLambda(p, lambda)
+}
+
+func (p *point) fromLambda(q *point, lambda *big.Int) *point {
+	// x3 = lambda^2 - x1 - x2, y3 = lambda * (x1 - x3) - y1
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, p.x).Sub(x3, q.x).Mod(x3, curveP)
+	y3 := new(big.Int).Sub(p.x, x3)
+	y3.Mul(y3, lambda).Sub(y3, p.y).Mod(y3, curveP)
+	return &point{x: x3, y: y3}
+}
+
+// scalarBaseMult 计算 k*G
+func scalarBaseMult(k *big.Int) *point {
+	var result *point
+	addend := &point{x: curveGx, y: curveGy}
+	for i := 0; i < k.BitLen(); i++ {
+		if k.Bit(i) == 1 {
+			result = addend.add(result)
+		}
+		addend = addend.double()
+	}
+	return result
+}
+
+// compress 压缩公钥格式，33字节
+func (p *point) compress() []byte {
+	out := make([]byte, 33)
+	out[0] = 0x02 + byte(p.y.Bit(0))
+	p.x.FillBytes(out[1:])
+	return out
+}
+
+// uncompressed 去掉 0x04 前缀的非压缩公钥，64字节
+func (p *point) uncompressed() []byte {
+	out := make([]byte, 64)
+	p.x.FillBytes(out[:32])
+	p.y.FillBytes(out[32:])
+	return out
+}
+
+// decompress 解析33字节压缩公钥
+func decompress(key []byte) (*point, error) {
+	if len(key) != 33 || (key[0] != 0x02 && key[0] != 0x03) {
+		return nil, errors.New("无效的压缩公钥")
+	}
+	x := new(big.Int).SetBytes(key[1:])
+	if x.Cmp(curveP) >= 0 {
+		return nil, errors.New("无效的压缩公钥")
+	}
+	// y^2 = x^3 + 7
+	y2 := new(big.Int).Exp(x, big.NewInt(3), curveP)
+	y2.Add(y2, curveB).Mod(y2, curveP)
+	// p ≡ 3 (mod 4)，平方根为 y2^((p+1)/4)
+	exp := new(big.Int).Add(curveP, big.NewInt(1))
+	exp.Rsh(exp, 2)
+	y := new(big.Int).Exp(y2, exp, curveP)
+	if new(big.Int).Exp(y, big.NewInt(2), curveP).Cmp(y2) != 0 {
+		return nil, errors.New("公钥不在曲线上")
+	}
+	if y.Bit(0) != uint(key[0]&1) {
+		y.Sub(curveP, y)
+	}
+	return &point{x: x, y: y}, nil
+}
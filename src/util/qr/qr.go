@@ -0,0 +1,78 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	DefaultSize = 256  // 默认尺寸（像素）
+	MinSize     = 64   // 最小尺寸
+	MaxSize     = 1024 // 最大尺寸
+)
+
+// ParseLevel 解析纠错等级 L、M、Q、H（不区分大小写），无效时使用 M
+func ParseLevel(level string) qrcode.RecoveryLevel {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low
+	case "Q":
+		return qrcode.High
+	case "H":
+		return qrcode.Highest
+	default:
+		return qrcode.Medium
+	}
+}
+
+// ClampSize 限制尺寸范围，未指定时使用默认尺寸
+func ClampSize(size int) int {
+	if size <= 0 {
+		return DefaultSize
+	}
+	if size < MinSize {
+		return MinSize
+	}
+	if size > MaxSize {
+		return MaxSize
+	}
+	return size
+}
+
+// PNG 生成 PNG 格式二维码
+func PNG(content string, level qrcode.RecoveryLevel, size int) ([]byte, error) {
+	return qrcode.Encode(content, level, size)
+}
+
+// SVG 生成 SVG 格式二维码，每个模块为一个单位，由 viewBox 缩放到指定尺寸
+func SVG(content string, level qrcode.RecoveryLevel, size int) ([]byte, error) {
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+	bitmap := code.Bitmap()
+	modules := len(bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/><path fill="#000000" d="`, modules, modules)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			// 合并同一行连续的黑色模块，减小输出体积
+			start := x
+			for x+1 < len(row) && row[x+1] {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start, y, x-start+1, x-start+1)
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes(), nil
+}
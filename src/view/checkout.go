@@ -0,0 +1,22 @@
+package view
+
+import "github.com/assimon/luuu/model/response"
+
+const (
+	// CheckoutTemplate 收银台模板文件名
+	CheckoutTemplate = "index.html"
+	// PaymentLinkTemplate 支付链接页面模板文件名
+	PaymentLinkTemplate = "link.html"
+)
+
+// CheckoutPage 收银台模板数据，订单字段与文案方法均可在模板中直接使用
+type CheckoutPage struct {
+	*response.CheckoutCounterResponse
+	Locale
+}
+
+// PaymentLinkPage 支付链接页面模板数据
+type PaymentLinkPage struct {
+	*response.PaymentLinkPageResponse
+	Locale
+}
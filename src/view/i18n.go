@@ -0,0 +1,195 @@
+package view
+
+import (
+	"embed"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/util/json"
+	"github.com/assimon/luuu/util/log"
+)
+
+// 内置语言文案，文件名为语言标识（zh-CN.json、en.json 等）
+//
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// 未匹配到任何语言时的兜底语言，内置文案以该语言为准
+const fallbackLang = "zh-CN"
+
+var (
+	localeMu    sync.RWMutex
+	localeCache map[string]map[string]string
+)
+
+// Locale 模板使用的语言文案，模板中通过 {{.T "key"}} 取值
+type Locale struct {
+	Lang     string       // 当前语言
+	Langs    []LangOption // 可切换的语言
+	messages map[string]string
+}
+
+// LangOption 可切换的语言
+type LangOption struct {
+	Lang string // 语言标识
+	Name string // 语言名称（该语言自身的写法）
+}
+
+// T 获取文案，当前语言缺失时使用兜底语言，都不存在时返回 key
+// 文案来自内置文件或运维配置的覆盖目录，视为可信内容，允许包含 HTML
+func (l Locale) T(key string) template.HTML {
+	if msg, ok := l.messages[key]; ok {
+		return template.HTML(msg)
+	}
+	if msg, ok := loadLocales()[fallbackLang][key]; ok {
+		return template.HTML(msg)
+	}
+	return template.HTML(template.HTMLEscapeString(key))
+}
+
+// NewLocale 按 lang 参数和 Accept-Language 请求头选择语言
+func NewLocale(lang string, acceptLanguage string) Locale {
+	locales := loadLocales()
+	selected := matchLang(locales, append([]string{lang}, parseAcceptLanguage(acceptLanguage)...))
+	if selected == "" {
+		selected = matchLang(locales, []string{config.GetCheckoutDefaultLang()})
+	}
+	if selected == "" {
+		selected = fallbackLang
+	}
+
+	langs := make([]LangOption, 0, len(locales))
+	for code, messages := range locales {
+		name := messages["lang_name"]
+		if name == "" {
+			name = code
+		}
+		langs = append(langs, LangOption{Lang: code, Name: name})
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i].Lang < langs[j].Lang })
+
+	return Locale{Lang: selected, Langs: langs, messages: locales[selected]}
+}
+
+// matchLang 依次匹配候选语言，先完全匹配，再按中文繁简和基础语言匹配
+func matchLang(locales map[string]map[string]string, candidates []string) string {
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(candidate), "_", "-"))
+		if candidate == "" || candidate == "*" {
+			continue
+		}
+		for code := range locales {
+			if strings.ToLower(code) == candidate {
+				return code
+			}
+		}
+		base := strings.SplitN(candidate, "-", 2)[0]
+		if base == "zh" {
+			// 港澳台及繁体中文使用 zh-TW，其余使用 zh-CN
+			target := "zh-CN"
+			if strings.Contains(candidate, "hant") || strings.HasSuffix(candidate, "-tw") ||
+				strings.HasSuffix(candidate, "-hk") || strings.HasSuffix(candidate, "-mo") {
+				target = "zh-TW"
+			}
+			if _, ok := locales[target]; ok {
+				return target
+			}
+		}
+		for code := range locales {
+			if strings.ToLower(strings.SplitN(code, "-", 2)[0]) == base {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
+// parseAcceptLanguage 解析 Accept-Language 请求头，按权重从高到低返回语言
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var items []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if fields[0] == "" {
+			continue
+		}
+		q := 1.0
+		for _, field := range fields[1:] {
+			field = strings.TrimSpace(field)
+			if strings.HasPrefix(field, "q=") {
+				if v, err := strconv.ParseFloat(field[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			items = append(items, weighted{lang: fields[0], q: q})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].q > items[j].q })
+	langs := make([]string, len(items))
+	for i, item := range items {
+		langs[i] = item.lang
+	}
+	return langs
+}
+
+// loadLocales 加载内置文案并合并覆盖目录 locales/*.json 中的文案，debug 模式下每次重新加载
+func loadLocales() map[string]map[string]string {
+	localeMu.RLock()
+	cached := localeCache
+	localeMu.RUnlock()
+	if cached != nil && !config.AppDebug {
+		return cached
+	}
+
+	locales := make(map[string]map[string]string)
+	files, _ := localeFS.ReadDir("locales")
+	for _, file := range files {
+		content, err := localeFS.ReadFile("locales/" + file.Name())
+		if err != nil {
+			continue
+		}
+		mergeLocale(locales, file.Name(), content)
+	}
+	if dir := config.GetCheckoutTemplatePath(); dir != "" {
+		paths, _ := filepath.Glob(filepath.Join(dir, "locales", "*.json"))
+		for _, path := range paths {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				log.Sugar.Warnf("[view] 读取语言文件失败: %s, err=%v", path, err)
+				continue
+			}
+			mergeLocale(locales, filepath.Base(path), content)
+		}
+	}
+
+	localeMu.Lock()
+	localeCache = locales
+	localeMu.Unlock()
+	return locales
+}
+
+func mergeLocale(locales map[string]map[string]string, fileName string, content []byte) {
+	var messages map[string]string
+	if err := json.Cjson.Unmarshal(content, &messages); err != nil {
+		log.Sugar.Warnf("[view] 解析语言文件失败: %s, err=%v", fileName, err)
+		return
+	}
+	lang := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	if locales[lang] == nil {
+		locales[lang] = make(map[string]string)
+	}
+	for key, msg := range messages {
+		locales[lang][key] = msg
+	}
+}
@@ -0,0 +1,77 @@
+package view
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/assimon/luuu/config"
+	"github.com/assimon/luuu/util/log"
+)
+
+// 内置模板，checkout_template_path 未配置或未覆盖时使用
+//
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// 模板函数
+var funcMap = template.FuncMap{
+	// safeURL 标记服务端生成的链接为可信，避免 tron:、solana: 等钱包协议链接被替换为 #ZgotmplZ
+	"safeURL": func(s string) template.URL {
+		return template.URL(s)
+	},
+}
+
+// 已解析的模板，键为 主题/模板名，debug 模式下每次重新解析便于调试模板
+var templateCache sync.Map
+
+// Render 渲染模板，theme 为订单指定的主题，为空时使用默认模板
+func Render(w io.Writer, name string, theme string, data interface{}) error {
+	tmpl, err := lookupTemplate(name, theme)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+// lookupTemplate 按 主题覆盖 > 目录覆盖 > 内置模板 的顺序查找模板
+func lookupTemplate(name string, theme string) (*template.Template, error) {
+	key := theme + "/" + name
+	if !config.AppDebug {
+		if cached, ok := templateCache.Load(key); ok {
+			return cached.(*template.Template), nil
+		}
+	}
+	content, err := readTemplate(name, theme)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(name).Funcs(funcMap).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	templateCache.Store(key, tmpl)
+	return tmpl, nil
+}
+
+func readTemplate(name string, theme string) ([]byte, error) {
+	if dir := config.GetCheckoutTemplatePath(); dir != "" {
+		candidates := []string{filepath.Join(dir, name)}
+		if theme != "" {
+			candidates = append([]string{filepath.Join(dir, theme, name)}, candidates...)
+		}
+		for _, path := range candidates {
+			content, err := os.ReadFile(path)
+			if err == nil {
+				return content, nil
+			}
+			if !os.IsNotExist(err) {
+				log.Sugar.Warnf("[view] 读取覆盖模板失败: %s, err=%v", path, err)
+			}
+		}
+	}
+	return templateFS.ReadFile("templates/" + name)
+}